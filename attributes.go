@@ -0,0 +1,184 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"math"
+
+	"github.com/thinkofdeath/steven/protocol"
+)
+
+// Attributes used by the client
+const (
+	attrMovementSpeed = "generic.movementSpeed"
+	attrMaxHealth     = "generic.maxHealth"
+)
+
+// playerWalkSpeed is the base movement speed of a player.
+const playerWalkSpeed = 0.1
+
+// sprintModifier is the id of the modifier added to the player's
+// movement speed whilst sprinting. The server uses the same id
+// so it replaces the server's copy instead of stacking with it.
+var sprintModifier = protocol.UUID{
+	0x66, 0x2a, 0x6b, 0x8d, 0xda, 0x3e, 0x4c, 0x1c,
+	0x88, 0x13, 0x96, 0xea, 0x60, 0x97, 0x27, 0x8d,
+}
+
+// Modifier operations
+const (
+	// Adds the amount to the base value
+	attrOpAdd = 0
+	// Adds the base value (after attrOpAdd) multiplied by the
+	// amount
+	attrOpMultiplyBase = 1
+	// Multiplies the final value by 1 + the amount
+	attrOpMultiply = 2
+)
+
+type attributeModifier struct {
+	amount    float64
+	operation int
+}
+
+// attribute is a value of an entity, e.g. its movement speed,
+// along with the modifiers changing it.
+type attribute struct {
+	base      float64
+	modifiers map[protocol.UUID]attributeModifier
+}
+
+// value computes the value of the attribute in the same way as
+// vanilla.
+func (a *attribute) value() float64 {
+	base := a.base
+	for _, m := range a.modifiers {
+		if m.operation == attrOpAdd {
+			base += m.amount
+		}
+	}
+	val := base
+	for _, m := range a.modifiers {
+		if m.operation == attrOpMultiplyBase {
+			val += base * m.amount
+		}
+	}
+	for _, m := range a.modifiers {
+		if m.operation == attrOpMultiply {
+			val *= 1 + m.amount
+		}
+	}
+	// None of vanilla's attributes can go below zero
+	return math.Max(0, val)
+}
+
+// attributeMap is the set of attributes of an entity keyed by
+// their name.
+type attributeMap map[string]*attribute
+
+// update replaces the attributes with the ones sent by the server.
+func (am attributeMap) update(props []protocol.EntityProperty) {
+	for _, p := range props {
+		a := &attribute{
+			base:      p.Value,
+			modifiers: make(map[protocol.UUID]attributeModifier, len(p.Modifiers)),
+		}
+		for _, m := range p.Modifiers {
+			a.modifiers[m.UUID] = attributeModifier{
+				amount:    m.Amount,
+				operation: int(m.Operation),
+			}
+		}
+		am[p.Key] = a
+	}
+}
+
+// value returns the value of the named attribute, ok is false if
+// the server hasn't sent it.
+func (am attributeMap) value(key string) (val float64, ok bool) {
+	a, ok := am[key]
+	if !ok {
+		return 0, false
+	}
+	return a.value(), true
+}
+
+type attributesComponent struct {
+	attributes attributeMap
+}
+
+func (a *attributesComponent) UpdateAttributes(props []protocol.EntityProperty) {
+	if a.attributes == nil {
+		a.attributes = attributeMap{}
+	}
+	a.attributes.update(props)
+}
+
+func (a *attributesComponent) Attribute(key string) (float64, bool) {
+	return a.attributes.value(key)
+}
+
+type AttributesComponent interface {
+	UpdateAttributes(props []protocol.EntityProperty)
+	Attribute(key string) (float64, bool)
+}
+
+// movementSpeed returns the player's movement speed attribute,
+// this is playerWalkSpeed when walking without any modifiers.
+// Until the server sends the attribute the speed is estimated
+// from the player's effects.
+func (c *ClientState) movementSpeed() float64 {
+	a, ok := c.attributes[attrMovementSpeed]
+	if !ok {
+		speed := playerWalkSpeed * c.effects.speedMultiplier()
+		if c.sprinting {
+			speed *= 1.3
+		}
+		return speed
+	}
+	return a.value()
+}
+
+// updateSprinting starts or stops the player sprinting. Like
+// vanilla the player only sprints whilst moving forwards and
+// not whilst flying or riding.
+func (c *ClientState) updateSprinting(riding bool) {
+	sprinting := c.KeyState[KeySprint] && c.KeyState[KeyForward] &&
+		!c.KeyState[KeyBackwards] && !c.GameMode.Fly() && !riding
+	if sprinting == c.sprinting {
+		return
+	}
+	c.sprinting = sprinting
+	c.applySprintModifier()
+}
+
+// applySprintModifier adds or removes the sprint modifier from the
+// player's movement speed to match whether they are sprinting.
+// This has to be reapplied whenever the server replaces the
+// attribute.
+func (c *ClientState) applySprintModifier() {
+	a, ok := c.attributes[attrMovementSpeed]
+	if !ok {
+		return
+	}
+	if c.sprinting {
+		a.modifiers[sprintModifier] = attributeModifier{
+			amount:    0.3,
+			operation: attrOpMultiply,
+		}
+	} else {
+		delete(a.modifiers, sprintModifier)
+	}
+}
@@ -116,6 +116,16 @@ func PlaySound(name string) {
 	playSoundInternal(snd.Category, snd.Sounds[soundRandom.Intn(len(snd.Sounds))], 1, 1, false, mgl32.Vec3{}, nil)
 }
 
+// PlaySoundVolume plays the named sound at the listener with the
+// passed volume and pitch.
+func PlaySoundVolume(name string, vol, pitch float64) {
+	snd, ok := soundInfo[name]
+	if !ok {
+		return
+	}
+	playSoundInternal(snd.Category, snd.Sounds[soundRandom.Intn(len(snd.Sounds))], vol, pitch, false, mgl32.Vec3{}, nil)
+}
+
 // note: callback only valid for streams
 func PlaySoundCallback(name string, cb func()) {
 	snd, ok := soundInfo[name]
@@ -54,11 +54,29 @@ func (s *signComponent) free() {
 }
 
 func (s *signComponent) create() {
+	s.model = render.NewStaticModel([][]*render.StaticVertex{
+		signVertices(s.lines, s.hasStand),
+	})
+	s.model.Radius = 2
+	x, y, z := s.position.X, s.position.Y, s.position.Z
+
+	s.model.X, s.model.Y, s.model.Z = -float32(x)-0.5, -float32(y)-0.5, float32(z)+0.5
+	s.model.Matrix[0] = mgl32.Translate3D(
+		float32(x)+0.5,
+		-float32(y)-0.5,
+		float32(z)+0.5,
+	).Mul4(mgl32.Rotate3DY(float32(s.rotation)).Mat4()).
+		Mul4(mgl32.Translate3D(float32(s.ox), float32(-s.oy), float32(s.oz)))
+}
+
+// signVertices returns the vertices for a sign centered on the
+// origin with the passed lines written on its north face.
+func signVertices(lines [4]format.AnyComponent, hasStand bool) []*render.StaticVertex {
 	const yS = (6.0 / 16.0) / 4.0
 	const xS = yS / 16.0
 
 	var verts []*render.StaticVertex
-	for i, line := range s.lines {
+	for i, line := range lines {
 		if line.Value == nil {
 			continue
 		}
@@ -122,7 +140,7 @@ func (s *signComponent) create() {
 		direction.North: {1.5, 1.0},
 		direction.South: {1.5, 1.0},
 	})
-	if s.hasStand {
+	if hasStand {
 		// Stand
 		log := render.GetTexture("blocks/log_oak")
 		verts = appendBox(verts, -0.5/16.0, -0.25-9/16.0, -0.5/16.0, 1/16.0, 9/16.0, 1/16.0, [6]render.TextureInfo{
@@ -134,19 +152,7 @@ func (s *signComponent) create() {
 			direction.South: log.Sub(0, 0, 2, 12),
 		})
 	}
-	s.model = render.NewStaticModel([][]*render.StaticVertex{
-		verts,
-	})
-	s.model.Radius = 2
-	x, y, z := s.position.X, s.position.Y, s.position.Z
-
-	s.model.X, s.model.Y, s.model.Z = -float32(x)-0.5, -float32(y)-0.5, float32(z)+0.5
-	s.model.Matrix[0] = mgl32.Translate3D(
-		float32(x)+0.5,
-		-float32(y)-0.5,
-		float32(z)+0.5,
-	).Mul4(mgl32.Rotate3DY(float32(s.rotation)).Mat4()).
-		Mul4(mgl32.Translate3D(float32(s.ox), float32(-s.oy), float32(s.oz)))
+	return verts
 }
 
 func esSignAdd(s *signComponent, b BlockComponent) {
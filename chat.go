@@ -16,6 +16,7 @@ package steven
 
 import (
 	"math"
+	"time"
 
 	"github.com/go-gl/glfw/v3.1/glfw"
 	"github.com/thinkofdeath/steven/format"
@@ -41,6 +42,14 @@ type ChatUI struct {
 	wasEnteringText bool
 	inputLine       []rune
 	cursorTick      float64
+
+	completion        tabCompletion
+	completionRequest string
+	completionPending bool
+	completionPopup   []ui.Drawable
+
+	tooltip hoverTooltip
+	log     chatLog
 }
 
 type chatLine struct {
@@ -81,6 +90,10 @@ func (c *ChatUI) Draw(delta float64) {
 		}
 		c.wasEnteringText = c.enteringText
 	}
+	// Typing after a completion ends it
+	if len(c.completion.matches) > 0 && !c.completion.active(string(c.inputLine)) {
+		c.resetCompletion()
+	}
 	if c.enteringText {
 		c.input.SetDraw(true)
 		c.inputBackground.SetDraw(true)
@@ -138,12 +151,9 @@ func (c *ChatUI) handleKey(w *glfw.Window, key glfw.Key, scancode int, action gl
 		if key == glfw.KeyEnter && len(c.inputLine) != 0 {
 			Client.network.Write(&protocol.ChatMessage{string(c.inputLine)})
 		}
-		// Return control back to the default
-		c.enteringText = false
-		c.inputLine = c.inputLine[:0]
+		c.close(w)
 		lockMouse = true
 		w.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
-		w.SetCharCallback(nil)
 		return
 	}
 	if key == glfw.KeyBackspace && action != glfw.Release {
@@ -151,6 +161,23 @@ func (c *ChatUI) handleKey(w *glfw.Window, key glfw.Key, scancode int, action gl
 			c.inputLine = c.inputLine[:len(c.inputLine)-1]
 		}
 	}
+	if key == glfw.KeyTab && action != glfw.Release {
+		c.completeChat()
+	}
+	if key == glfw.KeyPageUp && action == glfw.Release {
+		c.close(w)
+		setScreen(newChatHistoryScreen())
+	}
+}
+
+// close stops the player entering text and returns control
+// back to the default handlers.
+func (c *ChatUI) close(w *glfw.Window) {
+	c.enteringText = false
+	c.inputLine = c.inputLine[:0]
+	c.resetCompletion()
+	c.tooltip.hide()
+	w.SetCharCallback(onChar)
 }
 
 func (c *ChatUI) handleChar(w *glfw.Window, char rune) {
@@ -200,6 +227,20 @@ func chatColorRGB(c format.Color) (r, g, b int) {
 
 func (c *ChatUI) Add(msg format.AnyComponent) {
 	format.ConvertLegacy(msg)
+	plain := componentText(msg)
+	hide, highlight, ping := matchChatFilters(plain)
+	c.log.add(&chatEntry{
+		Time:    time.Now(),
+		Message: msg,
+		Plain:   plain,
+		Hidden:  hide,
+	})
+	if ping {
+		PlaySound("random.orb")
+	}
+	if hide {
+		return
+	}
 	copy(c.Lines[0:chatHistoryLines-1], c.Lines[1:])
 	c.Lines[chatHistoryLines-1] = msg
 	f := ui.NewFormattedWidth(msg, 5, chatHistoryLines*18+1, maxLineWidth-10).Attach(ui.Top, ui.Left)
@@ -211,6 +252,11 @@ func (c *ChatUI) Add(msg format.AnyComponent) {
 	}
 	line.background.AttachTo(c.container)
 	line.background.SetA(77)
+	if highlight {
+		line.background.SetR(170)
+		line.background.SetG(120)
+		line.background.SetB(0)
+	}
 	c.parts = append(c.parts, line)
 	Client.scene.AddDrawable(line.background)
 	Client.scene.AddDrawable(f)
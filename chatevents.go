@@ -0,0 +1,318 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"net/url"
+	"os/exec"
+	"runtime"
+
+	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/thinkofdeath/steven/console"
+	"github.com/thinkofdeath/steven/encoding/nbt"
+	"github.com/thinkofdeath/steven/format"
+	"github.com/thinkofdeath/steven/protocol"
+	"github.com/thinkofdeath/steven/render"
+	"github.com/thinkofdeath/steven/ui"
+	"github.com/thinkofdeath/steven/ui/scene"
+)
+
+// hoverTooltip displays the contents of a hover event next to
+// the cursor.
+type hoverTooltip struct {
+	event      *format.HoverEvent
+	background *ui.Image
+	text       *ui.Formatted
+}
+
+func (h *hoverTooltip) show(sc *scene.Type, ev *format.HoverEvent, x, y float64, width, height int) {
+	if h.event != ev {
+		h.hide()
+		val, ok := hoverComponent(ev)
+		if !ok {
+			return
+		}
+		h.event = ev
+		h.text = ui.NewFormatted(val, 0, 0)
+		h.background = ui.NewImage(render.GetTexture("solid"), 0, 0, h.text.Width+8, h.text.Height+6, 0, 0, 1, 1, 16, 0, 16)
+		h.background.SetA(230)
+		h.background.SetLayer(200)
+		h.text.SetLayer(200)
+		h.text.AttachTo(h.background)
+		sc.AddDrawable(h.background.Attach(ui.Top, ui.Left))
+		sc.AddDrawable(h.text.Attach(ui.Top, ui.Left))
+		h.text.SetX(4)
+		h.text.SetY(2)
+	}
+	if h.background == nil {
+		return
+	}
+	ox, oy := ui.ScreenOffset(x, y, width, height)
+	h.background.SetX(ox + 12)
+	h.background.SetY(oy - 12)
+}
+
+func (h *hoverTooltip) hide() {
+	if h.background != nil {
+		ui.Remove(h.background)
+		ui.Remove(h.text)
+	}
+	h.event = nil
+	h.background = nil
+	h.text = nil
+}
+
+// hoverComponent converts the hover event into the component
+// that should be displayed for it.
+func hoverComponent(ev *format.HoverEvent) (format.AnyComponent, bool) {
+	if ev.Value.Value == nil {
+		return format.AnyComponent{}, false
+	}
+	switch ev.Action {
+	case format.ShowText:
+		format.ConvertLegacy(ev.Value)
+		return ev.Value, true
+	case format.ShowAchievement:
+		return format.Wrap(&format.TranslateComponent{Translate: ev.Value.String()}), true
+	case format.ShowItem:
+		if tag, err := nbt.ParseText(ev.Value.String()); err == nil {
+			if c, ok := itemTooltip(tag); ok {
+				return c, true
+			}
+		}
+		// Unknown items are shown as the plain text sent by the
+		// server
+		return format.Wrap(&format.TextComponent{Text: ev.Value.String()}), true
+	case format.ShowEntity:
+		tag, err := nbt.ParseText(ev.Value.String())
+		if err != nil {
+			return format.AnyComponent{}, false
+		}
+		name, _ := tag.Items["name"].(string)
+		ty, _ := tag.Items["type"].(string)
+		id, _ := tag.Items["id"].(string)
+		lines := []format.AnyComponent{
+			format.Wrap(&format.TextComponent{Text: name}),
+		}
+		if ty != "" {
+			lines = append(lines, format.Build(ty).Color(format.Gray).Create())
+		}
+		if id != "" {
+			lines = append(lines, format.Build(id).Color(format.DarkGray).Create())
+		}
+		c := joinLines(lines)
+		format.ConvertLegacy(c)
+		return c, true
+	}
+	return format.AnyComponent{}, false
+}
+
+// itemTooltip creates the tooltip for an item from its NBT
+// form as used in show_item hover events.
+func itemTooltip(tag *nbt.Compound) (format.AnyComponent, bool) {
+	var ty ItemType
+	switch id := tag.Items["id"].(type) {
+	case string:
+		ty = itemByName(id)
+	case int16:
+		if validItemID(int(id)) {
+			ty = ItemById(int(id))
+		}
+	case int32:
+		if validItemID(int(id)) {
+			ty = ItemById(int(id))
+		}
+	}
+	if ty == nil {
+		return format.AnyComponent{}, false
+	}
+	if damage, ok := tag.Items["Damage"].(int16); ok {
+		ty.ParseDamage(damage)
+	}
+	if t, ok := tag.Items["tag"].(*nbt.Compound); ok {
+		ty.ParseTag(t)
+	}
+	var lines []format.AnyComponent
+	di, hasDisplay := ty.(DisplayTag)
+	if hasDisplay && di.DisplayName() != "" {
+		lines = append(lines, format.Wrap(&format.TextComponent{Text: di.DisplayName()}))
+	} else {
+		lines = append(lines, format.Wrap(&format.TranslateComponent{Translate: ty.NameLocaleKey()}))
+	}
+	if hasDisplay {
+		for _, l := range di.Lore() {
+			line := &format.TextComponent{Text: l}
+			line.Color = format.DarkPurple
+			line.Italic = format.True
+			lines = append(lines, format.Wrap(line))
+		}
+	}
+	c := joinLines(lines)
+	format.ConvertLegacy(c)
+	return c, true
+}
+
+// joinLines combines the components into a single component
+// with each on their own line.
+func joinLines(lines []format.AnyComponent) format.AnyComponent {
+	main := &format.TextComponent{}
+	for i, l := range lines {
+		if i != 0 {
+			main.Extra = append(main.Extra, format.Wrap(&format.TextComponent{Text: "\n"}))
+		}
+		main.Extra = append(main.Extra, l)
+	}
+	return format.Wrap(main)
+}
+
+// handleClickEvent performs the action of a clicked chat
+// component. Returns whether the chat should be closed.
+func handleClickEvent(ev *format.ClickEvent) bool {
+	switch ev.Action {
+	case format.OpenURL:
+		u, err := url.Parse(ev.Value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			console.Text("Refusing to open link: %s", ev.Value)
+			return false
+		}
+		setScreen(newConfirmURLScreen(u.String()))
+		return true
+	case format.RunCommand:
+		Client.network.Write(&protocol.ChatMessage{Message: ev.Value})
+		return true
+	case format.SuggestCommand:
+		Client.chat.inputLine = []rune(ev.Value)
+	}
+	return false
+}
+
+func (c *ChatUI) handleMouseMove(x, y float64, width, height int) {
+	for _, p := range c.parts {
+		ev, ok := p.text.EventAt(x, y, width, height)
+		if ok && ev.Hover != nil {
+			c.tooltip.show(Client.scene, ev.Hover, x, y, width, height)
+			return
+		}
+	}
+	c.tooltip.hide()
+}
+
+func (c *ChatUI) handleClick(w *glfw.Window, x, y float64, width, height int, mods glfw.ModifierKey) {
+	for _, p := range c.parts {
+		ev, ok := p.text.EventAt(x, y, width, height)
+		if !ok {
+			continue
+		}
+		if mods&glfw.ModShift != 0 && ev.Insertion != "" {
+			c.inputLine = append(c.inputLine, []rune(ev.Insertion)...)
+			return
+		}
+		if ev.Click != nil && handleClickEvent(ev.Click) {
+			c.close(w)
+			if currentScreen == nil {
+				lockMouse = true
+				w.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+			}
+		}
+		return
+	}
+}
+
+type confirmURLScreen struct {
+	baseUI
+	scene *scene.Type
+
+	background *ui.Image
+}
+
+func newConfirmURLScreen(link string) *confirmURLScreen {
+	cs := &confirmURLScreen{
+		scene: scene.New(true),
+	}
+
+	cs.background = ui.NewImage(render.GetTexture("solid"), 0, 0, 854, 480, 0, 0, 1, 1, 0, 0, 0)
+	cs.background.SetA(160)
+	cs.scene.AddDrawable(cs.background.Attach(ui.Top, ui.Left))
+
+	cs.scene.AddDrawable(
+		ui.NewText("Do you want to open the following link?", 0, -60, 255, 255, 255).Attach(ui.Center, ui.Middle),
+	)
+	cs.scene.AddDrawable(
+		ui.NewText(link, 0, -30, 85, 85, 255).Attach(ui.Center, ui.Middle),
+	)
+
+	open, txt := newButtonText("Open", -205, 20, 200, 40)
+	cs.scene.AddDrawable(open.Attach(ui.Center, ui.Middle))
+	cs.scene.AddDrawable(txt)
+	open.AddClick(func() {
+		setScreen(nil)
+		openURL(link)
+	})
+
+	cpy, txt := newButtonText("Copy to Clipboard", 0, 20, 200, 40)
+	cs.scene.AddDrawable(cpy.Attach(ui.Center, ui.Middle))
+	cs.scene.AddDrawable(txt)
+	cpy.AddClick(func() {
+		setScreen(nil)
+		window.SetClipboardString(link)
+	})
+
+	cancel, txt := newButtonText("Cancel", 205, 20, 200, 40)
+	cs.scene.AddDrawable(cancel.Attach(ui.Center, ui.Middle))
+	cs.scene.AddDrawable(txt)
+	cancel.AddClick(func() { setScreen(nil) })
+
+	uiFooter(cs.scene)
+	return cs
+}
+
+func (cs *confirmURLScreen) init() {
+	window.SetKeyCallback(cs.handleKey)
+}
+
+func (cs *confirmURLScreen) tick(delta float64) {
+	width, height := window.GetFramebufferSize()
+	cs.background.SetWidth(float64(width) / ui.Scale)
+	cs.background.SetHeight(float64(height) / ui.Scale)
+}
+
+func (cs *confirmURLScreen) handleKey(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+	if key == glfw.KeyEscape && action == glfw.Release {
+		setScreen(nil)
+	}
+}
+
+func (cs *confirmURLScreen) remove() {
+	cs.scene.Hide()
+	window.SetKeyCallback(onKey)
+}
+
+// openURL opens the link in the system's web browser.
+func openURL(link string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", link)
+	case "darwin":
+		cmd = exec.Command("open", link)
+	default:
+		cmd = exec.Command("xdg-open", link)
+	}
+	if err := cmd.Start(); err != nil {
+		console.Text("Failed to open %s: %s", link, err)
+		return
+	}
+	go cmd.Wait()
+}
@@ -0,0 +1,294 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/thinkofdeath/steven/console"
+	"github.com/thinkofdeath/steven/format"
+	"github.com/thinkofdeath/steven/resource/locale"
+)
+
+const (
+	chatLogDirectory = "chat-logs"
+	chatFiltersFile  = "chat-filters.json"
+	// Max number of messages kept in memory for the history
+	// screen
+	maxChatHistory = 2000
+)
+
+var chatLogEnabled = console.NewBoolVar("cl_chat_log", true, console.Mutable, console.Serializable).Doc(`
+cl_chat_log controls whether chat messages are written to log files
+in the chat-logs folder. A plain text and a json log is created for
+each server per a day.
+`)
+
+// chatEntry is a single message in the chat history.
+type chatEntry struct {
+	Time    time.Time
+	Message format.AnyComponent
+	Plain   string
+	Hidden  bool
+}
+
+// chatLog writes chat messages to per-server, per-day log
+// files and keeps a history of the messages in memory.
+type chatLog struct {
+	server  string
+	day     string
+	text    *os.File
+	json    *os.File
+	history []*chatEntry
+	// changes counts the changes made to the history. Unlike
+	// the length of the history it keeps changing once the
+	// history is full.
+	changes int
+}
+
+func (l *chatLog) setServer(server string) {
+	l.close()
+	l.server = server
+	l.history = nil
+	l.changes++
+}
+
+func (l *chatLog) add(e *chatEntry) {
+	l.history = append(l.history, e)
+	l.changes++
+	if len(l.history) > maxChatHistory {
+		l.history = l.history[len(l.history)-maxChatHistory:]
+	}
+	if !chatLogEnabled.Value() || l.server == "" {
+		return
+	}
+	if err := l.open(e.Time); err != nil {
+		console.Text("Failed to open chat log: %s", err)
+		return
+	}
+	fmt.Fprintf(l.text, "[%s] %s\n", e.Time.Format("15:04:05"), e.Plain)
+	data, err := json.Marshal(struct {
+		Time    time.Time
+		Message *format.AnyComponent
+	}{e.Time, &e.Message})
+	if err != nil {
+		return
+	}
+	l.json.Write(append(data, '\n'))
+}
+
+// open makes sure the log files for the day are open.
+func (l *chatLog) open(t time.Time) error {
+	day := t.Format("2006-01-02")
+	if l.text != nil && l.day == day {
+		return nil
+	}
+	l.close()
+	dir := filepath.Join(chatLogDirectory, sanitizeFileName(l.server))
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+	var err error
+	flags := os.O_CREATE | os.O_APPEND | os.O_WRONLY
+	if l.text, err = os.OpenFile(filepath.Join(dir, day+".log"), flags, 0666); err != nil {
+		return err
+	}
+	if l.json, err = os.OpenFile(filepath.Join(dir, day+".json"), flags, 0666); err != nil {
+		l.text.Close()
+		l.text = nil
+		return err
+	}
+	l.day = day
+	return nil
+}
+
+func (l *chatLog) close() {
+	if l.text != nil {
+		l.text.Close()
+		l.text = nil
+	}
+	if l.json != nil {
+		l.json.Close()
+		l.json = nil
+	}
+}
+
+// sanitizeFileName replaces characters that aren't safe to use
+// in a file name (e.g. the port separator).
+func sanitizeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '/', '\\', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// componentText returns the text of the component without any
+// formatting.
+func componentText(c format.AnyComponent) string {
+	var buf bytes.Buffer
+	writeComponentText(&buf, c)
+	return buf.String()
+}
+
+func writeComponentText(buf *bytes.Buffer, c format.AnyComponent) {
+	switch c := c.Value.(type) {
+	case *format.TextComponent:
+		buf.WriteString(stripLegacy(c.Text))
+		for _, e := range c.Extra {
+			writeComponentText(buf, e)
+		}
+	case *format.TranslateComponent:
+		for _, part := range locale.Get(c.Translate) {
+			switch part := part.(type) {
+			case string:
+				buf.WriteString(part)
+			case int:
+				if part >= 0 && part < len(c.With) {
+					writeComponentText(buf, c.With[part])
+				}
+			}
+		}
+		for _, e := range c.Extra {
+			writeComponentText(buf, e)
+		}
+	}
+}
+
+// Chat filters
+
+type chatFilterAction string
+
+const (
+	chatFilterHide      chatFilterAction = "hide"
+	chatFilterHighlight chatFilterAction = "highlight"
+	chatFilterPing      chatFilterAction = "ping"
+)
+
+type chatFilter struct {
+	Action  chatFilterAction
+	Pattern string
+
+	re *regexp.Regexp
+}
+
+var chatFilters []*chatFilter
+
+func init() {
+	f, err := os.Open(chatFiltersFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			console.Text("Failed to load chat filters: %s", err)
+		}
+		return
+	}
+	defer f.Close()
+	// Each filter is decoded separately so that a single
+	// invalid entry doesn't disable the others
+	var filters []json.RawMessage
+	if err := json.NewDecoder(f).Decode(&filters); err != nil {
+		console.Text("Failed to load chat filters: %s", err)
+		return
+	}
+	for i, data := range filters {
+		cf := &chatFilter{}
+		if err := json.Unmarshal(data, cf); err != nil {
+			console.Text("Invalid chat filter %d: %s", i, err)
+			continue
+		}
+		if cf.re, err = regexp.Compile(cf.Pattern); err != nil {
+			console.Text("Invalid chat filter %d: %s", i, err)
+			continue
+		}
+		chatFilters = append(chatFilters, cf)
+	}
+}
+
+func saveChatFilters() {
+	f, err := os.Create(chatFiltersFile)
+	if err != nil {
+		console.Text("Failed to save chat filters: %s", err)
+		return
+	}
+	defer f.Close()
+	data, err := json.MarshalIndent(chatFilters, "", "    ")
+	if err != nil {
+		panic(err)
+	}
+	f.Write(data)
+}
+
+// matchChatFilters returns whether the message should be hidden,
+// highlighted and/or ping the player.
+func matchChatFilters(plain string) (hide, highlight, ping bool) {
+	for _, cf := range chatFilters {
+		if !cf.re.MatchString(plain) {
+			continue
+		}
+		switch cf.Action {
+		case chatFilterHide:
+			hide = true
+		case chatFilterHighlight:
+			highlight = true
+		case chatFilterPing:
+			ping = true
+		}
+	}
+	return
+}
+
+func init() {
+	console.Register("chat_filter_add % %", func(action, pattern string) {
+		switch a := chatFilterAction(action); a {
+		case chatFilterHide, chatFilterHighlight, chatFilterPing:
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				console.Text("Invalid pattern: %s", err)
+				return
+			}
+			chatFilters = append(chatFilters, &chatFilter{Action: a, Pattern: pattern, re: re})
+			saveChatFilters()
+		default:
+			console.Text("Unknown action %q, expected hide, highlight or ping", action)
+		}
+	})
+	console.Register("chat_filter_remove %", func(i int) {
+		if i >= len(chatFilters) {
+			console.Text("No filter %d", i)
+			return
+		}
+		chatFilters = append(chatFilters[:i], chatFilters[i+1:]...)
+		saveChatFilters()
+	})
+	console.Register("chat_filter_list", func() {
+		for i, cf := range chatFilters {
+			console.Text("%d: %s %q", i, cf.Action, cf.Pattern)
+		}
+	})
+	console.Register("chat_history", func() {
+		if connected {
+			setScreen(newChatHistoryScreen())
+		}
+	})
+}
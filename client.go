@@ -17,6 +17,7 @@ package steven
 import (
 	"encoding/hex"
 	"math"
+	"strconv"
 	"time"
 
 	"github.com/go-gl/glfw/v3.1/glfw"
@@ -44,14 +45,31 @@ func initClient() {
 		for _, e := range Client.entities.entities {
 			Client.entities.container.RemoveEntity(e)
 		}
+		for _, e := range Client.entities.detached {
+			Client.entities.container.RemoveEntity(e)
+		}
 		for _, e := range Client.blockBreakers {
 			Client.entities.container.RemoveEntity(e)
 		}
 		if Client.entity != nil && Client.entityAdded {
 			Client.entities.container.RemoveEntity(Client.entity)
 		}
+		for _, e := range Client.explosions {
+			e.free()
+		}
+		Client.border.free()
+		Client.weather.free()
+		Client.particles.free()
+		Client.scoreboard.free()
+		Client.effects.free()
+		render.FOVScale = 1
+		Client.chat.log.close()
+		freeMaps()
 		Client.playerList.free()
 
+		if inv := Client.activeInventory; inv != nil && inv != Client.playerInventory {
+			inv.Hide()
+		}
 		Client.playerInventory.Close()
 		Client.hotbarScene.Hide()
 	}
@@ -73,16 +91,23 @@ type ClientState struct {
 
 	entity      *clientEntity
 	entityAdded bool
+	// entityID is the id the server uses for the client's entity
+	entityID int
 
 	LX, LY, LZ float64
 	X, Y, Z    float64
 	Yaw, Pitch float64
 
-	Health float64
-	Hunger float64
+	Health     float64
+	Hunger     float64
+	Armor      int
+	Air        int
+	Experience float64
+	ExpLevel   int
 
 	VSpeed                   float64
-	KeyState                 [6]bool
+	VelocityX, VelocityZ     float64
+	KeyState                 [7]bool
 	OnGround, didTouchGround bool
 	isLeftDown               bool
 	stepTimer                float64
@@ -116,6 +141,22 @@ type ClientState struct {
 	lifeFillUI []*ui.Image
 	foodUI     []*ui.Image
 	foodFillUI []*ui.Image
+	armorUI    []*ui.Image
+	airUI      []*ui.Image
+	expBarUI   *ui.Image
+	expFillUI  *ui.Image
+	expLevelUI []*ui.Text
+
+	// mountUI shows the health of the entity the player is
+	// riding in place of the food bar
+	mountUI     []*ui.Image
+	mountFillUI []*ui.Image
+	mountShown  bool
+	// mountRows is the number of rows of hearts the mount's
+	// health takes up, the air bar is moved above them
+	mountRows int
+
+	riding riding
 
 	currentHotbarSlot, lastHotbarSlot int
 	lastHotbarItem                    *ItemStack
@@ -125,6 +166,12 @@ type ClientState struct {
 	network    networkManager
 	chat       ChatUI
 	playerList playerListUI
+	scoreboard scoreboardState
+	titles     titleState
+	effects    effectState
+	attributes attributeMap
+	sprinting  bool
+	fovScale   float64
 	entities   clientEntities
 
 	playerInventory *Inventory
@@ -138,6 +185,11 @@ type ClientState struct {
 	breakEntity             BlockEntity
 	blockBreakers           map[int]BlockEntity
 
+	explosions []*explosionEffect
+	border     worldBorder
+	weather    weatherState
+	particles  particleSystem
+
 	delta float64
 }
 
@@ -147,7 +199,10 @@ func newClient() {
 			Min: mgl32.Vec3{-0.3, 0, -0.3},
 			Max: mgl32.Vec3{0.3, 1.8, 0.3},
 		},
-		scene: scene.New(true),
+		scene:      scene.New(true),
+		attributes: attributeMap{},
+		Air:        maxAir,
+		fovScale:   1,
 	}
 	Client = c
 	c.playerInventory = NewInventory(InvPlayer, 0, 45)
@@ -196,16 +251,71 @@ func newClient() {
 		c.foodFillUI = append(c.foodFillUI, f)
 	}
 
+	// Armor / Air
+	for i := 0; i < 10; i++ {
+		a := ui.NewImage(icons, 16*float64(i), -16-8-10-20, 18, 18, 16.0/256.0, 9.0/256.0, 9.0/256.0, 9.0/256.0, 255, 255, 255).
+			Attach(ui.Top, ui.Left)
+		a.AttachTo(hotbar)
+		a.SetDraw(false)
+		c.scene.AddDrawable(a)
+		c.armorUI = append(c.armorUI, a)
+
+		a = ui.NewImage(icons, 16*float64(i), -16-8-10-20, 18, 18, 16.0/256.0, 18.0/256.0, 9.0/256.0, 9.0/256.0, 255, 255, 255).
+			Attach(ui.Top, ui.Right)
+		a.AttachTo(hotbar)
+		a.SetDraw(false)
+		c.scene.AddDrawable(a)
+		c.airUI = append(c.airUI, a)
+	}
+
+	// Mount health, rows of 10 hearts going up from the food bar
+	for i := 0; i < maxMountHearts; i++ {
+		x, y := 16*float64(i%10), -16-8-10-20*float64(i/10)
+		m := ui.NewImage(icons, x, y, 18, 18, 52.0/256.0, 9.0/256.0, 9.0/256.0, 9.0/256.0, 255, 255, 255).
+			Attach(ui.Top, ui.Right)
+		m.AttachTo(hotbar)
+		m.SetDraw(false)
+		c.scene.AddDrawable(m)
+		c.mountUI = append(c.mountUI, m)
+		m = ui.NewImage(icons, x, y, 18, 18, 88.0/256.0, 9.0/256.0, 9.0/256.0, 9.0/256.0, 255, 255, 255).
+			Attach(ui.Top, ui.Right)
+		m.AttachTo(hotbar)
+		m.SetDraw(false)
+		c.scene.AddDrawable(m)
+		c.mountFillUI = append(c.mountFillUI, m)
+	}
+
 	// Exp bar
-	c.scene.AddDrawable(
-		ui.NewImage(icons, 0, 22*2+4, 182*2, 10, 0, 64.0/256.0, 182.0/256.0, 5.0/256.0, 255, 255, 255).
-			Attach(ui.Bottom, ui.Center),
-	)
+	c.expBarUI = ui.NewImage(icons, 0, 22*2+4, 182*2, 10, 0, 64.0/256.0, 182.0/256.0, 5.0/256.0, 255, 255, 255).
+		Attach(ui.Bottom, ui.Center)
+	c.scene.AddDrawable(c.expBarUI)
+	c.expFillUI = ui.NewImage(icons, 0, 0, 0, 10, 0, 69.0/256.0, 0, 5.0/256.0, 255, 255, 255).
+		Attach(ui.Top, ui.Left)
+	c.expFillUI.AttachTo(c.expBarUI)
+	c.scene.AddDrawable(c.expFillUI)
+	// The level is outlined in black by drawing it offset in each
+	// direction behind the real one
+	for _, off := range [...][2]float64{{-2, 0}, {2, 0}, {0, -2}, {0, 2}, {0, 0}} {
+		r, g, b := 0, 0, 0
+		if off == [2]float64{} {
+			r, g, b = 128, 255, 32
+		}
+		t := ui.NewText("", off[0], 26*2+off[1], r, g, b).
+			Attach(ui.Bottom, ui.Center)
+		t.SetDraw(false)
+		c.scene.AddDrawable(t)
+		c.expLevelUI = append(c.expLevelUI, t)
+	}
+	c.riding.init(c.scene, icons)
 
 	c.itemNameUI = ui.NewFormatted(format.Wrap(&format.TextComponent{}), 0, -16-8-10-16-20)
 	c.itemNameUI.AttachTo(c.hotbar)
 	c.scene.AddDrawable(c.itemNameUI.Attach(ui.Top, ui.Middle))
 
+	c.border.init(c.scene)
+	c.scoreboard.init(c.scene)
+	c.titles.init(c.scene, c.hotbar)
+	c.effects.init(c.scene)
 	c.chat.init()
 	c.initDebug()
 	c.playerList.init()
@@ -239,23 +349,23 @@ type clientEntity struct {
 	targetRotationComponent
 	targetPositionComponent
 	sizeComponent
+	hurtComponent
 
 	playerComponent
 	playerModelComponent
+	equipmentComponent
 }
 
 func (c *ClientState) updateWorldType(wt worldType) {
 	c.WorldType = wt
+	render.LightLevel = wt.lightLevel()
 	switch c.WorldType {
 	case wtOverworld:
-		render.LightLevel = 0.8
 		c.updateSky()
 	case wtNether:
-		render.LightLevel = 0.9
 		render.SkyOffset = 0.0
 		render.ClearColour.R, render.ClearColour.G, render.ClearColour.B = 52/255.0, 8/255.0, 8/255.0
 	case wtEnd:
-		render.LightLevel = 0.8
 		render.SkyOffset = 0.0
 		render.ClearColour.R, render.ClearColour.G, render.ClearColour.B = 23/255.0, 0, 23/255.0
 	}
@@ -269,9 +379,32 @@ func (c *ClientState) updateSky() {
 	}
 	render.SkyOffset = c.calculateSky()
 	timeO := float32(render.SkyOffset) * 0.9
-	render.ClearColour.R = (122.0 / 255.0) * timeO
-	render.ClearColour.G = (165.0 / 255.0) * timeO
-	render.ClearColour.B = (247.0 / 255.0) * timeO
+	r := (122.0 / 255.0) * timeO
+	g := (165.0 / 255.0) * timeO
+	b := (247.0 / 255.0) * timeO
+
+	// Rain and thunder make the sky greyer
+	if rain := float32(c.weather.rain); rain > 0 {
+		grey := (r*0.3 + g*0.59 + b*0.11) * 0.6
+		r = r*(1-rain*0.75) + grey*rain*0.75
+		g = g*(1-rain*0.75) + grey*rain*0.75
+		b = b*(1-rain*0.75) + grey*rain*0.75
+	}
+	if thunder := float32(c.weather.thunder); thunder > 0 {
+		grey := (r*0.3 + g*0.59 + b*0.11) * 0.2
+		r = r*(1-thunder*0.75) + grey*thunder*0.75
+		g = g*(1-thunder*0.75) + grey*thunder*0.75
+		b = b*(1-thunder*0.75) + grey*thunder*0.75
+	}
+	// Lightning briefly lights up the sky
+	if flash := float32(c.weather.flash); flash > 0 {
+		render.SkyOffset += (1 - render.SkyOffset) * flash
+		f := flash * 0.45
+		r = r*(1-f) + 0.8*f
+		g = g*(1-f) + 0.8*f
+		b = b*(1-f) + 1.0*f
+	}
+	render.ClearColour.R, render.ClearColour.G, render.ClearColour.B = r, g, b
 }
 
 func (c *ClientState) calculateSky() float32 {
@@ -294,6 +427,7 @@ func (c *ClientState) calculateSky() float32 {
 		offset = 0
 	}
 	offset = 1 - offset
+	offset *= c.weather.skyMultiplier()
 	return float32(offset*0.8 + 0.2)
 }
 
@@ -305,6 +439,7 @@ func (c *ClientState) initEntity(head bool) {
 	ce.hasHead = head
 	ce.isFirstPerson = !head
 	ce.manualMove = true
+	ce.skipHeld = true
 	ce.SetCurrentItem(c.lastHotbarItem)
 	ce.bounds = c.Bounds
 }
@@ -327,21 +462,31 @@ func (c *ClientState) renderTick(delta float64) {
 	c.delta = delta
 	c.hotbarUI.SetX(-184 + 24 + 40*float64(c.currentHotbarSlot))
 	c.tickItemName()
+	c.tickArmor()
 
 	forward, yaw := c.calculateMovement()
 
 	c.LX, c.LY, c.LZ = c.X, c.Y, c.Z
 	lx, ly, lz := c.X, c.Y, c.Z
 
-	if c.GameMode.Fly() {
+	vehicle, riding := c.entities.vehicle(c.entityID)
+	c.updateSprinting(riding)
+	c.riding.tick(vehicle, riding, delta)
+	c.tickMountHealth(vehicle, riding)
+	c.tickAir()
+	if riding {
+		// The vehicle is moved by the server, the player just
+		// sits on it
+		if x, y, z, ok := seatPosition(vehicle, c.entity); ok {
+			c.X, c.Y, c.Z = x, y, z
+		}
+		c.VSpeed = 0
+	} else if c.GameMode.Fly() {
 		c.X += forward * math.Cos(yaw) * -math.Cos(c.Pitch) * delta * 0.2
 		c.Z -= forward * math.Sin(yaw) * -math.Cos(c.Pitch) * delta * 0.2
 		c.Y -= forward * math.Sin(c.Pitch) * delta * 0.2
 	} else if chunkMap[chunkPosition{int(math.Floor(c.X)) >> 4, int(math.Floor(c.Z)) >> 4}] != nil {
-		speed := 4.317 / 60.0
-		if c.KeyState[KeySprint] {
-			speed = 5.612 / 60.0
-		}
+		speed := 4.317 / 60.0 * c.movementSpeed() / playerWalkSpeed
 		if _, ok := chunkMap.Block(int(math.Floor(c.X)), int(math.Floor(c.Y)), int(math.Floor(c.Z))).(*blockLiquid); ok {
 			speed = 2.20 / 60.0
 			if c.KeyState[KeyJump] {
@@ -358,7 +503,7 @@ func (c *ClientState) renderTick(delta float64) {
 				c.VSpeed = -0.3
 			}
 		} else if c.KeyState[KeyJump] {
-			c.VSpeed = 0.15
+			c.VSpeed = 0.15 * c.effects.jumpMultiplier()
 		} else {
 			c.VSpeed = 0
 		}
@@ -367,7 +512,27 @@ func (c *ClientState) renderTick(delta float64) {
 		c.Y += c.VSpeed * delta
 	}
 
-	if !c.GameMode.NoClip() {
+	// Knockback from explosions and the like
+	if !riding && (c.VelocityX != 0 || c.VelocityZ != 0) {
+		c.X += c.VelocityX * delta
+		c.Z += c.VelocityZ * delta
+		friction := 0.91
+		if c.OnGround {
+			friction = 0.546
+		}
+		// Friction is per a tick, a tick is 3 frames
+		friction = math.Pow(friction, delta/3)
+		c.VelocityX *= friction
+		c.VelocityZ *= friction
+		if math.Abs(c.VelocityX) < 0.001 {
+			c.VelocityX = 0
+		}
+		if math.Abs(c.VelocityZ) < 0.001 {
+			c.VelocityZ = 0
+		}
+	}
+
+	if !riding && !c.GameMode.NoClip() {
 		cx := c.X
 		cy := c.Y
 		cz := c.Z
@@ -431,7 +596,8 @@ func (c *ClientState) renderTick(delta float64) {
 	c.entity.SetTargetPosition(c.X-ox, c.Y, c.Z-oz)
 	c.entity.SetTargetYaw(-c.Yaw)
 	c.entity.SetTargetPitch(-c.Pitch - math.Pi)
-	c.entity.walking = c.X != lx || c.Y != ly || c.Z != lz
+	c.entity.walking = !riding && (c.X != lx || c.Y != ly || c.Z != lz)
+	c.entity.SetRiding(riding)
 
 	audio.SetListenerPosition(float32(c.X), float32(c.Y+playerHeight), float32(c.Z))
 	view := c.viewVector()
@@ -463,14 +629,46 @@ func (c *ClientState) renderTick(delta float64) {
 	c.armTick()
 	c.chat.Draw(delta)
 
+	c.scoreboard.render()
+	c.titles.tick(delta)
 	c.playerList.render(delta)
 	c.entities.tick()
+	c.tickExplosions(delta)
+	c.border.tick(delta)
+	c.weather.tick(delta)
+	c.particles.tick(delta)
 	c.copyToCamera()
 
 	if c.TickTime {
 		c.WorldTime += delta
 	}
 	c.updateSky()
+	c.effects.tick(delta)
+	c.updateFOV(delta)
+}
+
+// updateFOV widens or narrows the field of view as the player's
+// movement speed changes.
+func (c *ClientState) updateFOV(delta float64) {
+	target := (c.movementSpeed()/playerWalkSpeed + 1) / 2
+	c.fovScale += (target - c.fovScale) * math.Min(1, delta/3*0.5)
+	render.FOVScale = c.fovScale
+}
+
+// tickArmor copies the armor in the player's inventory to
+// the player's model and updates the armor bar.
+func (c *ClientState) tickArmor() {
+	armor := 0
+	for i := 0; i < 4; i++ {
+		item := c.playerInventory.Items[invPlayerArmorOffset+i]
+		c.entity.SetEquipment(equipmentHelmet-i, item)
+		if mat, ok := armorMaterial(item, equipmentHelmet-i); ok {
+			armor += armorPoints[mat][equipmentHelmet-i]
+		}
+	}
+	if armor != c.Armor {
+		c.UpdateArmor(armor)
+	}
 }
 
 func (c *ClientState) tickItemName() {
@@ -527,6 +725,7 @@ func (c *ClientState) armTick() {
 			if !b.Is(Blocks.Air) {
 				name, vol, pitch := b.DigSound()
 				PlaySoundAt(name, vol, pitch, pos.Vec())
+				c.particles.spawnBlockHit(b, pos, face)
 			}
 		}
 
@@ -570,6 +769,7 @@ func (c *ClientState) armTick() {
 				c.killBreakEntity()
 				name, vol, pitch := b.BreakSound()
 				PlaySoundAt(name, vol, pitch, pos.Vec())
+				c.particles.spawnBlockBreak(b, pos)
 			} else {
 				stage := int(9 - math.Min(9, 10*(c.breakTime/c.maxBreakTime)))
 				if stage != c.breakEntity.(BlockBreakComponent).Stage() {
@@ -616,12 +816,19 @@ func (c *ClientState) UpdateHealth(health float64) {
 	}
 	if health == 0.0 {
 		setScreen(newRespawnScreen())
+	} else if c.entity.dead {
+		c.entity.revive()
 	}
 }
 
 func (c *ClientState) UpdateHunger(hunger float64) {
 	const maxHunger = 20.0
 	c.Hunger = hunger
+	if c.mountShown {
+		// Replaced by the mount's health until the player
+		// dismounts
+		return
+	}
 	hp := (hunger / maxHunger) * float64(len(c.foodFillUI))
 	for i, img := range c.foodFillUI {
 		i := float64(i)
@@ -641,6 +848,136 @@ func (c *ClientState) UpdateHunger(hunger float64) {
 	}
 }
 
+func (c *ClientState) UpdateArmor(armor int) {
+	c.Armor = armor
+	for i, img := range c.armorUI {
+		img.SetDraw(armor > 0)
+		switch {
+		case i*2+1 < armor:
+			img.SetTextureX(34.0 / 256.0)
+		case i*2+1 == armor:
+			img.SetTextureX(25.0 / 256.0)
+		default:
+			img.SetTextureX(16.0 / 256.0)
+		}
+	}
+}
+
+const maxAir = 300
+
+func (c *ClientState) UpdateAir(air int) {
+	c.Air = air
+}
+
+// tickAir shows the player's air whilst their head is under
+// water. Like vanilla the bubbles sit above the food bar or
+// the mount's health if that is shown instead.
+func (c *ClientState) tickAir() {
+	b := chunkMap.Block(int(math.Floor(c.X)), int(math.Floor(c.Y+playerHeight)), int(math.Floor(c.Z)))
+	l, ok := b.(*blockLiquid)
+	underWater := ok && !l.Lava
+	// Bubbles pop just before they run out
+	full := int(math.Ceil(float64(c.Air-2) * 10 / maxAir))
+	popping := int(math.Ceil(float64(c.Air)*10/maxAir)) - full
+	rows := 1
+	if c.mountShown {
+		rows = c.mountRows
+	}
+	for i, img := range c.airUI {
+		img.SetY(-16 - 8 - 10 - 20*float64(rows))
+		img.SetDraw(underWater && i < full+popping)
+		if i < full {
+			img.SetTextureX(16.0 / 256.0)
+		} else {
+			img.SetTextureX(25.0 / 256.0)
+		}
+	}
+}
+
+// maxMountHearts is the most hearts shown for the health of the
+// player's mount.
+const maxMountHearts = 30
+
+// tickMountHealth shows the health of the entity the player is
+// riding in place of the food bar.
+func (c *ClientState) tickMountHealth(vehicle Entity, riding bool) {
+	var health, maxHealth float64
+	show := false
+	if h, ok := vehicle.(HurtComponent); ok && riding {
+		health, show = h.Health()
+		maxHealth = health
+		if a, ok := vehicle.(AttributesComponent); ok {
+			if max, ok := a.Attribute(attrMaxHealth); ok {
+				maxHealth = max
+			}
+		}
+	}
+	if !show {
+		if c.mountShown {
+			c.mountShown = false
+			for i := range c.mountUI {
+				c.mountUI[i].SetDraw(false)
+				c.mountFillUI[i].SetDraw(false)
+			}
+			for _, img := range c.foodUI {
+				img.SetDraw(true)
+			}
+			c.UpdateHunger(c.Hunger)
+		}
+		return
+	}
+	if !c.mountShown {
+		c.mountShown = true
+		for i := range c.foodUI {
+			c.foodUI[i].SetDraw(false)
+			c.foodFillUI[i].SetDraw(false)
+		}
+	}
+
+	hearts := int(maxHealth+0.5) / 2
+	if hearts > maxMountHearts {
+		hearts = maxMountHearts
+	}
+	c.mountRows = (hearts + 9) / 10
+	if c.mountRows < 1 {
+		c.mountRows = 1
+	}
+	hp := health / 2
+	for i, img := range c.mountFillUI {
+		c.mountUI[i].SetDraw(i < hearts)
+		f := float64(i)
+		if f+0.5 < hp {
+			img.SetDraw(true)
+			img.SetTextureX(88.0 / 256.0)
+		} else if f < hp {
+			img.SetDraw(true)
+			img.SetTextureX(97.0 / 256.0)
+		} else {
+			img.SetDraw(false)
+		}
+	}
+}
+
+func (c *ClientState) UpdateExperience(bar float64, level int) {
+	c.Experience, c.ExpLevel = bar, level
+	c.expFillUI.SetWidth(182 * 2 * bar)
+	c.expFillUI.SetTextureWidth((182.0 / 256.0) * bar)
+	for _, t := range c.expLevelUI {
+		t.Update(strconv.Itoa(level))
+	}
+	c.showExpBar(!c.riding.chargedJump)
+}
+
+// showExpBar shows or hides the exp bar along with the player's
+// level, it is hidden whilst the jump bar is shown.
+func (c *ClientState) showExpBar(show bool) {
+	c.expBarUI.SetDraw(show)
+	c.expFillUI.SetDraw(show)
+	for _, t := range c.expLevelUI {
+		t.SetDraw(show && c.ExpLevel > 0)
+	}
+}
+
 func (c *ClientState) MouseAction(button glfw.MouseButton, down bool) {
 	if button == glfw.MouseButtonLeft {
 		c.isLeftDown = down
@@ -988,6 +1325,17 @@ func (c *ClientState) checkCollisions(bounds vmath.AABB) (vmath.AABB, bool) {
 			}
 		}
 	}
+
+	// The world border acts as a wall but only whilst the
+	// player is inside it.
+	if c.border.inside(c.LX, c.LZ) {
+		for _, bb := range c.border.collisionBoxes(bounds) {
+			if bb.Intersects(bounds) {
+				bounds = bounds.MoveOutOf(bb, dir)
+				hit = true
+			}
+		}
+	}
 	return bounds, hit
 }
 
@@ -1003,6 +1351,7 @@ func (c *ClientState) copyToCamera() {
 	render.Camera.Z = z
 	render.Camera.Yaw = c.Yaw
 	render.Camera.Pitch = c.Pitch
+	render.Camera.Roll = c.entity.hurtRoll()
 	switch c.cameraMode {
 	case cameraBehind:
 		render.Camera.X -= 4 * math.Cos(c.Yaw-math.Pi/2) * -math.Cos(c.Pitch)
@@ -1041,7 +1390,16 @@ func (c *ClientState) tick() {
 		onGround = true
 	}
 
-	if c.Health > 0 {
+	if _, ok := c.entities.vehicle(c.entityID); ok && c.Health > 0 {
+		// Whilst riding the server only wants to know where
+		// the player is looking and how they are steering
+		c.network.Write(&protocol.PlayerLook{
+			Yaw:      float32(-c.Yaw * (180 / math.Pi)),
+			Pitch:    float32((-c.Pitch - math.Pi) * (180 / math.Pi)),
+			OnGround: onGround,
+		})
+		c.network.Write(c.riding.steer())
+	} else if c.Health > 0 {
 		c.network.Write(&protocol.PlayerPositionLook{
 			X:        c.X,
 			Y:        c.Y,
@@ -15,6 +15,8 @@
 package steven
 
 import (
+	"strings"
+
 	"github.com/go-gl/glfw/v3.1/glfw"
 	"github.com/thinkofdeath/steven/console"
 	"github.com/thinkofdeath/steven/format"
@@ -45,6 +47,7 @@ type consoleScreen struct {
 
 	input      string
 	cursorTick float64
+	completion tabCompletion
 }
 
 func (cs *consoleScreen) init() {
@@ -82,6 +85,9 @@ func (cs *consoleScreen) onKey(w *glfw.Window, key glfw.Key, scancode int, actio
 			cs.input = cs.input[:len(cs.input)-1]
 		}
 	}
+	if key == glfw.KeyTab && action != glfw.Release {
+		cs.complete()
+	}
 	if key == glfw.KeyEnter && action == glfw.Release {
 		console.Component(format.
 			Build("> ").
@@ -105,6 +111,25 @@ func (cs *consoleScreen) onKey(w *glfw.Window, key glfw.Key, scancode int, actio
 	}
 }
 
+// complete completes the last word of the input using the
+// console's registered commands and the names of the players
+// on the server. Repeated presses cycle through the matches.
+func (cs *consoleScreen) complete() {
+	if cs.completion.active(cs.input) {
+		cs.input = cs.completion.next()
+		return
+	}
+	cs.completion.reset()
+	matches := completeLocal(cs.input)
+	if len(matches) == 0 {
+		return
+	}
+	if len(matches) > 1 {
+		console.Text("%s", strings.Join(matches, " "))
+	}
+	cs.input = cs.completion.set(cs.input, matches)
+}
+
 func (cs *consoleScreen) onChar(w *glfw.Window, char rune) {
 	cs.input += string(char)
 }
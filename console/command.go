@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -230,6 +231,72 @@ func (r *registry) exec(node *commandNode, args []string, vals ...reflect.Value)
 	return nil, vals, err
 }
 
+// Complete returns the possible values for the last argument
+// of the passed command. An empty last argument (e.g. a trailing
+// space) returns every possible value for that position.
+func Complete(cmd string) []string {
+	return defaultRegistry.Complete(cmd)
+}
+
+func (r *registry) Complete(cmd string) []string {
+	if r.root == nil {
+		return nil
+	}
+	parts := strings.Split(cmd, " ")
+	nodes := []*commandNode{r.root}
+	for _, part := range parts[:len(parts)-1] {
+		if part == "" {
+			continue
+		}
+		var next []*commandNode
+		for _, node := range nodes {
+			next = append(next, node.follow(part)...)
+		}
+		nodes = next
+	}
+
+	last := parts[len(parts)-1]
+	lower := strings.ToLower(last)
+	seen := map[string]bool{}
+	var out []string
+	add := func(v string) {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	for _, node := range nodes {
+		for name := range node.childNodes {
+			if strings.HasPrefix(name, lower) {
+				add(name)
+			}
+		}
+		for _, info := range node.types {
+			if c, ok := info.handler.(Completer); ok {
+				for _, v := range c.Complete(last, info.data) {
+					add(v)
+				}
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// follow returns the nodes reachable from this node using
+// the argument.
+func (cn *commandNode) follow(arg string) (out []*commandNode) {
+	for _, info := range cn.types {
+		if _, err := info.handler.ParseType(arg, info.data); err == nil {
+			out = append(out, info.node)
+		}
+	}
+	if node, ok := cn.childNodes[strings.ToLower(arg)]; ok {
+		out = append(out, node)
+	}
+	return out
+}
+
 type commandNode struct {
 	childNodes map[string]*commandNode
 	types      []typeInfo
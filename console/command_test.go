@@ -179,3 +179,32 @@ func shouldPanic(t *testing.T, f func()) {
 	}()
 	f()
 }
+
+func TestComplete(t *testing.T) {
+	r := registry{}
+	r.Register("hello world", func() {})
+	r.Register("hello wide", func() {})
+	r.Register("help", func() {})
+	r.Register("flag %", func(bool) {})
+	r.Register("set % value", func(int) {})
+
+	checkMatches(t, r.Complete("hel"), "hello", "help")
+	checkMatches(t, r.Complete("hello w"), "wide", "world")
+	checkMatches(t, r.Complete("hello wo"), "world")
+	checkMatches(t, r.Complete("hello "), "wide", "world")
+	checkMatches(t, r.Complete("flag t"), "true")
+	checkMatches(t, r.Complete("set 5 v"), "value")
+	checkMatches(t, r.Complete("set a v"))
+	checkMatches(t, r.Complete("missing "))
+}
+
+func checkMatches(t *testing.T, got []string, want ...string) {
+	if len(got) != len(want) {
+		t.Fatalf("got %v, wanted %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, wanted %v", got, want)
+		}
+	}
+}
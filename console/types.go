@@ -40,6 +40,14 @@ type TypeHandler interface {
 	Equals(a, b interface{}) bool
 }
 
+// Completer can optionally be implemented by a TypeHandler to
+// provide possible values for an argument during Complete.
+//
+// Complete should return the values that start with arg.
+type Completer interface {
+	Complete(arg string, info interface{}) []string
+}
+
 // RegisterType adds the passed type and handler to the the registry,
 // any future calls to Register will be able use the type added
 // here
@@ -145,3 +153,12 @@ func (boolHandler) ParseType(arg string, info interface{}) (interface{}, error)
 func (boolHandler) Equals(a, b interface{}) bool {
 	return true
 }
+
+func (boolHandler) Complete(arg string, info interface{}) (out []string) {
+	for _, v := range []string{"false", "true"} {
+		if strings.HasPrefix(v, strings.ToLower(arg)) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
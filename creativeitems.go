@@ -0,0 +1,250 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/thinkofdeath/steven/resource/locale"
+)
+
+type creativeTab int
+
+// Creative inventory tabs in the order vanilla displays them, the
+// first six along the top of the window and the rest along the
+// bottom.
+const (
+	creativeBuilding creativeTab = iota
+	creativeDecoration
+	creativeRedstone
+	creativeTransport
+	creativeMisc
+	creativeSearch
+	creativeFood
+	creativeTools
+	creativeCombat
+	creativeBrewing
+	creativeMaterials
+	creativeInventory
+	creativeTabCount
+)
+
+var creativeTabInfo = [creativeTabCount]struct {
+	locale, icon string
+}{
+	creativeBuilding:   {"itemGroup.buildingBlocks", "brick_block"},
+	creativeDecoration: {"itemGroup.decorations", "red_flower"},
+	creativeRedstone:   {"itemGroup.redstone", "redstone"},
+	creativeTransport:  {"itemGroup.transportation", "golden_rail"},
+	creativeMisc:       {"itemGroup.misc", "lava_bucket"},
+	creativeSearch:     {"itemGroup.search", "compass"},
+	creativeFood:       {"itemGroup.food", "apple"},
+	creativeTools:      {"itemGroup.tools", "iron_axe"},
+	creativeCombat:     {"itemGroup.combat", "golden_sword"},
+	creativeBrewing:    {"itemGroup.brewing", "glass_bottle"},
+	creativeMaterials:  {"itemGroup.materials", "stick"},
+	creativeInventory:  {"itemGroup.inventory", "chest"},
+}
+
+// Blocks that can't be held as an item or that are only obtainable
+// through commands.
+var creativeHiddenBlocks = map[string]bool{
+	"air": true, "flowing_water": true, "water": true, "flowing_lava": true,
+	"lava": true, "bed": true, "piston_head": true, "piston_extension": true,
+	"double_stone_slab": true, "fire": true, "mob_spawner": true,
+	"redstone_wire": true, "wheat": true, "farmland": true, "furnace_lit": true,
+	"standing_sign": true, "wall_sign": true, "wooden_door": true, "iron_door": true,
+	"redstone_ore_lit": true, "redstone_torch_unlit": true, "reeds": true,
+	"portal": true, "cake": true, "repeater_unpowered": true,
+	"repeater_powered": true, "pumpkin_stem": true, "melon_stem": true,
+	"nether_wart": true, "brewing_stand": true, "cauldron": true,
+	"end_portal": true, "dragon_egg": true, "redstone_lamp_lit": true,
+	"double_wooden_slab": true, "cocoa": true, "tripwire": true,
+	"command_block": true, "flower_pot": true, "carrots": true, "potatoes": true,
+	"skull": true, "comparator_unpowered": true, "comparator_powered": true,
+	"barrier": true, "standing_banner": true, "wall_banner": true,
+	"daylight_detector_inverted": true, "double_stone_slab2": true,
+	"spruce_door": true, "birch_door": true, "jungle_door": true,
+	"acacia_door": true, "dark_oak_door": true, "missing_block": true,
+}
+
+// creativeTabRule places items matching any of the patterns into
+// the tab. Patterns starting with an underscore match the end of
+// the name, others have to match exactly.
+type creativeTabRule struct {
+	tab      creativeTab
+	patterns []string
+}
+
+func (r creativeTabRule) matches(name string) bool {
+	for _, p := range r.patterns {
+		if p[0] == '_' && strings.HasSuffix(name, p) || p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Blocks that don't match a rule are building blocks.
+var creativeBlockRules = []creativeTabRule{
+	{creativeRedstone, []string{
+		"dispenser", "dropper", "hopper", "noteblock", "sticky_piston", "piston",
+		"tnt", "lever", "redstone_torch", "redstone_block", "redstone_lamp",
+		"_pressure_plate", "_button", "trap_door", "iron_trap_door",
+		"_fence_gate", "tripwire_hook", "trapped_chest", "daylight_detector",
+	}},
+	{creativeTransport, []string{"_rail", "rail"}},
+	{creativeDecoration, []string{
+		"sapling", "leaves", "leaves2", "web", "tallgrass", "deadbush",
+		"yellow_flower", "red_flower", "brown_mushroom", "red_mushroom",
+		"double_plant", "vine", "waterlily", "cactus", "torch", "ladder",
+		"snow_layer", "chest", "ender_chest", "crafting_table", "furnace",
+		"jukebox", "_fence", "fence", "cobblestone_wall", "iron_bars",
+		"glass_pane", "stained_glass_pane", "carpet", "monster_egg",
+		"enchanting_table", "end_portal_frame", "anvil", "hay_block", "slime",
+		"brown_mushroom_block", "red_mushroom_block",
+	}},
+}
+
+// Items that don't match a rule go into the miscellaneous tab.
+var creativeItemRules = []creativeTabRule{
+	{creativeTransport, []string{
+		"minecart", "_minecart", "saddle", "boat", "carrot_on_a_stick",
+	}},
+	{creativeRedstone, []string{"redstone", "repeater", "comparator", "_door"}},
+	{creativeBrewing, []string{
+		"potion", "glass_bottle", "nether_wart", "ghast_tear", "spider_eye",
+		"fermented_spider_eye", "blaze_powder", "magma_cream", "brewing_stand",
+		"cauldron", "speckled_melon", "sugar", "rabbit_foot",
+	}},
+	{creativeMaterials, []string{
+		"coal", "diamond", "_ingot", "emerald", "stick", "bowl", "string",
+		"feather", "gunpowder", "wheat_seeds", "wheat", "flint", "leather",
+		"brick", "netherbrick", "clay_ball", "paper", "book", "slime_ball",
+		"glowstone_dust", "dye", "bone", "pumpkin_seeds", "melon_seeds",
+		"blaze_rod", "gold_nugget", "nether_star", "quartz", "prismarine_shard",
+		"prismarine_crystals", "rabbit_hide",
+	}},
+	{creativeCombat, []string{
+		"_sword", "bow", "arrow", "_helmet", "_chestplate", "_leggings", "_boots",
+	}},
+	{creativeTools, []string{
+		"_shovel", "_pickaxe", "_axe", "_hoe", "flint_and_steel", "shears",
+		"fishing_rod", "compass", "clock", "lead", "name_tag", "bucket",
+		"_bucket", "enchanted_book",
+	}},
+	{creativeFood, []string{
+		"apple", "golden_apple", "mushroom_stew", "bread", "porkchop",
+		"cooked_porkchop", "fish", "cooked_fish", "cake", "cookie", "melon",
+		"beef", "cooked_beef", "chicken", "cooked_chicken", "rotten_flesh",
+		"carrot", "potato", "baked_potato", "poisonous_potato",
+		"golden_carrot", "pumpkin_pie", "rabbit", "cooked_rabbit",
+		"rabbit_stew", "mutton", "cooked_mutton",
+	}},
+	{creativeDecoration, []string{
+		"painting", "sign", "bed", "item_frame", "flower_pot", "skull",
+		"banner", "armor_stand",
+	}},
+}
+
+func creativeTabFor(name string, rules []creativeTabRule, def creativeTab) creativeTab {
+	for _, r := range rules {
+		if r.matches(name) {
+			return r.tab
+		}
+	}
+	return def
+}
+
+// creativeItem is an item that can be picked from the creative
+// inventory.
+type creativeItem struct {
+	item *ItemStack
+	tab  creativeTab
+	// The localized name of the item in lowercase for
+	// searching.
+	name string
+}
+
+// creativeItems returns every item that can be picked from the
+// creative inventory. Items without a model are skipped as they
+// can't be displayed.
+func creativeItems() (items []creativeItem) {
+	add := func(item *ItemStack, tab creativeTab) {
+		if getModel(item.Type.Name()) == nil {
+			return
+		}
+		name := locale.GetRaw(item.Type.NameLocaleKey())
+		if strings.HasPrefix(name, "!") {
+			name = item.Type.Name()
+		}
+		items = append(items, creativeItem{
+			item: item,
+			tab:  tab,
+			name: strings.ToLower(name),
+		})
+	}
+
+	for _, bs := range blockSetsByID {
+		if bs == nil || creativeHiddenBlocks[bs.Base.Name()] {
+			continue
+		}
+		tab := creativeTabFor(bs.Base.Name(), creativeBlockRules, creativeBuilding)
+		// Variants are stored in the damage value, the rest
+		// of the damage values are things like the rotation
+		// which have the same model.
+		seen := map[string]bool{}
+		for d := int16(0); d < 16; d++ {
+			ty := ItemOfBlock(bs.Base)
+			ty.ParseDamage(d)
+			if seen[ty.Name()] {
+				continue
+			}
+			seen[ty.Name()] = true
+			add(&ItemStack{
+				Type:      ty,
+				Count:     1,
+				rawID:     int16(bs.ID),
+				rawDamage: d,
+			}, tab)
+		}
+	}
+
+	ids := make([]int, 0, len(itemsByID))
+	for id := range itemsByID {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		base := itemsByID[id]()
+		tab := creativeTabFor(base.Name(), creativeItemRules, creativeMisc)
+		damages := []int16{0}
+		if v, ok := base.(ItemVariants); ok {
+			damages = v.Variants()
+		}
+		for _, d := range damages {
+			ty := itemsByID[id]()
+			ty.ParseDamage(d)
+			add(&ItemStack{
+				Type:      ty,
+				Count:     1,
+				rawID:     int16(id),
+				rawDamage: d,
+			}, tab)
+		}
+	}
+	return items
+}
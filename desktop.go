@@ -136,6 +136,10 @@ func onMouseMove(w *glfw.Window, xpos float64, ypos float64) {
 		currentScreen.hover(xpos*(float64(fw)/float64(width)), ypos*(float64(fh)/float64(height)), fw, fh)
 		return
 	}
+	if Client.chat.enteringText {
+		fw, fh := w.GetFramebufferSize()
+		Client.chat.handleMouseMove(xpos*(float64(fw)/float64(width)), ypos*(float64(fh)/float64(height)), fw, fh)
+	}
 	if !lockMouse {
 		return
 	}
@@ -148,13 +152,29 @@ func onMouseMove(w *glfw.Window, xpos float64, ypos float64) {
 
 func onMouseClick(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mod glfw.ModifierKey) {
 	if currentScreen != nil {
-		if button != glfw.MouseButtonLeft || action == glfw.Repeat {
+		if action == glfw.Repeat {
+			return
+		}
+		bs, ok := currentScreen.(buttonScreen)
+		if !ok && button != glfw.MouseButtonLeft {
+			return
+		}
+		width, height := w.GetSize()
+		xpos, ypos := w.GetCursorPos()
+		fw, fh := w.GetFramebufferSize()
+		x, y := xpos*(float64(fw)/float64(width)), ypos*(float64(fh)/float64(height))
+		if ok {
+			bs.mouseButton(button, action == glfw.Press, mod, x, y, fw, fh)
 			return
 		}
+		currentScreen.click(action == glfw.Press, x, y, fw, fh)
+		return
+	}
+	if Client.chat.enteringText && button == glfw.MouseButtonLeft && action == glfw.Release {
 		width, height := w.GetSize()
 		xpos, ypos := w.GetCursorPos()
 		fw, fh := w.GetFramebufferSize()
-		currentScreen.click(action == glfw.Press, xpos*(float64(fw)/float64(width)), ypos*(float64(fh)/float64(height)), fw, fh)
+		Client.chat.handleClick(w, xpos*(float64(fw)/float64(width)), ypos*(float64(fh)/float64(height)), fw, fh, mod)
 		return
 	}
 	if !Client.chat.enteringText && lockMouse && action != glfw.Repeat {
@@ -175,6 +195,7 @@ const (
 	KeyRight
 	KeySprint
 	KeyJump
+	KeySneak
 )
 
 var keyStateMap = map[glfw.Key]Key{
@@ -184,6 +205,7 @@ var keyStateMap = map[glfw.Key]Key{
 	glfw.KeyD:           KeyRight,
 	glfw.KeyLeftControl: KeySprint,
 	glfw.KeySpace:       KeyJump,
+	glfw.KeyLeftShift:   KeySneak,
 }
 
 func onChar(w *glfw.Window, char rune) {
@@ -241,6 +263,10 @@ func onKey(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods
 			Client.playerList.set(false)
 		}
 	case glfw.KeyE:
+		if action == glfw.Release && Client.GameMode == gmCreative {
+			setScreen(newCreativeScreen())
+			return
+		}
 		if action == glfw.Release {
 			wasPlayer := Client.activeInventory == Client.playerInventory
 			closeInventory()
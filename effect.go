@@ -0,0 +1,190 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/thinkofdeath/steven/protocol"
+)
+
+// Sounds played by the effect packet
+var effectSounds = map[int]struct {
+	name         string
+	vol, pitch   float64
+	randomPitch  bool
+	randomVolume bool
+}{
+	1000: {name: "random.click", vol: 1, pitch: 1},
+	1001: {name: "random.click", vol: 1, pitch: 1.2},
+	1002: {name: "random.bow", vol: 1, pitch: 1.2},
+	1004: {name: "random.fizz", vol: 0.5, pitch: 2.6, randomPitch: true},
+	1007: {name: "mob.ghast.charge", vol: 10, pitch: 1, randomPitch: true},
+	1008: {name: "mob.ghast.fireball", vol: 10, pitch: 1, randomPitch: true},
+	1009: {name: "mob.ghast.fireball", vol: 2, pitch: 1, randomPitch: true},
+	1010: {name: "mob.zombie.wood", vol: 2, pitch: 1, randomPitch: true},
+	1011: {name: "mob.zombie.metal", vol: 2, pitch: 1, randomPitch: true},
+	1012: {name: "mob.zombie.woodbreak", vol: 2, pitch: 1, randomPitch: true},
+	1013: {name: "mob.wither.spawn", vol: 1, pitch: 1},
+	1014: {name: "mob.wither.shoot", vol: 2, pitch: 1, randomPitch: true},
+	1015: {name: "mob.bat.takeoff", vol: 0.05, pitch: 1, randomPitch: true},
+	1016: {name: "mob.zombie.infect", vol: 2, pitch: 1, randomPitch: true},
+	1017: {name: "mob.zombie.unfect", vol: 2, pitch: 1, randomPitch: true},
+	1018: {name: "mob.enderdragon.end", vol: 5, pitch: 1},
+	1020: {name: "random.anvil_break", vol: 1, pitch: 0.9, randomPitch: true},
+	1021: {name: "random.anvil_use", vol: 1, pitch: 0.9, randomPitch: true},
+	1022: {name: "random.anvil_land", vol: 0.3, pitch: 0.9, randomPitch: true},
+}
+
+// playEffect handles the sounds and particles for the effect
+// packet.
+func (c *ClientState) playEffect(p *protocol.Effect) {
+	pos := Position{p.Location.X(), p.Location.Y(), p.Location.Z()}
+	x, y, z := float64(pos.X)+0.5, float64(pos.Y)+0.5, float64(pos.Z)+0.5
+	data := int(p.Data)
+
+	if snd, ok := effectSounds[int(p.EffectID)]; ok {
+		pitch := snd.pitch
+		if snd.randomPitch {
+			pitch += (rand.Float64() - rand.Float64()) * 0.2
+		}
+		c.playEffectSound(snd.name, snd.vol, pitch, x, y, z, p.DisableRelative)
+		return
+	}
+
+	switch p.EffectID {
+	case 1003: // Door
+		name := "random.door_open"
+		if rand.Intn(2) == 0 {
+			name = "random.door_close"
+		}
+		c.playEffectSound(name, 1, rand.Float64()*0.1+0.9, x, y, z, p.DisableRelative)
+	case 1005: // Record
+		// TODO Records are streamed music which isn't
+		// positional yet
+	case 2000: // Smoke
+		dx, dz := float64(data%3-1), float64(data/3%3-1)
+		for i := 0; i < 10; i++ {
+			d := rand.Float64()*0.2 + 0.01
+			c.particles.spawn(11,
+				x+dx*0.6+randRange(0.3)*math.Abs(dz),
+				y+randRange(0.5),
+				z+dz*0.6+randRange(0.3)*math.Abs(dx),
+				dx*d+rand.NormFloat64()*0.01,
+				-0.03+rand.NormFloat64()*0.01,
+				dz*d+rand.NormFloat64()*0.01,
+				nil,
+			)
+		}
+	case 2001: // Block break
+		b := blockFromStateID(data)
+		if !b.Is(Blocks.Air) {
+			name, vol, pitch := b.BreakSound()
+			PlaySoundAt(name, vol, pitch, pos.Vec())
+		}
+		c.particles.spawnBlockBreak(b, pos)
+	case 2002: // Splash potion
+		y = float64(pos.Y)
+		for i := 0; i < 8; i++ {
+			c.particles.spawn(36, x, y, z,
+				rand.NormFloat64()*0.15, rand.Float64()*0.2, rand.NormFloat64()*0.15,
+				[]int{373, data},
+			)
+		}
+		r, g, b := potionColor(data)
+		id := 13
+		if data&0x4000 != 0 {
+			// Instant potions
+			id = 14
+		}
+		for i := 0; i < 100; i++ {
+			speed := rand.Float64() * 4
+			angle := rand.Float64() * math.Pi * 2
+			vx, vz := math.Cos(angle)*speed, math.Sin(angle)*speed
+			c.particles.spawn(id,
+				x+vx*0.1, y+0.3, z+vz*0.1,
+				vx, 0.01+rand.Float64()*0.5, vz,
+				nil,
+			)
+			// Tint the last spawned particle with the
+			// potion's colour.
+			if n := len(c.particles.particles); n > 0 {
+				f := 0.75 + rand.Float64()*0.25
+				pp := c.particles.particles[n-1]
+				pp.r, pp.g, pp.b = float64(r)/255*f, float64(g)/255*f, float64(b)/255*f
+			}
+		}
+		c.playEffectSound("game.potion.smash", 1, rand.Float64()*0.1+0.9, x, y, z, p.DisableRelative)
+	case 2003: // Eye of ender
+		for i := 0; i < 8; i++ {
+			c.particles.spawn(33, x, y, z,
+				rand.NormFloat64()*0.15, rand.Float64()*0.2, rand.NormFloat64()*0.15,
+				nil,
+			)
+		}
+		for a := 0.0; a < math.Pi*2; a += math.Pi / 20 {
+			c.particles.spawn(24,
+				x+math.Cos(a)*5, y-0.4, z+math.Sin(a)*5,
+				math.Cos(a)*-5, 0, math.Sin(a)*-5,
+				nil,
+			)
+			c.particles.spawn(24,
+				x+math.Cos(a)*5, y-0.4, z+math.Sin(a)*5,
+				math.Cos(a)*-7, 0, math.Sin(a)*-7,
+				nil,
+			)
+		}
+	case 2004: // Mob spawner
+		for i := 0; i < 20; i++ {
+			px := x + randRange(0.5)
+			py := y + randRange(0.5)
+			pz := z + randRange(0.5)
+			c.particles.spawn(11, px, py, pz, 0, 0, 0, nil)
+			c.particles.spawn(26, px, py, pz, 0, 0, 0, nil)
+		}
+	case 2005: // Bonemeal
+		if data == 0 {
+			data = 15
+		}
+		b := chunkMap.Block(pos.X, pos.Y, pos.Z)
+		bounds := b.CollisionBounds()
+		height := 1.0
+		if len(bounds) > 0 {
+			height = float64(bounds[0].Max.Y())
+		}
+		for i := 0; i < data; i++ {
+			c.particles.spawn(21,
+				float64(pos.X)+rand.Float64(),
+				float64(pos.Y)+rand.Float64()*height,
+				float64(pos.Z)+rand.Float64(),
+				rand.NormFloat64()*0.02, rand.NormFloat64()*0.02, rand.NormFloat64()*0.02,
+				nil,
+			)
+		}
+	}
+}
+
+// playEffectSound plays the sound at the location unless the
+// sound is global in which case it is played at the player's
+// position.
+func (c *ClientState) playEffectSound(name string, vol, pitch, x, y, z float64, global bool) {
+	if global {
+		PlaySound(name)
+		return
+	}
+	PlaySoundAt(name, vol, pitch, mgl32.Vec3{float32(x), float32(y), float32(z)})
+}
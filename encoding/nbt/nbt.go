@@ -53,6 +53,18 @@ func NewCompound() *Compound {
 	}
 }
 
+// Copy returns a deep copy of the compound.
+func (c *Compound) Copy() *Compound {
+	n := &Compound{
+		Name:  c.Name,
+		Items: make(map[string]interface{}, len(c.Items)),
+	}
+	for k, v := range c.Items {
+		n.Items[k] = copyValue(v)
+	}
+	return n
+}
+
 func (c *Compound) Serialize(w io.Writer) error {
 	err := writeString(w, c.Name)
 	if err != nil {
@@ -175,6 +187,32 @@ type List struct {
 	Elements []interface{}
 }
 
+// Copy returns a deep copy of the list.
+func (l *List) Copy() *List {
+	n := &List{
+		Type:     l.Type,
+		Elements: make([]interface{}, len(l.Elements)),
+	}
+	for i, e := range l.Elements {
+		n.Elements[i] = copyValue(e)
+	}
+	return n
+}
+
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case *Compound:
+		return v.Copy()
+	case *List:
+		return v.Copy()
+	case []byte:
+		return append([]byte(nil), v...)
+	case []int32:
+		return append([]int32(nil), v...)
+	}
+	return v
+}
+
 func (l *List) serialize(w io.Writer) error {
 	if err := writeByte(w, byte(l.Type)); err != nil {
 		return err
@@ -0,0 +1,41 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbt
+
+import "testing"
+
+func TestCompoundCopy(t *testing.T) {
+	c, err := ParseText(`{display:{Name:"a",Lore:["b"]},ench:[{id:1s}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := c.Copy()
+
+	display := n.Items["display"].(*Compound)
+	display.Items["Name"] = "changed"
+	display.Items["Lore"].(*List).Elements[0] = "changed"
+	n.Items["ench"].(*List).Elements[0].(*Compound).Items["id"] = int16(2)
+
+	display = c.Items["display"].(*Compound)
+	if display.Items["Name"] != "a" {
+		t.Errorf("name changed to %v", display.Items["Name"])
+	}
+	if l := display.Items["Lore"].(*List).Elements[0]; l != "b" {
+		t.Errorf("lore changed to %v", l)
+	}
+	if id := c.Items["ench"].(*List).Elements[0].(*Compound).Items["id"]; id != int16(1) {
+		t.Errorf("enchantment changed to %v", id)
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidText = errors.New("invalid nbt text")
+
+// ParseText parses the text form of a compound as used in
+// commands and chat hover events, e.g.
+//
+//     {id:"minecraft:stone",Count:1b,tag:{display:{Name:"Rock"}}}
+//
+// Values are parsed into the same types used by Deserialize.
+func ParseText(str string) (*Compound, error) {
+	p := &textParser{str: str}
+	p.skipSpace()
+	v, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	c, ok := v.(*Compound)
+	if !ok {
+		return nil, ErrInvalidText
+	}
+	return c, nil
+}
+
+type textParser struct {
+	str string
+	pos int
+}
+
+func (p *textParser) skipSpace() {
+	for p.pos < len(p.str) && (p.str[p.pos] == ' ' || p.str[p.pos] == '\t' || p.str[p.pos] == '\n') {
+		p.pos++
+	}
+}
+
+func (p *textParser) peek() byte {
+	if p.pos >= len(p.str) {
+		return 0
+	}
+	return p.str[p.pos]
+}
+
+func (p *textParser) expect(b byte) error {
+	p.skipSpace()
+	if p.peek() != b {
+		return fmt.Errorf("nbt: expected %q at %d", b, p.pos)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *textParser) value() (interface{}, error) {
+	p.skipSpace()
+	switch p.peek() {
+	case '{':
+		return p.compound()
+	case '[':
+		return p.list()
+	case '"':
+		return p.quoted()
+	case 0:
+		return nil, ErrInvalidText
+	}
+	return parsePrimitive(p.unquoted(false)), nil
+}
+
+func (p *textParser) compound() (*Compound, error) {
+	p.pos++
+	c := NewCompound()
+	for {
+		p.skipSpace()
+		if p.peek() == '}' {
+			p.pos++
+			return c, nil
+		}
+		var key string
+		if p.peek() == '"' {
+			var err error
+			if key, err = p.quoted(); err != nil {
+				return nil, err
+			}
+		} else {
+			key = p.unquoted(true)
+		}
+		if err := p.expect(':'); err != nil {
+			return nil, err
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		c.Items[key] = v
+		if !p.next('}') {
+			return nil, ErrInvalidText
+		}
+	}
+}
+
+func (p *textParser) list() (*List, error) {
+	p.pos++
+	l := &List{}
+	for {
+		p.skipSpace()
+		if p.peek() == ']' {
+			p.pos++
+			break
+		}
+		// Older versions prefix elements with their index
+		start := p.pos
+		for p.pos < len(p.str) && p.str[p.pos] >= '0' && p.str[p.pos] <= '9' {
+			p.pos++
+		}
+		if p.peek() == ':' && p.pos != start {
+			p.pos++
+		} else {
+			p.pos = start
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		if len(l.Elements) == 0 {
+			l.Type = getTypeID(v)
+		}
+		l.Elements = append(l.Elements, v)
+		if !p.next(']') {
+			return nil, ErrInvalidText
+		}
+	}
+	return l, nil
+}
+
+// next consumes a comma or the closing character. Returns false
+// if neither was found. The closing character is left to be
+// consumed by the caller.
+func (p *textParser) next(end byte) bool {
+	p.skipSpace()
+	switch p.peek() {
+	case ',':
+		p.pos++
+		return true
+	case end:
+		return true
+	}
+	return false
+}
+
+func (p *textParser) quoted() (string, error) {
+	p.pos++
+	var buf []byte
+	for p.pos < len(p.str) {
+		b := p.str[p.pos]
+		p.pos++
+		switch b {
+		case '\\':
+			if p.pos < len(p.str) {
+				buf = append(buf, p.str[p.pos])
+				p.pos++
+			}
+		case '"':
+			return string(buf), nil
+		default:
+			buf = append(buf, b)
+		}
+	}
+	return "", ErrInvalidText
+}
+
+// unquoted reads a plain value. Keys also stop at a colon
+// whilst values may contain them (e.g. minecraft:stone).
+func (p *textParser) unquoted(key bool) string {
+	start := p.pos
+	for p.pos < len(p.str) {
+		switch p.str[p.pos] {
+		case ',', '}', ']':
+			return strings.TrimSpace(p.str[start:p.pos])
+		case ':':
+			if key {
+				return strings.TrimSpace(p.str[start:p.pos])
+			}
+		}
+		p.pos++
+	}
+	return strings.TrimSpace(p.str[start:p.pos])
+}
+
+// parsePrimitive converts the plain value into a number if
+// possible using the type suffix, otherwise it is returned as
+// a string.
+func parsePrimitive(v string) interface{} {
+	if len(v) == 0 {
+		return v
+	}
+	num, suffix := v[:len(v)-1], v[len(v)-1]
+	switch suffix {
+	case 'b', 'B':
+		if i, err := strconv.ParseInt(num, 10, 8); err == nil {
+			return int8(i)
+		}
+	case 's', 'S':
+		if i, err := strconv.ParseInt(num, 10, 16); err == nil {
+			return int16(i)
+		}
+	case 'l', 'L':
+		if i, err := strconv.ParseInt(num, 10, 64); err == nil {
+			return i
+		}
+	case 'f', 'F':
+		if f, err := strconv.ParseFloat(num, 32); err == nil {
+			return float32(f)
+		}
+	case 'd', 'D':
+		if f, err := strconv.ParseFloat(num, 64); err == nil {
+			return f
+		}
+	}
+	if i, err := strconv.ParseInt(v, 10, 32); err == nil {
+		return int32(i)
+	}
+	if strings.ContainsRune(v, '.') {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	switch v {
+	case "true":
+		return int8(1)
+	case "false":
+		return int8(0)
+	}
+	return v
+}
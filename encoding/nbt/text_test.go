@@ -0,0 +1,144 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTextValues(t *testing.T) {
+	tests := []struct {
+		text string
+		want interface{}
+	}{
+		// Numbers and their suffixes
+		{`{v:1b}`, int8(1)},
+		{`{v:-2B}`, int8(-2)},
+		{`{v:300s}`, int16(300)},
+		{`{v:5}`, int32(5)},
+		{`{v:-7}`, int32(-7)},
+		{`{v:9000000000l}`, int64(9000000000)},
+		{`{v:1.5f}`, float32(1.5)},
+		{`{v:2.25d}`, float64(2.25)},
+		{`{v:2.25}`, float64(2.25)},
+		{`{v:true}`, int8(1)},
+		{`{v:false}`, int8(0)},
+		// Out of range values aren't numbers
+		{`{v:300b}`, "300b"},
+		// Strings
+		{`{v:minecraft:stone}`, "minecraft:stone"},
+		{`{v:"hello, world"}`, "hello, world"},
+		{`{v:"say \"hi\""}`, `say "hi"`},
+		{`{v:"back\\slash"}`, `back\slash`},
+		{`{v:"5b"}`, "5b"},
+		{`{v:}`, ""},
+		{`{ v : spaced }`, "spaced"},
+		// Like vanilla unquoted values may contain spaces
+		{`{v:Some Name}`, "Some Name"},
+	}
+	for _, test := range tests {
+		c, err := ParseText(test.text)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", test.text, err)
+			continue
+		}
+		if got := c.Items["v"]; !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %#v (%T), wanted %#v (%T)", test.text, got, got, test.want, test.want)
+		}
+	}
+}
+
+func TestParseTextNested(t *testing.T) {
+	c, err := ParseText(`{id:"minecraft:stone",tag:{display:{Name:"Rock",Lore:["a","b"]}},list:[0:1,1:2],nested:[[1b],[2b,3b]],empty:[],"quoted key":1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Items["id"] != "minecraft:stone" {
+		t.Errorf("wrong id %#v", c.Items["id"])
+	}
+	tag, ok := c.Items["tag"].(*Compound)
+	if !ok {
+		t.Fatalf("tag isn't a compound: %#v", c.Items["tag"])
+	}
+	display, ok := tag.Items["display"].(*Compound)
+	if !ok {
+		t.Fatalf("display isn't a compound: %#v", tag.Items["display"])
+	}
+	if display.Items["Name"] != "Rock" {
+		t.Errorf("wrong name %#v", display.Items["Name"])
+	}
+	lore, ok := display.Items["Lore"].(*List)
+	if !ok || lore.Type != TagString || !reflect.DeepEqual(lore.Elements, []interface{}{"a", "b"}) {
+		t.Errorf("wrong lore %#v", display.Items["Lore"])
+	}
+
+	// Indexed list elements used by older versions
+	list, ok := c.Items["list"].(*List)
+	if !ok || list.Type != TagInt || !reflect.DeepEqual(list.Elements, []interface{}{int32(1), int32(2)}) {
+		t.Errorf("wrong indexed list %#v", c.Items["list"])
+	}
+
+	nested, ok := c.Items["nested"].(*List)
+	if !ok || nested.Type != TagList || len(nested.Elements) != 2 {
+		t.Fatalf("wrong nested list %#v", c.Items["nested"])
+	}
+	inner, ok := nested.Elements[1].(*List)
+	if !ok || inner.Type != TagByte || !reflect.DeepEqual(inner.Elements, []interface{}{int8(2), int8(3)}) {
+		t.Errorf("wrong inner list %#v", nested.Elements[1])
+	}
+
+	if empty, ok := c.Items["empty"].(*List); !ok || len(empty.Elements) != 0 {
+		t.Errorf("wrong empty list %#v", c.Items["empty"])
+	}
+	if c.Items["quoted key"] != int32(1) {
+		t.Errorf("wrong quoted key value %#v", c.Items["quoted key"])
+	}
+}
+
+func TestParseTextInvalid(t *testing.T) {
+	tests := []string{
+		``,
+		`   `,
+		`5b`,
+		`"string"`,
+		`[1,2]`,
+		`{`,
+		`{a`,
+		`{a:`,
+		`{a:1`,
+		`{a:1,`,
+		`{a 1}`,
+		`{a:"unterminated}`,
+		`{a:"trailing escape\`,
+		`{"key`,
+		`{a:[`,
+		`{a:[1`,
+		`{a:[1,`,
+		`{a:{b:{c:[{d:`,
+	}
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("%q: panicked: %v", test, r)
+				}
+			}()
+			if c, err := ParseText(test); err == nil {
+				t.Errorf("%q: expected an error, got %#v", test, c)
+			}
+		}()
+	}
+}
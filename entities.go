@@ -24,14 +24,19 @@ func newPlayer() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
 		playerComponent
 		playerModelComponent
+		equipmentComponent
 	}
 	p := &player{}
 	p.hasHead = true
 	p.hasNameTag = true
 	p.isFirstPerson = false
+	p.skipHeld = true
 	p.bounds = vmath.NewAABB(-0.3, 0, -0.3, 0.6, 2.0, 0.6)
 	return p
 }
@@ -44,11 +49,14 @@ func newCreeper() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	c := &creeper{
-		debugComponent: debugComponent{16, 117, 55},
+		mobModelComponent: mobModelComponent{name: "creeper"},
 	}
 	c.NetworkID = 50
 	c.bounds = vmath.NewAABB(-0.2, 0, -0.2, 0.4, 1.5, 0.4)
@@ -63,11 +71,15 @@ func newSkeleton() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
+		equipmentComponent
 	}
 	s := &skeleton{
-		debugComponent: debugComponent{255, 255, 255},
+		mobModelComponent: mobModelComponent{name: "skeleton"},
 	}
 	s.NetworkID = 51
 	s.bounds = vmath.NewAABB(-0.3, 0, -0.3, 0.6, 1.8, 0.6)
@@ -82,11 +94,14 @@ func newSpider() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	s := &spider{
-		debugComponent: debugComponent{59, 7, 7},
+		mobModelComponent: mobModelComponent{name: "spider"},
 	}
 	s.NetworkID = 52
 	s.bounds = vmath.NewAABB(-0.7, 0, -0.7, 1.4, 0.9, 1.4)
@@ -101,11 +116,15 @@ func newZombie() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
+		equipmentComponent
 	}
 	z := &zombie{
-		debugComponent: debugComponent{17, 114, 156},
+		mobModelComponent: mobModelComponent{name: "zombie"},
 	}
 	z.NetworkID = 54
 	z.bounds = vmath.NewAABB(-0.3, 0, -0.3, 0.6, 1.8, 0.6)
@@ -120,11 +139,14 @@ func newSlime() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	s := &slime{
-		debugComponent: debugComponent{17, 114, 156},
+		mobModelComponent: mobModelComponent{name: "slime"},
 	}
 	s.NetworkID = 55
 	s.bounds = vmath.NewAABB(-0.5, 0, -0.5, 1, 1, 1)
@@ -139,11 +161,14 @@ func newGhast() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	g := &ghast{
-		debugComponent: debugComponent{191, 191, 191},
+		mobModelComponent: mobModelComponent{name: "ghast"},
 	}
 	g.NetworkID = 56
 	g.bounds = vmath.NewAABB(-2, 0, -2, 4, 4, 4)
@@ -158,11 +183,15 @@ func newZombiePigman() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
+		equipmentComponent
 	}
 	z := &zombiePigman{
-		debugComponent: debugComponent{204, 110, 198},
+		mobModelComponent: mobModelComponent{name: "zombie_pigman"},
 	}
 	z.NetworkID = 57
 	z.bounds = vmath.NewAABB(-0.3, 0, -0.3, 0.6, 1.8, 0.6)
@@ -177,11 +206,14 @@ func newEnderman() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	e := &enderman{
-		debugComponent: debugComponent{74, 0, 69},
+		mobModelComponent: mobModelComponent{name: "enderman"},
 	}
 	e.NetworkID = 58
 	e.bounds = vmath.NewAABB(-0.3, 0, -0.3, 0.6, 2.9, 0.6)
@@ -196,11 +228,14 @@ func newCaveSpider() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	c := &caveSpider{
-		debugComponent: debugComponent{0, 116, 232},
+		mobModelComponent: mobModelComponent{name: "cave_spider"},
 	}
 	c.NetworkID = 59
 	c.bounds = vmath.NewAABB(-0.35, 0, -0.35, 0.7, 0.5, 0.7)
@@ -215,11 +250,14 @@ func newSilverfish() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	s := &silverfish{
-		debugComponent: debugComponent{128, 128, 128},
+		mobModelComponent: mobModelComponent{name: "silverfish"},
 	}
 	s.NetworkID = 60
 	s.bounds = vmath.NewAABB(-0.2, 0, -0.2, 0.4, 0.3, 0.4)
@@ -234,11 +272,14 @@ func newBlaze() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	b := &blaze{
-		debugComponent: debugComponent{184, 61, 0},
+		mobModelComponent: mobModelComponent{name: "blaze"},
 	}
 	b.NetworkID = 61
 	b.bounds = vmath.NewAABB(-0.3, 0, -0.3, 0.6, 1.8, 0.6)
@@ -253,11 +294,14 @@ func newMagmaCube() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	m := &magmaCube{
-		debugComponent: debugComponent{186, 28, 28},
+		mobModelComponent: mobModelComponent{name: "magma_cube"},
 	}
 	m.NetworkID = 62
 	m.bounds = vmath.NewAABB(-0.5, 0, -0.5, 1, 1, 1)
@@ -272,11 +316,14 @@ func newEnderDragon() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	e := &enderDragon{
-		debugComponent: debugComponent{122, 59, 117},
+		mobModelComponent: mobModelComponent{name: "ender_dragon"},
 	}
 	e.NetworkID = 63
 	e.bounds = vmath.NewAABB(-8, 0, -8, 16, 8, 16)
@@ -291,11 +338,14 @@ func newWither() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	w := &wither{
-		debugComponent: debugComponent{64, 64, 64},
+		mobModelComponent: mobModelComponent{name: "wither"},
 	}
 	w.NetworkID = 64
 	w.bounds = vmath.NewAABB(-0.45, 0, -0.45, 0.9, 3.5, 0.9)
@@ -310,11 +360,14 @@ func newBat() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	b := &bat{
-		debugComponent: debugComponent{8, 8, 8},
+		mobModelComponent: mobModelComponent{name: "bat"},
 	}
 	b.NetworkID = 65
 	b.bounds = vmath.NewAABB(-0.25, 0, -0.25, 0.5, 0.9, 0.5)
@@ -329,11 +382,14 @@ func newWitch() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	w := &witch{
-		debugComponent: debugComponent{87, 64, 0},
+		mobModelComponent: mobModelComponent{name: "witch"},
 	}
 	w.NetworkID = 66
 	w.bounds = vmath.NewAABB(-0.3, 0, -0.3, 0.6, 1.8, 0.6)
@@ -348,11 +404,14 @@ func newEndermite() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	e := &endermite{
-		debugComponent: debugComponent{69, 47, 71},
+		mobModelComponent: mobModelComponent{name: "endermite"},
 	}
 	e.NetworkID = 67
 	e.bounds = vmath.NewAABB(-0.2, 0, -0.2, 0.4, 0.3, 0.4)
@@ -367,11 +426,14 @@ func newGuardian() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	g := &guardian{
-		debugComponent: debugComponent{69, 47, 71},
+		mobModelComponent: mobModelComponent{name: "guardian"},
 	}
 	g.NetworkID = 68
 	g.bounds = vmath.NewAABB(-0.425, 0, -0.425, 0.85, 0.85, 0.85)
@@ -386,14 +448,19 @@ func newPig() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
+		vehicleComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	p := &pig{
-		debugComponent: debugComponent{252, 0, 194},
+		mobModelComponent: mobModelComponent{name: "pig"},
 	}
 	p.NetworkID = 90
 	p.bounds = vmath.NewAABB(-0.45, 0, -0.45, 0.9, 0.9, 0.9)
+	p.mountOffset = 0.675
 	return p
 }
 
@@ -405,11 +472,14 @@ func newSheep() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	s := &sheep{
-		debugComponent: debugComponent{232, 232, 232},
+		mobModelComponent: mobModelComponent{name: "sheep"},
 	}
 	s.NetworkID = 91
 	s.bounds = vmath.NewAABB(-0.45, 0, -0.45, 0.9, 1.3, 0.9)
@@ -424,11 +494,14 @@ func newCow() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	c := &cow{
-		debugComponent: debugComponent{125, 52, 0},
+		mobModelComponent: mobModelComponent{name: "cow"},
 	}
 	c.NetworkID = 92
 	c.bounds = vmath.NewAABB(-0.45, 0, -0.45, 0.9, 1.3, 0.9)
@@ -443,11 +516,14 @@ func newChicken() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	c := &chicken{
-		debugComponent: debugComponent{217, 217, 217},
+		mobModelComponent: mobModelComponent{name: "chicken"},
 	}
 	c.NetworkID = 93
 	c.bounds = vmath.NewAABB(-0.2, 0, -0.2, 0.4, 0.7, 0.4)
@@ -462,11 +538,14 @@ func newSquid() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	s := &squid{
-		debugComponent: debugComponent{84, 39, 245},
+		mobModelComponent: mobModelComponent{name: "squid"},
 	}
 	s.NetworkID = 94
 	s.bounds = vmath.NewAABB(-0.475, 0, -0.475, 0.95, 0.95, 0.95)
@@ -481,11 +560,14 @@ func newWolf() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	w := &wolf{
-		debugComponent: debugComponent{148, 148, 148},
+		mobModelComponent: mobModelComponent{name: "wolf"},
 	}
 	w.NetworkID = 95
 	w.bounds = vmath.NewAABB(-0.3, 0, -0.3, 0.6, 0.8, 0.6)
@@ -500,11 +582,14 @@ func newMooshroom() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	m := &mooshroom{
-		debugComponent: debugComponent{145, 41, 0},
+		mobModelComponent: mobModelComponent{name: "mooshroom"},
 	}
 	m.NetworkID = 96
 	m.bounds = vmath.NewAABB(-0.45, 0, -0.45, 0.9, 1.3, 0.9)
@@ -519,11 +604,14 @@ func newSnowman() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	s := &snowman{
-		debugComponent: debugComponent{225, 225, 255},
+		mobModelComponent: mobModelComponent{name: "snowman"},
 	}
 	s.NetworkID = 97
 	s.bounds = vmath.NewAABB(-0.35, 0, -0.35, 0.7, 1.9, 0.7)
@@ -538,11 +626,14 @@ func newOcelot() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	o := &ocelot{
-		debugComponent: debugComponent{242, 222, 0},
+		mobModelComponent: mobModelComponent{name: "ocelot"},
 	}
 	o.NetworkID = 98
 	o.bounds = vmath.NewAABB(-0.3, 0, -0.3, 0.6, 0.8, 0.6)
@@ -557,11 +648,14 @@ func newIronGolem() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	i := &ironGolem{
-		debugComponent: debugComponent{125, 125, 125},
+		mobModelComponent: mobModelComponent{name: "iron_golem"},
 	}
 	i.NetworkID = 99
 	i.bounds = vmath.NewAABB(-0.7, 0, -0.7, 1.4, 2.9, 1.4)
@@ -576,14 +670,20 @@ func newHorse() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
+		vehicleComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	h := &horse{
-		debugComponent: debugComponent{191, 156, 0},
+		mobModelComponent: mobModelComponent{name: "horse"},
 	}
 	h.NetworkID = 100
 	h.bounds = vmath.NewAABB(-0.7, 0, -0.7, 1.4, 1.6, 1.4)
+	h.mountOffset = 1.2
+	h.chargedJump = true
 	return h
 }
 
@@ -595,11 +695,14 @@ func newRabbit() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	r := &rabbit{
-		debugComponent: debugComponent{181, 123, 42},
+		mobModelComponent: mobModelComponent{name: "rabbit"},
 	}
 	r.NetworkID = 101
 	r.bounds = vmath.NewAABB(-0.3, 0, -0.3, 0.6, 0.7, 0.6)
@@ -614,11 +717,14 @@ func newVillager() Entity {
 		targetRotationComponent
 		targetPositionComponent
 		sizeComponent
+		hurtComponent
+		effectsComponent
+		attributesComponent
 
-		debugComponent
+		mobModelComponent
 	}
 	v := &villager{
-		debugComponent: debugComponent{212, 183, 142},
+		mobModelComponent: mobModelComponent{name: "villager"},
 	}
 	v.NetworkID = 120
 	v.bounds = vmath.NewAABB(-0.3, 0, -0.3, 0.6, 1.8, 0.6)
@@ -52,6 +52,29 @@ var entityTypes = map[int]func() Entity{
 	120: newVillager,
 }
 
+// objectTypes maps the types used by the spawn object packet to
+// their entities. The data sent with the packet is passed to
+// the constructor as its meaning depends on the type.
+var objectTypes = map[int]func(data int) Entity{
+	1:  newBoat,
+	2:  newItem,
+	10: newMinecart,
+	50: newPrimedTNT,
+	60: newArrow,
+	61: newThrownItem("snowball"),
+	62: newThrownItem("egg"),
+	63: newThrownItem("fire_charge"),
+	64: newThrownItem("fire_charge"),
+	65: newThrownItem("ender_pearl"),
+	70: newFallingBlock,
+	71: newItemFrame,
+	72: newThrownItem("ender_eye"),
+	73: newThrownItem("potion_bottle_splash"),
+	75: newThrownItem("experience_bottle"),
+	76: newThrownItem("fireworks"),
+	77: newLeashKnot,
+}
+
 var globalSystems []globalSystem
 
 type globalSystem struct {
@@ -71,11 +94,29 @@ func addSystem(stage entitysys.Stage, f interface{}, matchers ...entitysys.Match
 type clientEntities struct {
 	entities  map[int]Entity
 	container *entitysys.Container
+	// detached contains entities that the server no longer knows
+	// about but are still being animated.
+	detached []detachedEntity
+
+	// vehicles maps riding entities to the entity they are
+	// riding.
+	vehicles map[int]int
+	// leashes maps leashed entities to the entity holding
+	// their leash.
+	leashes map[int]int
+}
+
+// detachedEntity is an entity that can remain in the world after
+// the server removes it until it has finished.
+type detachedEntity interface {
+	finished() bool
 }
 
 func (ce *clientEntities) init() {
 	ce.container = entitysys.NewContainer()
 	ce.entities = map[int]Entity{}
+	ce.vehicles = map[int]int{}
+	ce.leashes = map[int]int{}
 	for _, g := range globalSystems {
 		ce.container.AddSystem(g.Stage, g.F, g.Matchers...)
 	}
@@ -92,11 +133,50 @@ func (ce *clientEntities) remove(id int) {
 		return
 	}
 	delete(ce.entities, id)
+	ce.unlink(id)
 	ce.container.RemoveEntity(e)
 }
 
+// detach removes the entity from the id map, leaving it in the
+// world until it has finished.
+func (ce *clientEntities) detach(id int) {
+	e, ok := ce.entities[id]
+	if !ok {
+		return
+	}
+	de, ok := e.(detachedEntity)
+	if !ok {
+		ce.remove(id)
+		return
+	}
+	delete(ce.entities, id)
+	ce.unlink(id)
+	ce.detached = append(ce.detached, de)
+}
+
+// get returns the entity with the passed id, this includes the
+// client's own entity.
+func (ce *clientEntities) get(id int) (Entity, bool) {
+	if id == Client.entityID && Client.entity != nil {
+		return Client.entity, true
+	}
+	e, ok := ce.entities[id]
+	return e, ok
+}
+
 func (ce *clientEntities) tick() {
+	ce.updateRiders()
 	ce.container.Tick()
+	ce.drawLeashes()
+	// Removal is delayed until after ticking as the container
+	// can't have entities removed whilst it is ticking them
+	for i := 0; i < len(ce.detached); i++ {
+		if e := ce.detached[i]; e.finished() {
+			ce.container.RemoveEntity(e)
+			ce.detached = append(ce.detached[:i], ce.detached[i+1:]...)
+			i--
+		}
+	}
 }
 
 type Entity interface{}
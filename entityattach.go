@@ -0,0 +1,177 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import "github.com/thinkofdeath/steven/render"
+
+// attach links the entity to the target, either as a passenger
+// of the target or leashed to it. A target of -1 removes the
+// link.
+func (ce *clientEntities) attach(id, target int, leash bool) {
+	links := ce.vehicles
+	if leash {
+		links = ce.leashes
+	}
+	if target == -1 {
+		delete(links, id)
+	} else {
+		links[id] = target
+	}
+	if leash {
+		return
+	}
+	if e, ok := ce.get(id); ok {
+		if p, ok := e.(PlayerModelComponent); ok {
+			p.SetRiding(target != -1)
+		}
+	}
+}
+
+// unlink removes the links from and to the entity, dismounting
+// anything riding it and dropping the leashes it holds.
+func (ce *clientEntities) unlink(id int) {
+	delete(ce.vehicles, id)
+	delete(ce.leashes, id)
+	for rider, v := range ce.vehicles {
+		if v == id {
+			ce.attach(rider, -1, false)
+		}
+	}
+	for leashed, h := range ce.leashes {
+		if h == id {
+			delete(ce.leashes, leashed)
+		}
+	}
+}
+
+// vehicle returns the entity that the entity is riding if any.
+func (ce *clientEntities) vehicle(id int) (Entity, bool) {
+	v, ok := ce.vehicles[id]
+	if !ok {
+		return nil, false
+	}
+	return ce.get(v)
+}
+
+// seatPosition returns the position an entity riding the vehicle
+// should be at.
+func seatPosition(vehicle, rider Entity) (x, y, z float64, ok bool) {
+	p, ok := vehicle.(PositionComponent)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	x, y, z = p.Position()
+	if v, ok := vehicle.(VehicleComponent); ok {
+		y += v.MountOffset()
+	} else if s, ok := vehicle.(SizeComponent); ok {
+		b := s.Bounds()
+		y += float64(b.Max.Y()-b.Min.Y()) * 0.75
+	}
+	// Players sit lower to put their legs either side of
+	// the vehicle
+	if _, ok := rider.(PlayerComponent); ok {
+		y -= 0.35
+	}
+	return x, y, z, true
+}
+
+// updateRiders moves the passengers of vehicles to their seats.
+// The client's own entity is moved by the client instead.
+func (ce *clientEntities) updateRiders() {
+	for id, v := range ce.vehicles {
+		if id == Client.entityID {
+			continue
+		}
+		rider, ok := ce.entities[id]
+		if !ok {
+			continue
+		}
+		vehicle, ok := ce.get(v)
+		if !ok {
+			continue
+		}
+		x, y, z, ok := seatPosition(vehicle, rider)
+		if !ok {
+			continue
+		}
+		if p, ok := rider.(PositionComponent); ok {
+			p.SetPosition(x, y, z)
+		}
+		if p, ok := rider.(TargetPositionComponent); ok {
+			p.SetTargetPosition(x, y, z)
+		}
+	}
+}
+
+// leashPoint returns the point on the entity that a leash is
+// tied to.
+func leashPoint(e Entity) (x, y, z float64, ok bool) {
+	p, ok := e.(PositionComponent)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	x, y, z = p.Position()
+	if _, ok := e.(PlayerComponent); ok {
+		// Held in the player's hand
+		return x, y + 1.2, z, true
+	}
+	if s, ok := e.(SizeComponent); ok {
+		b := s.Bounds()
+		y += float64(b.Min.Y()) + float64(b.Max.Y()-b.Min.Y())*0.6
+	}
+	return x, y, z, true
+}
+
+// leashSegments is the number of pieces a leash is drawn with.
+const leashSegments = 24
+
+// drawLeashes draws a rope between each leashed entity and the
+// entity holding it. Like vanilla the rope curves towards the
+// leashed entity and alternates between two shades of brown.
+func (ce *clientEntities) drawLeashes() {
+	for id, h := range ce.leashes {
+		e, ok := ce.get(id)
+		if !ok {
+			continue
+		}
+		holder, ok := ce.get(h)
+		if !ok {
+			continue
+		}
+		sx, sy, sz, ok := leashPoint(e)
+		if !ok {
+			continue
+		}
+		ex, ey, ez, ok := leashPoint(holder)
+		if !ok {
+			continue
+		}
+		dx, dy, dz := ex-sx, ey-sy, ez-sz
+		point := func(i int) (x, y, z float64) {
+			t := float64(i) / leashSegments
+			return sx + dx*t, sy + dy*(t*t+t)*0.5, sz + dz*t
+		}
+		lx, ly, lz := point(0)
+		for i := 1; i <= leashSegments; i++ {
+			x, y, z := point(i)
+			var r, g, b byte = 127, 102, 76
+			if i%2 == 0 {
+				r, g, b = 89, 71, 53
+			}
+			render.DrawLine(lx, ly, lz, x, y, z, 0.05, r, g, b, 255)
+			lx, ly, lz = x, y, z
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/thinkofdeath/steven/protocol"
+	"github.com/thinkofdeath/steven/render"
+)
+
+// effectsComponent tracks the status effects on other entities.
+// Only invisibility changes how they are shown.
+type effectsComponent struct {
+	effects map[int]int
+	// invisible is the invisible flag from the entity's
+	// metadata
+	invisible bool
+}
+
+func (e *effectsComponent) AddEffect(id, amplifier int) {
+	if e.effects == nil {
+		e.effects = map[int]int{}
+	}
+	e.effects[id] = amplifier
+}
+
+func (e *effectsComponent) RemoveEffect(id int) {
+	delete(e.effects, id)
+}
+
+func (e *effectsComponent) SetInvisible(invisible bool) {
+	e.invisible = invisible
+}
+
+func (e *effectsComponent) Invisible() bool {
+	_, ok := e.effects[effectInvisibility]
+	return e.invisible || ok
+}
+
+type EffectsComponent interface {
+	AddEffect(id, amplifier int)
+	RemoveEffect(id int)
+	SetInvisible(invisible bool)
+	Invisible() bool
+}
+
+// metadataInvisible returns whether the entity flags in the
+// metadata mark the entity as invisible.
+func metadataInvisible(m protocol.Metadata) (invisible, ok bool) {
+	flags, ok := m[0].(int8)
+	return flags&0x20 != 0, ok
+}
+
+// esInvisibleTick hides the models of invisible entities. Like
+// vanilla the armor and items they hold are still shown so this
+// must run after they have been positioned.
+func esInvisibleTick(e *effectsComponent, m interface {
+	Model() *render.StaticModel
+}) {
+	model := m.Model()
+	if model == nil || !e.Invisible() {
+		return
+	}
+	hide := mgl32.Scale3D(0, 0, 0)
+	for i := range model.Matrix {
+		model.Matrix[i] = hide
+	}
+}
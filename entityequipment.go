@@ -0,0 +1,256 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"math"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/thinkofdeath/steven/render"
+)
+
+// Equipment slots as used by the entity equipment packet.
+const (
+	equipmentHeld = iota
+	equipmentBoots
+	equipmentLeggings
+	equipmentChestplate
+	equipmentHelmet
+)
+
+// armorSlots maps the equipment slots to the name of the armor
+// piece (and texture variable in the armor model) they hold.
+var armorSlots = [...]string{
+	equipmentBoots:      "boots",
+	equipmentLeggings:   "leggings",
+	equipmentChestplate: "chestplate",
+	equipmentHelmet:     "helmet",
+}
+
+// boneModel is implemented by entity models that armor and
+// held items can be attached to.
+type boneModel interface {
+	Model() *render.StaticModel
+	// bone returns the current matrix of the named bone and its
+	// pivot in model coordinates.
+	bone(name string) (mgl32.Mat4, [3]float64, bool)
+}
+
+type equipmentComponent struct {
+	items [5]*ItemStack
+	dirty bool
+	// skipHeld is set for entities that draw their held item
+	// themselves.
+	skipHeld bool
+
+	armor      *render.StaticModel
+	armorBones []int
+	held       *render.StaticModel
+	heldMat    mgl32.Mat4
+}
+
+func (e *equipmentComponent) SetEquipment(slot int, item *ItemStack) {
+	if slot < 0 || slot >= len(e.items) || e.items[slot] == item {
+		return
+	}
+	e.items[slot] = item
+	e.dirty = true
+}
+
+func (e *equipmentComponent) Equipment(slot int) *ItemStack {
+	if slot < 0 || slot >= len(e.items) {
+		return nil
+	}
+	return e.items[slot]
+}
+
+type EquipmentComponent interface {
+	SetEquipment(slot int, item *ItemStack)
+	Equipment(slot int) *ItemStack
+}
+
+// armorMaterial returns the material of the armor piece for the
+// slot, e.g. iron for an iron_helmet.
+func armorMaterial(item *ItemStack, slot int) (string, bool) {
+	if item == nil {
+		return "", false
+	}
+	name := item.Type.Name()
+	if !strings.HasSuffix(name, "_"+armorSlots[slot]) {
+		return "", false
+	}
+	mat := strings.TrimSuffix(name, "_"+armorSlots[slot])
+	if mat == "golden" {
+		mat = "gold"
+	}
+	return mat, true
+}
+
+// armorPoints is the amount of protection given by each piece
+// of armor, keyed by material.
+var armorPoints = map[string][5]int{
+	"leather":   {equipmentBoots: 1, equipmentLeggings: 2, equipmentChestplate: 3, equipmentHelmet: 1},
+	"chainmail": {equipmentBoots: 1, equipmentLeggings: 4, equipmentChestplate: 5, equipmentHelmet: 2},
+	"iron":      {equipmentBoots: 2, equipmentLeggings: 5, equipmentChestplate: 6, equipmentHelmet: 2},
+	"gold":      {equipmentBoots: 1, equipmentLeggings: 3, equipmentChestplate: 5, equipmentHelmet: 2},
+	"diamond":   {equipmentBoots: 3, equipmentLeggings: 6, equipmentChestplate: 8, equipmentHelmet: 3},
+}
+
+func (e *equipmentComponent) free() {
+	if e.armor != nil {
+		e.armor.Free()
+		e.armor = nil
+	}
+	if e.held != nil {
+		e.held.Free()
+		e.held = nil
+	}
+	e.armorBones = e.armorBones[:0]
+}
+
+func (e *equipmentComponent) rebuild() {
+	e.free()
+	e.dirty = false
+
+	if def := getEntityModel("armor"); def != nil {
+		e.rebuildArmor(def)
+	}
+
+	item := e.items[equipmentHeld]
+	if e.skipHeld || item == nil {
+		return
+	}
+	out, mat, ok := itemStaticModel(item, "thirdperson")
+	if !ok {
+		return
+	}
+	e.heldMat = mat
+	e.held = render.NewStaticModel([][]*render.StaticVertex{out})
+	e.held.Radius = 3
+	e.held.Glint[0] = item.enchanted()
+}
+
+// rebuildArmor creates the model for the worn armor. Each piece
+// of armor gets its own part per a bone so that the glint can be
+// applied to just the enchanted pieces.
+func (e *equipmentComponent) rebuildArmor(def *entityModel) {
+	var parts [][]*render.StaticVertex
+	var glint []bool
+	for i, b := range def.Bones {
+		for slot := equipmentBoots; slot <= equipmentHelmet; slot++ {
+			item := e.items[slot]
+			mat, ok := armorMaterial(item, slot)
+			if !ok {
+				continue
+			}
+			layer := "1"
+			if slot == equipmentLeggings {
+				layer = "2"
+			}
+			tex := render.RelativeTexture(render.GetTexture("models/armor/"+mat+"_layer_"+layer), 64, 32)
+
+			// Leather is dyed with a default brown unless the
+			// item has its own color. The overlay holds the
+			// undyed details.
+			var overlay render.TextureInfo
+			var r, g, bl byte = 255, 255, 255
+			if mat == "leather" {
+				overlay = render.RelativeTexture(render.GetTexture("models/armor/leather_layer_"+layer+"_overlay"), 64, 32)
+				var dyed bool
+				if r, g, bl, dyed = item.dyeColor(); !dyed {
+					r, g, bl = 0xA0, 0x65, 0x40
+				}
+			}
+
+			var verts []*render.StaticVertex
+			for _, box := range b.Boxes {
+				if def.Textures[box.Texture].Name != "#"+armorSlots[slot] {
+					continue
+				}
+				start := len(verts)
+				verts = box.appendTo(verts, tex)
+				for _, v := range verts[start:] {
+					v.R = byte(int(v.R) * int(r) / 255)
+					v.G = byte(int(v.G) * int(g) / 255)
+					v.B = byte(int(v.B) * int(bl) / 255)
+				}
+				if overlay != nil {
+					// Grown slightly to stop it fighting with
+					// the dyed layer
+					box.Inflate += 0.01
+					verts = box.appendTo(verts, overlay)
+				}
+			}
+			if len(verts) == 0 {
+				continue
+			}
+			parts = append(parts, verts)
+			glint = append(glint, item.enchanted())
+			e.armorBones = append(e.armorBones, i)
+		}
+	}
+	if len(parts) == 0 {
+		return
+	}
+	e.armor = render.NewStaticModel(parts)
+	e.armor.Radius = 3
+	copy(e.armor.Glint, glint)
+}
+
+func esEquipmentTick(e *equipmentComponent, b boneModel) {
+	if e.dirty {
+		e.rebuild()
+	}
+	model := b.Model()
+	if model == nil {
+		return
+	}
+	def := getEntityModel("armor")
+	if e.armor != nil && def != nil {
+		e.armor.X, e.armor.Y, e.armor.Z = model.X, model.Y, model.Z
+		e.armor.BlockLight, e.armor.SkyLight = model.BlockLight, model.SkyLight
+		for i, bone := range e.armorBones {
+			ab := def.Bones[bone]
+			mat, pivot, ok := b.bone(ab.Name)
+			if !ok {
+				// Hide armor for bones the entity doesn't have
+				e.armor.Matrix[i] = mgl32.Scale3D(0, 0, 0)
+				continue
+			}
+			e.armor.Matrix[i] = mat.Mul4(entityTransform([3]float64{
+				ab.Pivot[0] - pivot[0],
+				ab.Pivot[1] - pivot[1],
+				ab.Pivot[2] - pivot[2],
+			}, [3]float64{}))
+		}
+	}
+	if e.held != nil {
+		e.held.X, e.held.Y, e.held.Z = model.X, model.Y, model.Z
+		e.held.BlockLight, e.held.SkyLight = model.BlockLight, model.SkyLight
+		mat, _, ok := b.bone("arm_right")
+		if !ok {
+			e.held.Matrix[0] = mgl32.Scale3D(0, 0, 0)
+			return
+		}
+		e.held.Matrix[0] = mat.Mul4(mgl32.Translate3D(1/16.0, 9/16.0, -5/16.0)).
+			Mul4(mgl32.Rotate3DX(math.Pi).Mat4()).
+			Mul4(e.heldMat)
+	}
+}
+
+func esEquipmentRemove(e *equipmentComponent) {
+	e.free()
+}
@@ -0,0 +1,310 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/thinkofdeath/steven/entitysys"
+	"github.com/thinkofdeath/steven/protocol"
+	"github.com/thinkofdeath/steven/render"
+	"github.com/thinkofdeath/steven/type/direction"
+	"github.com/thinkofdeath/steven/type/vmath"
+)
+
+func init() {
+	addSystem(entitysys.Add, esPaintingAdd)
+	addSystem(entitysys.Add, esItemFrameAdd)
+	addSystem(entitysys.Add, esLeashKnotAdd)
+}
+
+// hangingFacing is the direction a painting or item frame faces
+// using vanilla's horizontal index (south, west, north, east).
+type hangingFacing int
+
+// offset returns the direction the entity faces as a vector.
+func (h hangingFacing) offset() (x, z float64) {
+	switch h & 3 {
+	case 0:
+		return 0, 1
+	case 1:
+		return -1, 0
+	case 2:
+		return 0, -1
+	default:
+		return 1, 0
+	}
+}
+
+// yaw returns the rotation of the entity matching the yaw
+// used by other entities.
+func (h hangingFacing) yaw() float64 {
+	return float64(h&3) * (math.Pi / 2)
+}
+
+// matrix returns the matrix for a model centered on the
+// passed position and facing the entity's direction. The
+// front of the model faces north.
+func (h hangingFacing) matrix(x, y, z float64) mgl32.Mat4 {
+	return mgl32.Translate3D(float32(x), -float32(y), float32(z)).
+		Mul4(mgl32.Rotate3DY(math.Pi - float32(h.yaw())).Mat4())
+}
+
+// Paintings
+
+type paintingArt struct {
+	X, Y, Width, Height int
+}
+
+// paintingArts is the location of each painting in the
+// painting texture.
+var paintingArts = map[string]paintingArt{
+	"Kebab":         {0, 0, 16, 16},
+	"Aztec":         {16, 0, 16, 16},
+	"Alban":         {32, 0, 16, 16},
+	"Aztec2":        {48, 0, 16, 16},
+	"Bomb":          {64, 0, 16, 16},
+	"Plant":         {80, 0, 16, 16},
+	"Wasteland":     {96, 0, 16, 16},
+	"Pool":          {0, 32, 32, 16},
+	"Courbet":       {32, 32, 32, 16},
+	"Sea":           {64, 32, 32, 16},
+	"Sunset":        {96, 32, 32, 16},
+	"Creebet":       {128, 32, 32, 16},
+	"Wanderer":      {0, 64, 16, 32},
+	"Graham":        {16, 64, 16, 32},
+	"Match":         {0, 128, 32, 32},
+	"Bust":          {32, 128, 32, 32},
+	"Stage":         {64, 128, 32, 32},
+	"Void":          {96, 128, 32, 32},
+	"SkullAndRoses": {128, 128, 32, 32},
+	"WitherSkull":   {160, 128, 32, 32},
+	"Fighters":      {0, 96, 64, 32},
+	"Pointer":       {0, 192, 64, 64},
+	"Pigscene":      {64, 192, 64, 64},
+	"BurningSkull":  {128, 192, 64, 64},
+	"Skeleton":      {192, 64, 64, 48},
+	"DonkeyKong":    {192, 112, 64, 48},
+}
+
+func newPainting(title string, x, y, z int, facing hangingFacing) Entity {
+	type painting struct {
+		networkComponent
+		positionComponent
+		sizeComponent
+
+		paintingComponent
+	}
+	art, ok := paintingArts[title]
+	if !ok {
+		art = paintingArts["Kebab"]
+	}
+	p := &painting{
+		paintingComponent: paintingComponent{art: art, facing: facing},
+	}
+	w, h := float64(art.Width)/16, float64(art.Height)/16
+	p.bounds = vmath.NewAABB(
+		-float32(w/2), -float32(h/2), -float32(w/2),
+		float32(w), float32(h), float32(w),
+	)
+
+	// Vanilla positions the painting against the wall behind
+	// the block and shifts paintings with an even number of
+	// blocks along the wall so that they are centered.
+	shift := func(size int) float64 {
+		if size%32 == 0 {
+			return 0.5
+		}
+		return 0
+	}
+	fx, fz := facing.offset()
+	lx, lz := (facing + 3).offset()
+	p.X = float64(x) + 0.5 - fx*0.46875 + lx*shift(art.Width)
+	p.Y = float64(y) + 0.5 + shift(art.Height)
+	p.Z = float64(z) + 0.5 - fz*0.46875 + lz*shift(art.Width)
+	return p
+}
+
+type paintingComponent struct {
+	model  *render.StaticModel
+	art    paintingArt
+	facing hangingFacing
+}
+
+func (p *paintingComponent) Model() *render.StaticModel { return p.model }
+
+func esPaintingAdd(p *paintingComponent, pos PositionComponent) {
+	tex := render.RelativeTexture(render.GetTexture("painting/paintings_kristoffer_zetterstrand"), 256, 256)
+	var textures [6]render.TextureInfo
+	textures[direction.North] = tex.Sub(p.art.X, p.art.Y, p.art.Width, p.art.Height)
+	textures[direction.South] = tex.Sub(192, 0, 16, 16)
+	textures[direction.Up] = tex.Sub(192, 0, 16, 1)
+	textures[direction.Down] = tex.Sub(192, 0, 16, 1)
+	textures[direction.West] = tex.Sub(192, 0, 1, 16)
+	textures[direction.East] = tex.Sub(192, 0, 1, 16)
+
+	w, h := float32(p.art.Width)/16, float32(p.art.Height)/16
+	p.model = render.NewStaticModel([][]*render.StaticVertex{
+		appendBox(nil, -w/2, -h/2, -1/32.0, w, h, 1/16.0, textures),
+	})
+	p.model.Radius = float32(math.Max(float64(w), float64(h)))
+
+	x, y, z := pos.Position()
+	p.model.X, p.model.Y, p.model.Z = -float32(x), -float32(y), float32(z)
+	p.model.Matrix[0] = p.facing.matrix(x, y, z)
+}
+
+// Item frames
+
+// newItemFrame returns an item frame, data is the direction
+// it faces.
+func newItemFrame(data int) Entity {
+	type itemFrame struct {
+		networkComponent
+		positionComponent
+		sizeComponent
+
+		itemFrameComponent
+	}
+	i := &itemFrame{
+		itemFrameComponent: itemFrameComponent{facing: hangingFacing(data)},
+	}
+	i.NetworkID = 71
+	i.bounds = vmath.NewAABB(-0.5, -0.5, -0.5, 1, 1, 1)
+	return i
+}
+
+type itemFrameComponent struct {
+	model    *render.StaticModel
+	facing   hangingFacing
+	item     *ItemStack
+	rotation int
+
+	added   bool
+	x, y, z float64
+}
+
+func (i *itemFrameComponent) Model() *render.StaticModel { return i.model }
+
+// SetMetadata updates the item and rotation of the frame.
+func (i *itemFrameComponent) SetMetadata(m protocol.Metadata) {
+	changed := false
+	if it, ok := m[8].(protocol.ItemStack); ok {
+		i.item = ItemStackFromProtocol(it)
+		changed = true
+	}
+	if r, ok := m[9].(int8); ok {
+		i.rotation = int(r)
+		changed = true
+	}
+	if changed && i.added {
+		i.rebuild()
+	}
+}
+
+func esItemFrameAdd(i *itemFrameComponent, pos PositionComponent) {
+	// The position sent is the corner of the block the frame
+	// is in
+	x, y, z := pos.Position()
+	i.x, i.y, i.z = x+0.5, y+0.5, z+0.5
+	pos.SetPosition(i.x, i.y, i.z)
+	i.added = true
+	i.rebuild()
+}
+
+func (i *itemFrameComponent) rebuild() {
+	if i.model != nil {
+		i.model.Free()
+		i.model = nil
+	}
+	var im ItemMap
+	if i.item != nil {
+		im, _ = i.item.Type.(ItemMap)
+	}
+	variant := "normal"
+	if im != nil {
+		variant = "map"
+	}
+
+	var frame []*render.StaticVertex
+	if bs := findStateModel("minecraft", "item_frame"); bs != nil {
+		if v := bs.variant(variant); len(v) > 0 {
+			frame = staticModelFromProcessed(v[0], nil)
+		}
+	}
+
+	var out []*render.StaticVertex
+	mat := mgl32.Ident4()
+	rot := float32(i.rotation) * (math.Pi / 4)
+	if im != nil {
+		out = createMapModel(im.MapID())
+		rot = float32(i.rotation%4) * (math.Pi / 2)
+	} else if i.item != nil {
+		out, mat, _ = itemStaticModel(i.item, "ground")
+	}
+
+	i.model = render.NewStaticModel([][]*render.StaticVertex{
+		frame,
+		out,
+	})
+	i.model.Radius = 1.5
+
+	x, y, z := i.x, i.y, i.z
+	i.model.X, i.model.Y, i.model.Z = -float32(x), -float32(y), float32(z)
+	base := i.facing.matrix(x, y, z)
+	i.model.Matrix[0] = base
+	i.model.Matrix[1] = base.Mul4(mgl32.Translate3D(0, 0, 7/16.0)).
+		Mul4(mgl32.Rotate3DZ(-rot).Mat4()).
+		Mul4(mat)
+}
+
+// Leash knots
+
+func newLeashKnot(data int) Entity {
+	type leashKnot struct {
+		networkComponent
+		positionComponent
+		sizeComponent
+
+		leashKnotComponent
+	}
+	l := &leashKnot{}
+	l.NetworkID = 77
+	l.bounds = vmath.NewAABB(-0.1875, -0.25, -0.1875, 0.375, 0.5, 0.375)
+	return l
+}
+
+type leashKnotComponent struct {
+	model *render.StaticModel
+}
+
+func (l *leashKnotComponent) Model() *render.StaticModel { return l.model }
+
+func esLeashKnotAdd(l *leashKnotComponent, pos PositionComponent) {
+	// Like item frames the position sent is the corner of the
+	// fence the knot is tied to
+	x, y, z := pos.Position()
+	x, y, z = x+0.5, y+0.5, z+0.5
+	pos.SetPosition(x, y, z)
+
+	tex := render.RelativeTexture(render.GetTexture("entity/lead_knot"), 32, 32)
+	l.model = render.NewStaticModel([][]*render.StaticVertex{
+		newEntityBox(0, 0, -3, -6, -3, 6, 8, 6).appendTo(nil, tex),
+	})
+	l.model.Radius = 0.5
+	l.model.X, l.model.Y, l.model.Z = -float32(x), -float32(y), float32(z)
+	l.model.Matrix[0] = mgl32.Translate3D(float32(x), -float32(y), float32(z))
+}
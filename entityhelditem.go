@@ -26,8 +26,36 @@ import (
 	"github.com/thinkofdeath/steven/world/biome"
 )
 
+// itemStaticModel returns the vertices and matrix needed to draw
+// the item as a static model in the passed display mode.
+func itemStaticModel(item *ItemStack, mode string) (out []*render.StaticVertex, mat mgl32.Mat4, ok bool) {
+	mdl := getModel(item.Type.Name())
+	if mdl == nil {
+		return nil, mat, false
+	}
+
+	var blk Block
+	if bt, ok := item.Type.(*blockItem); ok {
+		blk = bt.block
+	}
+
+	if im, ok := item.Type.(ItemMap); ok {
+		out, mat = createMapModel(im.MapID()), heldItemMatrix(mdl, mode)
+	} else if mdl.builtIn == builtInGenerated {
+		out, mat = genStaticModelFromItem(mdl, blk, mode)
+	} else if mdl.builtIn == builtInFalse {
+		out, mat = staticModelFromItem(mdl, blk, mode)
+	}
+	return out, mat, true
+}
+
 func staticModelFromItem(mdl *model, block Block, mode string) (out []*render.StaticVertex, mat mgl32.Mat4) {
 	mat = mgl32.Ident4()
+	if _, ok := mdl.display[mode]; !ok && mode == "ground" {
+		// The models don't say how they should look when dropped
+		// so use the size vanilla draws blocks at.
+		mat = mgl32.Scale3D(0.25, 0.25, 0.25)
+	}
 	if gui, ok := mdl.display[mode]; ok {
 		if gui.Scale != nil {
 			mat = mat.Mul4(mgl32.Scale3D(
@@ -50,7 +78,12 @@ func staticModelFromItem(mdl *model, block Block, mode string) (out []*render.St
 		}
 	}
 
-	p := precomputeModel(mdl)
+	return staticModelFromProcessed(precomputeModel(mdl), block), mat
+}
+
+// staticModelFromProcessed converts the faces of a block model
+// into vertices for a static model centered on the origin.
+func staticModelFromProcessed(p *processedModel, block Block) (out []*render.StaticVertex) {
 	for fi := range p.faces {
 		f := p.faces[len(p.faces)-1-fi]
 		var cr, cg, cb byte
@@ -102,12 +135,18 @@ func staticModelFromItem(mdl *model, block Block, mode string) (out []*render.St
 	return
 }
 
-func genStaticModelFromItem(mdl *model, block Block, mode string) (out []*render.StaticVertex, mat mgl32.Mat4) {
-	if mode == "thirdperson" {
+// heldItemMatrix returns the matrix used to position a generated
+// item model in the hand.
+func heldItemMatrix(mdl *model, mode string) (mat mgl32.Mat4) {
+	switch mode {
+	case "thirdperson":
 		mat = mgl32.Translate3D(0, 0, 2/16.0).
 			Mul4(mgl32.Rotate3DY(math.Pi).Mat4()).
 			Mul4(mgl32.Rotate3DZ(math.Pi).Mat4())
-	} else {
+	case "ground":
+		mat = mgl32.Rotate3DZ(math.Pi).Mat4().
+			Mul4(mgl32.Scale3D(0.5, 0.5, 0.5))
+	default:
 		mat = mgl32.Translate3D(0, -8/16.0, 0).
 			Mul4(mgl32.Rotate3DX(math.Pi).Mat4()).
 			Mul4(mgl32.Rotate3DY(math.Pi).Mat4()).
@@ -135,6 +174,11 @@ func genStaticModelFromItem(mdl *model, block Block, mode string) (out []*render
 			mat = mat.Mul4(mgl32.Rotate3DZ(float32(gui.Rotation[2]/180) * math.Pi).Mat4())
 		}
 	}
+	return mat
+}
+
+func genStaticModelFromItem(mdl *model, block Block, mode string) (out []*render.StaticVertex, mat mgl32.Mat4) {
+	mat = heldItemMatrix(mdl, mode)
 
 	tex := render.GetTexture("solid")
 	rect := tex.Rect()
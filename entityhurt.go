@@ -0,0 +1,151 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/thinkofdeath/steven/render"
+)
+
+// hurtDuration is the number of ticks an entity is shown as hurt
+// for after being damaged.
+const hurtDuration = 10
+
+// hurtComponent tracks the health along with the hurt and death
+// animations of living entities. Times are in game ticks.
+type hurtComponent struct {
+	hurtTime  float64
+	dead      bool
+	deathTime float64
+
+	health    float64
+	hasHealth bool
+}
+
+func (h *hurtComponent) Hurt() {
+	h.hurtTime = hurtDuration
+}
+
+func (h *hurtComponent) Die() {
+	h.dead = true
+	h.hurtTime = hurtDuration
+}
+
+// revive resets the entity after it died, used when the client
+// respawns.
+func (h *hurtComponent) revive() {
+	h.dead = false
+	h.deathTime = 0
+	h.hurtTime = 0
+}
+
+func (h *hurtComponent) SetHealth(health float64) {
+	h.health, h.hasHealth = health, true
+}
+
+// Health returns the entity's health, ok is false if the server
+// hasn't sent it yet.
+func (h *hurtComponent) Health() (health float64, ok bool) {
+	return h.health, h.hasHealth
+}
+
+type HurtComponent interface {
+	Hurt()
+	Die()
+	SetHealth(health float64)
+	Health() (float64, bool)
+}
+
+// esHurtTick tints hurt entities red and tips dying entities on
+// to their side. This must run after the entity's model has been
+// positioned for the tick.
+func esHurtTick(h *hurtComponent, pos PositionComponent, r RotationComponent, m interface {
+	Model() *render.StaticModel
+}) {
+	dt := Client.delta / 3
+	if h.hurtTime > 0 {
+		h.hurtTime = math.Max(0, h.hurtTime-dt)
+	}
+	if h.dead {
+		h.deathTime += dt
+	}
+	model := m.Model()
+	if model == nil {
+		return
+	}
+
+	col := [4]float32{1.0, 1.0, 1.0, 1.0}
+	if h.hurtTime > 0 || h.dead {
+		col = [4]float32{1.0, 0.5, 0.5, 1.0}
+	}
+	for i := range model.Colors {
+		model.Colors[i] = col
+	}
+	if !h.dead {
+		return
+	}
+
+	models := []*render.StaticModel{model}
+	if p, ok := m.(interface {
+		HeldModel() *render.StaticModel
+	}); ok && p.HeldModel() != nil {
+		models = append(models, p.HeldModel())
+	}
+
+	// Same as vanilla, falls quickly at first and then slows
+	// down as it hits the ground
+	f := math.Min(1, math.Sqrt(h.deathTime/20*1.6))
+	x, y, z := pos.Position()
+	base := mgl32.Translate3D(float32(x), -float32(y), float32(z)).
+		Mul4(mgl32.Rotate3DY(math.Pi - float32(r.Yaw())).Mat4())
+	rot := base.Mul4(mgl32.Rotate3DZ(float32(f * math.Pi / 2)).Mat4()).
+		Mul4(base.Inv())
+	for _, mdl := range models {
+		for i := range mdl.Matrix {
+			mdl.Matrix[i] = rot.Mul4(mdl.Matrix[i])
+		}
+	}
+}
+
+// hurtRoll returns the amount the camera should be tilted by
+// whilst the player is hurt.
+func (h *hurtComponent) hurtRoll() float64 {
+	if h.hurtTime <= 0 || h.dead {
+		return 0
+	}
+	f := h.hurtTime / hurtDuration
+	return -math.Sin(f*f*f*f*math.Pi) * (14 * math.Pi / 180)
+}
+
+// entityStatusParticle is the particles spawned around an entity
+// for an entity status.
+type entityStatusParticle struct {
+	id, count int
+}
+
+// entityStatusParticles maps the entity status codes that only
+// spawn particles to the particles they spawn.
+var entityStatusParticles = map[byte]entityStatusParticle{
+	6:  {11, 7},  // Taming failed
+	7:  {34, 7},  // Tamed
+	12: {34, 5},  // Villager mating
+	13: {20, 5},  // Angry villager
+	14: {21, 5},  // Happy villager
+	15: {17, 16}, // Witch magic
+	18: {34, 7},  // In love
+	20: {0, 20},  // Spawner poof
+}
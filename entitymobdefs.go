@@ -0,0 +1,983 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"math"
+	"strconv"
+)
+
+// entityModels contains the models for each mob and object. The values are
+// taken from vanilla's models, a few parts have been merged
+// to stay within the bone limit.
+var entityModels = map[string]*entityModel{
+	"player": playerEntityModel(),
+	"creeper": {
+		Textures: []entityTexture{{"entity/creeper/creeper", 64, 32}},
+		Bones: []entityBone{
+			newEntityBone("head", 0, 6, 0, newEntityBox(0, 0, -4, -8, -4, 8, 8, 8)).animated(animLook()),
+			newEntityBone("body", 0, 6, 0, newEntityBox(16, 16, -4, 0, -2, 8, 12, 4)),
+			newEntityBone("leg_back_right", -2, 18, 4, newEntityBox(0, 16, -2, 0, -2, 4, 6, 4)).animated(animWalk(1)),
+			newEntityBone("leg_back_left", 2, 18, 4, newEntityBox(0, 16, -2, 0, -2, 4, 6, 4)).animated(animWalk(-1)),
+			newEntityBone("leg_front_right", -2, 18, -4, newEntityBox(0, 16, -2, 0, -2, 4, 6, 4)).animated(animWalk(-1)),
+			newEntityBone("leg_front_left", 2, 18, -4, newEntityBox(0, 16, -2, 0, -2, 4, 6, 4)).animated(animWalk(1)),
+		},
+	},
+	"skeleton":      skeletonModel(),
+	"spider":        spiderModel("entity/spider/spider", 1),
+	"cave_spider":   spiderModel("entity/spider/cave_spider", 0.7),
+	"zombie":        bipedModel("entity/zombie/zombie", 64, 64, -math.Pi/2, 0),
+	"zombie_pigman": bipedModel("entity/zombie_pigman", 64, 64, -math.Pi/2, 0),
+	"enderman":      endermanModel(),
+	// The outer layer of slimes is translucent which static models
+	// can't draw so only the inner part is shown.
+	"slime": {
+		Textures: []entityTexture{{"entity/slime/slime", 64, 32}},
+		Scale:    2,
+		Bones: []entityBone{
+			newEntityBone("body", 0, 0, 0,
+				newEntityBox(0, 16, -3, 17, -3, 6, 6, 6),
+				newEntityBox(32, 0, -3.25, 18, -3.5, 2, 2, 2),
+				newEntityBox(32, 4, 1.25, 18, -3.5, 2, 2, 2),
+				newEntityBox(32, 8, 0, 21, -3.5, 1, 1, 1),
+			),
+		},
+	},
+	"magma_cube":   magmaCubeModel(),
+	"ghast":        ghastModel(),
+	"blaze":        blazeModel(),
+	"silverfish":   silverfishModel(),
+	"endermite":    endermiteModel(),
+	"bat":          batModel(),
+	"witch":        witchModel(),
+	"villager":     villagerModel("entity/villager/villager", 64, 64),
+	"guardian":     guardianModel(),
+	"wither":       witherModel(),
+	"ender_dragon": dragonModel(),
+	"pig":          pigModel(),
+	"cow":          cowModel("entity/cow/cow"),
+	"mooshroom":    cowModel("entity/cow/mooshroom"),
+	"sheep":        sheepModel(),
+	"chicken": {
+		Textures: []entityTexture{{"entity/chicken", 64, 32}},
+		Bones: []entityBone{
+			newEntityBone("head", 0, 15, -4,
+				newEntityBox(0, 0, -2, -6, -2, 4, 6, 3),
+				newEntityBox(14, 0, -2, -4, -4, 4, 2, 2),
+				newEntityBox(14, 4, -1, -2, -3, 2, 2, 2),
+			).animated(animLook()),
+			newEntityBone("body", 0, 16, 0, newEntityBox(0, 9, -3, -4, -3, 6, 8, 6)).rotated(math.Pi/2, 0, 0),
+			newEntityBone("leg_right", -2, 19, 1, newEntityBox(26, 0, -1, 0, -3, 3, 5, 3)).animated(animWalk(1)),
+			newEntityBone("leg_left", 1, 19, 1, newEntityBox(26, 0, -1, 0, -3, 3, 5, 3)).animated(animWalk(-1)),
+			newEntityBone("wing_right", -4, 13, 0, newEntityBox(24, 13, 0, 0, -3, 1, 4, 6)).animated(animWave(entityAxisZ, 0.1, 0.1, 0)),
+			newEntityBone("wing_left", 4, 13, 0, newEntityBox(24, 13, -1, 0, -3, 1, 4, 6)).animated(animWave(entityAxisZ, -0.1, 0.1, 0)),
+		},
+	},
+	"squid":  squidModel(),
+	"wolf":   wolfModel(),
+	"ocelot": ocelotModel(),
+	"rabbit": rabbitModel(),
+	"snowman": {
+		Textures: []entityTexture{{"entity/snowman", 64, 64}},
+		Bones: []entityBone{
+			newEntityBone("head", 0, 4, 0, newEntityBox(0, 0, -4, -8, -4, 8, 8, 8).grow(-0.5)).animated(animLook()),
+			newEntityBone("body", 0, 13, 0, newEntityBox(0, 16, -5, -10, -5, 10, 10, 10).grow(-0.5)),
+			newEntityBone("body_bottom", 0, 24, 0, newEntityBox(0, 36, -6, -12, -6, 12, 12, 12).grow(-0.5)),
+			newEntityBone("arm_right", 5, 6, 0, newEntityBox(32, 0, -1, 0, -1, 12, 2, 2).grow(-0.5)).rotated(0, 0, -1),
+			newEntityBone("arm_left", -5, 6, 0, newEntityBox(32, 0, -11, 0, -1, 12, 2, 2).grow(-0.5)).rotated(0, 0, 1),
+		},
+	},
+	"iron_golem": {
+		Textures: []entityTexture{{"entity/iron_golem", 128, 128}},
+		Bones: []entityBone{
+			newEntityBone("head", 0, -7, -2,
+				newEntityBox(0, 0, -4, -12, -5.5, 8, 10, 8),
+				newEntityBox(24, 0, -1, -5, -7.5, 2, 4, 2),
+			).animated(animLook()),
+			newEntityBone("body", 0, -7, 0,
+				newEntityBox(0, 40, -9, -2, -6, 18, 12, 11),
+				newEntityBox(0, 70, -4.5, 10, -3, 9, 5, 6).grow(0.5),
+			),
+			newEntityBone("arm_right", 0, -7, 0, newEntityBox(60, 21, -13, -2.5, -3, 4, 30, 6)).animated(animWalk(-0.75)),
+			newEntityBone("arm_left", 0, -7, 0, newEntityBox(60, 58, 9, -2.5, -3, 4, 30, 6)).animated(animWalk(0.75)),
+			newEntityBone("leg_right", -4, 11, 0, newEntityBox(37, 0, -3.5, -3, -3, 6, 16, 5)).animated(animWalk(1)),
+			newEntityBone("leg_left", 5, 11, 0, newEntityBox(60, 0, -3.5, -3, -3, 6, 16, 5).mirrored()).animated(animWalk(-1)),
+		},
+	},
+	"horse": horseModel(),
+
+	// Worn by players and bipedal mobs
+	"armor": armorModel(),
+
+	"arrow":    arrowModel(),
+	"boat":     boatModel(),
+	"minecart": minecartModel(),
+}
+
+func newEntityBox(u, v int, x, y, z float64, w, h, d int) entityBox {
+	return entityBox{U: u, V: v, X: x, Y: y, Z: z, W: w, H: h, D: d}
+}
+
+func (b entityBox) grow(f float64) entityBox {
+	b.Inflate = f
+	return b
+}
+
+func (b entityBox) mirrored() entityBox {
+	b.Mirror = !b.Mirror
+	return b
+}
+
+func (b entityBox) texture(i int) entityBox {
+	b.Texture = i
+	return b
+}
+
+func (b entityBox) at(x, y, z float64) entityBox {
+	b.Origin = [3]float64{x, y, z}
+	return b
+}
+
+func (b entityBox) rotated(x, y, z float64) entityBox {
+	b.Rotation = [3]float64{x, y, z}
+	return b
+}
+
+// flipped mirrors the box to the other side of the x axis.
+func (b entityBox) flipped() entityBox {
+	b.X = -(b.X + float64(b.W))
+	b.Origin[0] = -b.Origin[0]
+	b.Rotation[1], b.Rotation[2] = -b.Rotation[1], -b.Rotation[2]
+	return b.mirrored()
+}
+
+func newEntityBone(name string, x, y, z float64, boxes ...entityBox) entityBone {
+	return entityBone{
+		Name:  name,
+		Pivot: [3]float64{x, y, z},
+		Boxes: boxes,
+	}
+}
+
+func (b entityBone) child(parent string) entityBone {
+	b.Parent = parent
+	return b
+}
+
+func (b entityBone) rotated(x, y, z float64) entityBone {
+	b.Rotation = [3]float64{x, y, z}
+	return b
+}
+
+func (b entityBone) animated(a ...entityAnimation) entityBone {
+	b.Animations = append(b.Animations, a...)
+	return b
+}
+
+// flipped mirrors the bone to the other side of the x axis
+// under the new name.
+func (b entityBone) flipped(name, parent string) entityBone {
+	b.Name, b.Parent = name, parent
+	b.Pivot[0] = -b.Pivot[0]
+	b.Rotation[1], b.Rotation[2] = -b.Rotation[1], -b.Rotation[2]
+	boxes := make([]entityBox, len(b.Boxes))
+	for i, box := range b.Boxes {
+		boxes[i] = box.flipped()
+	}
+	b.Boxes = boxes
+	anims := make([]entityAnimation, len(b.Animations))
+	for i, a := range b.Animations {
+		switch {
+		case a.Type == entityAnimBob:
+			if a.Axis == entityAxisX {
+				a.Scale = -a.Scale
+			}
+		case a.Axis == entityAxisX:
+		case a.Type == entityAnimSpin:
+			a.Speed, a.Phase = -a.Speed, -a.Phase
+		default:
+			a.Scale = -a.Scale
+		}
+		anims[i] = a
+	}
+	b.Animations = anims
+	return b
+}
+
+func animLook() entityAnimation {
+	return entityAnimation{Type: entityAnimLook, Axis: entityAxisX, Scale: 1}
+}
+
+func animWalk(scale float64) entityAnimation {
+	return entityAnimation{Type: entityAnimWalk, Axis: entityAxisX, Scale: scale}
+}
+
+func animWave(axis entityAxis, scale, speed, phase float64) entityAnimation {
+	return entityAnimation{Type: entityAnimWave, Axis: axis, Scale: scale, Speed: speed, Phase: phase}
+}
+
+func animSpin(axis entityAxis, speed, phase float64) entityAnimation {
+	return entityAnimation{Type: entityAnimSpin, Axis: axis, Speed: speed, Phase: phase}
+}
+
+func animBob(axis entityAxis, scale, speed, phase float64) entityAnimation {
+	return entityAnimation{Type: entityAnimBob, Axis: axis, Scale: scale, Speed: speed, Phase: phase}
+}
+
+// armSway returns the idle movement of a biped's arm, side is
+// 1 for the right arm and -1 for the left.
+func armSway(side float64) []entityAnimation {
+	return []entityAnimation{
+		animWave(entityAxisZ, 0.05*side, 0.09, math.Pi/2),
+		animWave(entityAxisX, 0.05*side, 0.067, 0),
+	}
+}
+
+// bipedModel returns a humanoid model. armPitch is the resting
+// rotation of the arms and armSwing how much they move whilst
+// walking.
+func bipedModel(texture string, w, h int, armPitch, armSwing float64) *entityModel {
+	return &entityModel{
+		Textures: []entityTexture{{texture, w, h}},
+		Bones: []entityBone{
+			newEntityBone("head", 0, 0, 0,
+				newEntityBox(0, 0, -4, -8, -4, 8, 8, 8),
+				newEntityBox(32, 0, -4, -8, -4, 8, 8, 8).grow(0.5),
+			).animated(animLook()),
+			newEntityBone("body", 0, 0, 0, newEntityBox(16, 16, -4, 0, -2, 8, 12, 4)),
+			newEntityBone("arm_right", -5, 2, 0, newEntityBox(40, 16, -3, -2, -2, 4, 12, 4)).
+				rotated(armPitch, 0, 0.05).
+				animated(animWalk(-armSwing)).
+				animated(armSway(1)...),
+			newEntityBone("arm_left", 5, 2, 0, newEntityBox(40, 16, -1, -2, -2, 4, 12, 4).mirrored()).
+				rotated(armPitch, 0, -0.05).
+				animated(animWalk(armSwing)).
+				animated(armSway(-1)...),
+			newEntityBone("leg_right", -1.9, 12, 0, newEntityBox(0, 16, -2, 0, -2, 4, 12, 4)).animated(animWalk(1)),
+			newEntityBone("leg_left", 1.9, 12, 0, newEntityBox(0, 16, -2, 0, -2, 4, 12, 4).mirrored()).animated(animWalk(-1)),
+		},
+	}
+}
+
+func skeletonModel() *entityModel {
+	m := bipedModel("entity/skeleton/skeleton", 64, 32, 0, 0.75)
+	m.Bones[2].Boxes = []entityBox{newEntityBox(40, 16, -1, -2, -1, 2, 12, 2)}
+	m.Bones[3].Boxes = []entityBox{newEntityBox(40, 16, -1, -2, -1, 2, 12, 2).mirrored()}
+	m.Bones[4].Boxes = []entityBox{newEntityBox(0, 16, -1, 0, -1, 2, 12, 2)}
+	m.Bones[4].Pivot[0] = -2
+	m.Bones[5].Boxes = []entityBox{newEntityBox(0, 16, -1, 0, -1, 2, 12, 2).mirrored()}
+	m.Bones[5].Pivot[0] = 2
+	return m
+}
+
+func endermanModel() *entityModel {
+	return &entityModel{
+		Textures: []entityTexture{{"entity/enderman/enderman", 64, 32}},
+		Bones: []entityBone{
+			newEntityBone("head", 0, -14, 0,
+				newEntityBox(0, 0, -4, -8, -4, 8, 8, 8).grow(-0.5),
+				newEntityBox(0, 16, -4, -8, -4, 8, 8, 8).grow(-0.5).at(0, 1, 0),
+			).animated(animLook()),
+			newEntityBone("body", 0, -14, 0, newEntityBox(32, 16, -4, 0, -2, 8, 12, 4)),
+			newEntityBone("arm_right", -5, -12, 0, newEntityBox(56, 0, -1, -2, -1, 2, 30, 2)).
+				rotated(0, 0, 0.05).
+				animated(animWalk(-0.375)).
+				animated(armSway(1)...),
+			newEntityBone("arm_left", 5, -12, 0, newEntityBox(56, 0, -1, -2, -1, 2, 30, 2).mirrored()).
+				rotated(0, 0, -0.05).
+				animated(animWalk(0.375)).
+				animated(armSway(-1)...),
+			newEntityBone("leg_right", -2, -2, 0, newEntityBox(56, 0, -1, 0, -1, 2, 30, 2)).animated(animWalk(0.5)),
+			newEntityBone("leg_left", 2, -2, 0, newEntityBox(56, 0, -1, 0, -1, 2, 30, 2).mirrored()).animated(animWalk(-0.5)),
+		},
+	}
+}
+
+func spiderModel(texture string, scale float64) *entityModel {
+	m := &entityModel{
+		Textures: []entityTexture{{texture, 64, 32}},
+		Scale:    scale,
+		Bones: []entityBone{
+			newEntityBone("head", 0, 15, -3, newEntityBox(32, 4, -4, -4, -8, 8, 8, 8)).animated(animLook()),
+			newEntityBone("body", 0, 15, 9,
+				newEntityBox(0, 12, -5, -4, -6, 10, 8, 12),
+				newEntityBox(0, 0, -3, -3, -3, 6, 6, 6).at(0, 0, -9),
+			),
+		},
+	}
+	legs := []struct {
+		z, roll, yaw, swing float64
+	}{
+		{2, math.Pi / 4, math.Pi / 4, 0.5},
+		{1, math.Pi / 4 * 0.74, math.Pi / 8, -0.5},
+		{0, math.Pi / 4 * 0.74, -math.Pi / 8, 0.5},
+		{-1, math.Pi / 4, -math.Pi / 4, -0.5},
+	}
+	for i, l := range legs {
+		right := newEntityBone("leg_right_"+strconv.Itoa(i+1), -4, 15, l.z, newEntityBox(18, 0, -15, -1, -1, 16, 2, 2)).
+			rotated(0, l.yaw, -l.roll).
+			animated(entityAnimation{Type: entityAnimWalk, Axis: entityAxisY, Scale: l.swing})
+		m.Bones = append(m.Bones, right, right.flipped("leg_left_"+strconv.Itoa(i+1), ""))
+	}
+	return m
+}
+
+func magmaCubeModel() *entityModel {
+	body := newEntityBone("body", 0, 0, 0, newEntityBox(0, 16, -2, 18, -2, 4, 4, 4))
+	for i := 0; i < 8; i++ {
+		u, v := 0, i
+		switch i {
+		case 2:
+			u, v = 24, 10
+		case 3:
+			u, v = 24, 19
+		}
+		body.Boxes = append(body.Boxes, newEntityBox(u, v, -4, 16+float64(i), -4, 8, 1, 8))
+	}
+	return &entityModel{
+		Textures: []entityTexture{{"entity/slime/magmacube", 64, 32}},
+		Scale:    2,
+		Bones:    []entityBone{body},
+	}
+}
+
+func ghastModel() *entityModel {
+	// The model is drawn lower than the entity, leaving the
+	// tentacles hanging below its bounding box
+	const drop = 0.6 * 16
+	m := &entityModel{
+		Textures: []entityTexture{{"entity/ghast/ghast", 64, 32}},
+		Scale:    4.5,
+		Bones: []entityBone{
+			newEntityBone("body", 0, 8+drop, 0, newEntityBox(0, 0, -8, -8, -8, 16, 16, 16)),
+		},
+	}
+	lengths := [9]int{5, 8, 4, 7, 3, 6, 8, 5, 2}
+	for i, l := range lengths {
+		x := ((float64(i%3)-float64(i/3%2)*0.5+0.25)/2*2 - 1) * 5
+		z := (float64(i/3)/2*2 - 1) * 5
+		m.Bones = append(m.Bones, newEntityBone("tentacle_"+strconv.Itoa(i+1), x, 15+drop, z, newEntityBox(0, 0, -1, 0, -1, 2, l, 2)).
+			rotated(0.4, 0, 0).
+			animated(animWave(entityAxisX, 0.2, 0.3, float64(i))),
+		)
+	}
+	return m
+}
+
+func blazeModel() *entityModel {
+	m := &entityModel{
+		Textures: []entityTexture{{"entity/blaze", 64, 32}},
+		Bones: []entityBone{
+			newEntityBone("head", 0, 0, 0, newEntityBox(0, 0, -4, -4, -4, 8, 8, 8)).animated(animLook()),
+		},
+	}
+	rings := []struct {
+		y, radius, speed, phase, bob float64
+	}{
+		{-2, 9, math.Pi * 0.1, 0, 0.25},
+		{2, 7, -math.Pi * 0.03, -math.Pi / 4, 0.25},
+		{11, 5, math.Pi * 0.05, -0.47123894, 0.5},
+	}
+	for i, r := range rings {
+		ring := newEntityBone("rods_"+strconv.Itoa(i+1), 0, r.y, 0).
+			animated(
+				animSpin(entityAxisY, r.speed, r.phase),
+				animBob(entityAxisY, 1, r.bob, math.Pi/2),
+			)
+		for j := 0; j < 4; j++ {
+			ring.Boxes = append(ring.Boxes, newEntityBox(0, 16, 0, 0, 0, 2, 8, 2).
+				at(math.Cos(float64(j))*r.radius, 0, math.Sin(float64(j))*r.radius),
+			)
+		}
+		m.Bones = append(m.Bones, ring)
+	}
+	return m
+}
+
+// segmentedModel returns a model made of a line of segments that
+// wiggle from side to side like silverfish and endermites.
+func segmentedModel(texture string, sizes, uvs [][3]int, yaw, shift float64) *entityModel {
+	m := &entityModel{
+		Textures: []entityTexture{{texture, 64, 32}},
+	}
+	z := -3.5
+	for i, s := range sizes {
+		w, h, d := s[0], s[1], s[2]
+		dist := math.Abs(float64(i) - 2)
+		phase := float64(i)*0.15*math.Pi + math.Pi/2
+		m.Bones = append(m.Bones, newEntityBone("segment_"+strconv.Itoa(i+1), 0, 24-float64(h), z,
+			newEntityBox(uvs[i][0], uvs[i][1], -float64(w)*0.5, 0, -float64(d)*0.5, w, h, d),
+		).animated(
+			animWave(entityAxisY, math.Pi*yaw*(1+dist), 0.9, phase),
+			animBob(entityAxisX, math.Pi*shift*dist, 0.9, phase-math.Pi/2),
+		))
+		if i < len(sizes)-1 {
+			z += float64(d+sizes[i+1][2]) * 0.5
+		}
+	}
+	return m
+}
+
+func silverfishModel() *entityModel {
+	sizes := [][3]int{{3, 2, 2}, {4, 3, 2}, {6, 4, 3}, {3, 3, 3}, {2, 2, 3}, {2, 1, 2}, {1, 1, 2}}
+	m := segmentedModel("entity/silverfish", sizes, [][3]int{
+		{0, 0}, {0, 4}, {0, 9}, {0, 16}, {0, 22}, {11, 0}, {13, 4},
+	}, 0.05, 0.2)
+	// The scales on the back follow the segment they sit on
+	m.Bones[2].Boxes = append(m.Bones[2].Boxes, newEntityBox(20, 0, -5, 0, -float64(sizes[2][2])*0.5, 10, 8, sizes[2][2]).at(0, -4, 0))
+	m.Bones[4].Boxes = append(m.Bones[4].Boxes, newEntityBox(20, 11, -3, 0, -float64(sizes[4][2])*0.5, 6, 4, sizes[4][2]).at(0, -2, 0))
+	m.Bones[1].Boxes = append(m.Bones[1].Boxes, newEntityBox(20, 18, -3, 0, -float64(sizes[1][2])*0.5, 6, 5, sizes[1][2]).at(0, -2, 0))
+	return m
+}
+
+func endermiteModel() *entityModel {
+	return segmentedModel("entity/endermite", [][3]int{
+		{4, 3, 2}, {6, 4, 5}, {3, 3, 1}, {1, 2, 1},
+	}, [][3]int{
+		{0, 0}, {0, 5}, {0, 14}, {0, 18},
+	}, 0.01, 0.1)
+}
+
+func batModel() *entityModel {
+	wing := newEntityBone("wing_right", 0, 0, 0, newEntityBox(42, 0, -12, 1, 1.5, 10, 16, 1)).
+		child("body").
+		animated(animWave(entityAxisY, math.Pi/4, 1.3, math.Pi/2))
+	outer := newEntityBone("wing_outer_right", -12, 1, 1.5, newEntityBox(24, 16, -8, 1, 0, 8, 12, 1)).
+		child("wing_right").
+		animated(animWave(entityAxisY, math.Pi/8, 1.3, math.Pi/2))
+	return &entityModel{
+		Textures: []entityTexture{{"entity/bat", 64, 64}},
+		Scale:    0.35,
+		Bones: []entityBone{
+			newEntityBone("head", 0, 0, 0,
+				newEntityBox(0, 0, -3, -3, -3, 6, 6, 6),
+				newEntityBox(24, 0, -4, -6, -2, 3, 4, 1),
+				newEntityBox(24, 0, 1, -6, -2, 3, 4, 1).mirrored(),
+			).animated(animLook()),
+			newEntityBone("body", 0, 0, 0,
+				newEntityBox(0, 16, -3, 4, -3, 6, 12, 6),
+				newEntityBox(0, 34, -5, 16, 0, 10, 6, 1),
+			).rotated(math.Pi/4, 0, 0).animated(animWave(entityAxisX, 0.15, 0.1, math.Pi/2)),
+			wing,
+			outer,
+			wing.flipped("wing_left", "body"),
+			outer.flipped("wing_outer_left", "wing_left"),
+		},
+	}
+}
+
+func villagerModel(texture string, w, h int) *entityModel {
+	return &entityModel{
+		Textures: []entityTexture{{texture, w, h}},
+		Bones: []entityBone{
+			newEntityBone("head", 0, 0, 0,
+				newEntityBox(0, 0, -4, -10, -4, 8, 10, 8),
+				newEntityBox(24, 0, -1, -1, -6, 2, 4, 2).at(0, -2, 0),
+			).animated(animLook()),
+			newEntityBone("body", 0, 0, 0,
+				newEntityBox(16, 20, -4, 0, -3, 8, 12, 6),
+				newEntityBox(0, 38, -4, 0, -3, 8, 18, 6).grow(0.5),
+			),
+			newEntityBone("arms", 0, 2, 0,
+				newEntityBox(44, 22, -8, -2, -2, 4, 8, 4),
+				newEntityBox(44, 22, 4, -2, -2, 4, 8, 4),
+				newEntityBox(40, 38, -4, 2, -2, 8, 4, 4),
+			).rotated(-0.75, 0, 0),
+			newEntityBone("leg_right", -2, 12, 0, newEntityBox(0, 22, -2, 0, -2, 4, 12, 4)).animated(animWalk(0.7)),
+			newEntityBone("leg_left", 2, 12, 0, newEntityBox(0, 22, -2, 0, -2, 4, 12, 4).mirrored()).animated(animWalk(-0.7)),
+		},
+	}
+}
+
+func witchModel() *entityModel {
+	m := villagerModel("entity/witch", 64, 128)
+	m.Bones[0].Boxes = append(m.Bones[0].Boxes,
+		newEntityBox(0, 0, 0, 3, -6.75, 1, 1, 1).grow(-0.25).at(0, -2, 0),
+		newEntityBox(0, 64, 0, 0, 0, 10, 2, 10).at(-5, -10.03, -5),
+		newEntityBox(0, 76, 0, 0, 0, 7, 4, 7).at(-3.25, -14.03, -3).rotated(-0.05, 0, 0.02617994),
+		newEntityBox(0, 87, 0, 0, 0, 4, 4, 4).at(-1.5, -18.03, -1).rotated(-0.15, 0, 0.05235988),
+		newEntityBox(0, 95, 0, 0, 0, 1, 2, 1).grow(0.25).at(0.25, -20.03, 1).rotated(-0.35, 0, 0.10471976),
+	)
+	return m
+}
+
+func guardianModel() *entityModel {
+	body := newEntityBone("body", 0, 0, 0,
+		newEntityBox(0, 0, -6, 10, -8, 12, 12, 16),
+		newEntityBox(0, 28, -8, 10, -6, 2, 12, 12),
+		newEntityBox(0, 28, 6, 10, -6, 2, 12, 12).mirrored(),
+		newEntityBox(16, 40, -6, 8, -6, 12, 2, 12).mirrored(),
+		newEntityBox(16, 40, -6, 22, -6, 12, 2, 12),
+		newEntityBox(8, 0, -1, 15, 0, 2, 2, 1).at(0, 0, -8.25),
+	).animated(animLook())
+	spikes := [12][6]float64{
+		{1.75, 0, 0, 0, -8, 8},
+		{0.25, 0, 0, 0, -8, -8},
+		{0, 0, 0.25, 8, -8, 0},
+		{0, 0, 1.75, -8, -8, 0},
+		{0.5, 0.25, 0, -8, 0, -8},
+		{0.5, 1.75, 0, 8, 0, -8},
+		{0.5, 1.25, 0, 8, 0, 8},
+		{0.5, 0.75, 0, -8, 0, 8},
+		{1.25, 0, 0, 0, 8, 8},
+		{0.75, 0, 0, 0, 8, -8},
+		{0, 0, 0.75, 8, 8, 0},
+		{0, 0, 1.25, -8, 8, 0},
+	}
+	for _, s := range spikes {
+		body.Boxes = append(body.Boxes, newEntityBox(0, 0, -1, -4.5, -1, 2, 9, 2).
+			at(s[3], 16+s[4], s[5]).
+			rotated(math.Pi*s[0], math.Pi*s[1], math.Pi*s[2]),
+		)
+	}
+	return &entityModel{
+		Textures: []entityTexture{{"entity/guardian", 64, 64}},
+		Bones: []entityBone{
+			body,
+			newEntityBone("tail_1", 0, 0, 0, newEntityBox(40, 0, -2, 14, 7, 4, 4, 8)).
+				child("body").
+				animated(animWave(entityAxisY, math.Pi*0.05, 0.1, 0)),
+			newEntityBone("tail_2", -1.5, 0.5, 14, newEntityBox(0, 54, 0, 14, 0, 3, 3, 7)).
+				child("tail_1").
+				animated(animWave(entityAxisY, math.Pi*0.1, 0.1, 0)),
+			newEntityBone("tail_3", 0.5, 0.5, 6,
+				newEntityBox(41, 32, 0, 14, 0, 2, 2, 6),
+				newEntityBox(25, 19, 1, 10.5, 3, 1, 9, 9),
+			).child("tail_2").animated(animWave(entityAxisY, math.Pi*0.15, 0.1, 0)),
+		},
+	}
+}
+
+func witherModel() *entityModel {
+	return &entityModel{
+		Textures: []entityTexture{{"entity/wither/wither", 64, 64}},
+		Scale:    2,
+		Bones: []entityBone{
+			newEntityBone("spine", 0, 0, 0, newEntityBox(0, 16, -10, 3.9, -0.5, 20, 3, 3)),
+			newEntityBone("ribs", -2, 6.9, -0.5,
+				newEntityBox(0, 22, 0, 0, 0, 3, 10, 3),
+				newEntityBox(24, 22, -4, 1.5, 0.5, 11, 2, 2),
+				newEntityBox(24, 22, -4, 4, 0.5, 11, 2, 2),
+				newEntityBox(24, 22, -4, 6.5, 0.5, 11, 2, 2),
+			).rotated(0.065*math.Pi, 0, 0).animated(animWave(entityAxisX, 0.05*math.Pi, 0.1, math.Pi/2)),
+			newEntityBone("tail", 0, 10, 0, newEntityBox(12, 22, 0, 0, 0, 3, 6, 3)).
+				child("ribs").
+				rotated(0.2*math.Pi, 0, 0).
+				animated(animWave(entityAxisX, 0.05*math.Pi, 0.1, math.Pi/2)),
+			newEntityBone("head_center", 0, 0, 0, newEntityBox(0, 0, -4, -4, -4, 8, 8, 8)).animated(animLook()),
+			newEntityBone("head_right", -8, 4, 0, newEntityBox(32, 0, -4, -4, -4, 6, 6, 6)).animated(animLook()),
+			newEntityBone("head_left", 10, 4, 0, newEntityBox(32, 0, -4, -4, -4, 6, 6, 6)).animated(animLook()),
+		},
+	}
+}
+
+type dragonSegment struct {
+	pivot [3]float64
+	pitch float64
+	box   entityBox
+}
+
+// dragonLimb returns the boxes of a jointed limb in its resting
+// pose. Each segment's pivot is relative to the previous one.
+func dragonLimb(segments ...dragonSegment) []entityBox {
+	var out []entityBox
+	var origin [3]float64
+	var pitch float64
+	for _, s := range segments {
+		y, z := s.pivot[1], s.pivot[2]
+		origin[0] += s.pivot[0]
+		origin[1] += y*math.Cos(pitch) - z*math.Sin(pitch)
+		origin[2] += y*math.Sin(pitch) + z*math.Cos(pitch)
+		pitch += s.pitch
+		out = append(out, s.box.at(origin[0], origin[1], origin[2]).rotated(pitch, 0, 0))
+	}
+	return out
+}
+
+func dragonModel() *entityModel {
+	front := dragonLimb(
+		dragonSegment{[3]float64{-12, 20, 2}, 1.3, newEntityBox(112, 104, -4, -4, -4, 8, 24, 8)},
+		dragonSegment{[3]float64{0, 20, -1}, 0.5, newEntityBox(226, 138, -3, -1, -3, 6, 24, 6)},
+		dragonSegment{[3]float64{0, 23, 0}, 0.75, newEntityBox(144, 104, -4, 0, -12, 8, 4, 16)},
+	)
+	rear := dragonLimb(
+		dragonSegment{[3]float64{-16, 16, 42}, 1.0, newEntityBox(0, 0, -8, -4, -8, 16, 32, 16)},
+		dragonSegment{[3]float64{0, 32, -4}, 0.5, newEntityBox(196, 0, -6, -2, 0, 12, 32, 12)},
+		dragonSegment{[3]float64{0, 31, 4}, 0.75, newEntityBox(112, 0, -9, 0, -20, 18, 6, 24)},
+	)
+	for _, legs := range []*[]entityBox{&front, &rear} {
+		for _, b := range *legs {
+			*legs = append(*legs, b.flipped())
+		}
+	}
+
+	body := newEntityBone("body", 0, 4, 8,
+		newEntityBox(0, 0, -12, 0, -16, 24, 24, 64),
+		newEntityBox(220, 53, -1, -6, -10, 2, 6, 12),
+		newEntityBox(220, 53, -1, -6, 10, 2, 6, 12),
+		newEntityBox(220, 53, -1, -6, 30, 2, 6, 12),
+	)
+	// The neck and tail are made of the same segments, in vanilla
+	// these are positioned every frame but here they are kept still
+	for i := 0; i < 5; i++ {
+		z := -20 - 10*float64(i)
+		body.Boxes = append(body.Boxes,
+			newEntityBox(192, 104, -5, -5, -5, 10, 10, 10).at(0, 16, z),
+			newEntityBox(48, 0, -1, -9, -3, 2, 4, 6).at(0, 16, z),
+		)
+	}
+	for i := 0; i < 12; i++ {
+		z := 52 + 10*float64(i)
+		body.Boxes = append(body.Boxes,
+			newEntityBox(192, 104, -5, -5, -5, 10, 10, 10).at(0, 6, z),
+			newEntityBox(48, 0, -1, -9, -3, 2, 4, 6).at(0, 6, z),
+		)
+	}
+
+	wing := newEntityBone("wing_right", -12, 5, 2,
+		newEntityBox(112, 88, -56, -4, -4, 56, 8, 8),
+		newEntityBox(-56, 88, -56, 0, 2, 56, 0, 56),
+	).rotated(0.125, 0.25, 0.1).animated(
+		animWave(entityAxisZ, 0.8, 0.15, 0),
+		animWave(entityAxisX, -0.2, 0.15, math.Pi/2),
+	)
+	tip := newEntityBone("wing_tip_right", -56, 0, 0,
+		newEntityBox(112, 136, -56, -2, -2, 56, 4, 4),
+		newEntityBox(-56, 144, -56, 0, 2, 56, 0, 56),
+	).child("wing_right").rotated(0, 0, -0.375).animated(
+		animWave(entityAxisZ, -0.75, 0.15, 2),
+	)
+
+	return &entityModel{
+		Textures: []entityTexture{{"entity/enderdragon/dragon", 256, 256}},
+		Bones: []entityBone{
+			body,
+			newEntityBone("head", 0, 20, -62,
+				newEntityBox(176, 44, -6, -1, -24, 12, 5, 16),
+				newEntityBox(112, 30, -8, -8, -10, 16, 16, 16),
+				newEntityBox(0, 0, -5, -12, -4, 2, 4, 6).mirrored(),
+				newEntityBox(112, 0, -5, -3, -22, 2, 2, 4).mirrored(),
+				newEntityBox(0, 0, 3, -12, -4, 2, 4, 6),
+				newEntityBox(112, 0, 3, -3, -22, 2, 2, 4),
+				newEntityBox(176, 65, -6, 0, -16, 12, 4, 16).at(0, 4, -8).rotated(0.2, 0, 0),
+			).animated(animLook()),
+			wing,
+			tip,
+			wing.flipped("wing_left", ""),
+			tip.flipped("wing_tip_left", "wing_left"),
+			newEntityBone("legs_front", 0, 0, 0, front...),
+			newEntityBone("legs_rear", 0, 0, 0, rear...),
+		},
+	}
+}
+
+// quadrupedModel returns a four legged model, the head and body
+// are expected to be replaced by the caller.
+func quadrupedModel(texture string, height int) *entityModel {
+	h := float64(height)
+	leg := newEntityBox(0, 16, -2, 0, -2, 4, height, 4)
+	return &entityModel{
+		Textures: []entityTexture{{texture, 64, 32}},
+		Bones: []entityBone{
+			newEntityBone("head", 0, 18-h, -6, newEntityBox(0, 0, -4, -4, -8, 8, 8, 8)).animated(animLook()),
+			newEntityBone("body", 0, 17-h, 2, newEntityBox(28, 8, -5, -10, -7, 10, 16, 8)).rotated(math.Pi/2, 0, 0),
+			newEntityBone("leg_back_right", -3, 24-h, 7, leg).animated(animWalk(1)),
+			newEntityBone("leg_back_left", 3, 24-h, 7, leg).animated(animWalk(-1)),
+			newEntityBone("leg_front_right", -3, 24-h, -5, leg).animated(animWalk(-1)),
+			newEntityBone("leg_front_left", 3, 24-h, -5, leg).animated(animWalk(1)),
+		},
+	}
+}
+
+func pigModel() *entityModel {
+	m := quadrupedModel("entity/pig/pig", 6)
+	m.Bones[0].Boxes = append(m.Bones[0].Boxes, newEntityBox(16, 16, -2, 0, -9, 4, 3, 1))
+	return m
+}
+
+func cowModel(texture string) *entityModel {
+	m := quadrupedModel(texture, 12)
+	m.Bones[0].Pivot = [3]float64{0, 4, -8}
+	m.Bones[0].Boxes = []entityBox{
+		newEntityBox(0, 0, -4, -4, -6, 8, 8, 6),
+		newEntityBox(22, 0, -5, -5, -4, 1, 3, 1),
+		newEntityBox(22, 0, 4, -5, -4, 1, 3, 1),
+	}
+	m.Bones[1].Pivot = [3]float64{0, 5, 2}
+	m.Bones[1].Boxes = []entityBox{
+		newEntityBox(18, 4, -6, -10, -7, 12, 18, 10),
+		newEntityBox(52, 0, -2, 2, -8, 4, 6, 1),
+	}
+	for i, p := range [][3]float64{{-4, 12, 7}, {4, 12, 7}, {-4, 12, -6}, {4, 12, -6}} {
+		m.Bones[2+i].Pivot = p
+	}
+	return m
+}
+
+func sheepModel() *entityModel {
+	m := quadrupedModel("entity/sheep/sheep", 12)
+	m.Textures = append(m.Textures, entityTexture{"entity/sheep/sheep_fur", 64, 32})
+	m.Bones[0].Pivot = [3]float64{0, 6, -8}
+	m.Bones[0].Boxes = []entityBox{
+		newEntityBox(0, 0, -3, -4, -6, 6, 6, 8),
+		newEntityBox(0, 0, -3, -4, -4, 6, 6, 6).grow(0.6).texture(1),
+	}
+	m.Bones[1].Pivot = [3]float64{0, 5, 2}
+	m.Bones[1].Boxes = []entityBox{
+		newEntityBox(28, 8, -4, -10, -7, 8, 16, 6),
+		newEntityBox(28, 8, -4, -10, -7, 8, 16, 6).grow(1.75).texture(1),
+	}
+	for i := 2; i < 6; i++ {
+		m.Bones[i].Boxes = []entityBox{
+			m.Bones[i].Boxes[0],
+			newEntityBox(0, 16, -2, 0, -2, 4, 6, 4).grow(0.5).texture(1),
+		}
+	}
+	return m
+}
+
+func squidModel() *entityModel {
+	m := &entityModel{
+		Textures: []entityTexture{{"entity/squid", 64, 32}},
+		Bones: []entityBone{
+			newEntityBone("body", 0, 8, 0, newEntityBox(0, 0, -6, -8, -6, 12, 16, 12)),
+		},
+	}
+	for i := 0; i < 8; i++ {
+		ang := float64(i) * math.Pi * 2 / 8
+		m.Bones = append(m.Bones, newEntityBone("tentacle_"+strconv.Itoa(i+1), math.Cos(ang)*5, 15, math.Sin(ang)*5,
+			newEntityBox(48, 0, -1, 0, -1, 2, 18, 2),
+		).rotated(0.3, -ang+math.Pi/2, 0).animated(animWave(entityAxisX, 0.3, 0.2, 0)))
+	}
+	return m
+}
+
+func wolfModel() *entityModel {
+	leg := newEntityBox(0, 18, -1, 0, -1, 2, 8, 2)
+	return &entityModel{
+		Textures: []entityTexture{{"entity/wolf/wolf", 64, 32}},
+		Bones: []entityBone{
+			newEntityBone("head", -1, 13.5, -7,
+				newEntityBox(0, 0, -3, -3, -2, 6, 6, 4),
+				newEntityBox(16, 14, -3, -5, 0, 2, 2, 1),
+				newEntityBox(16, 14, 1, -5, 0, 2, 2, 1),
+				newEntityBox(0, 10, -1.5, 0, -5, 3, 3, 4),
+			).animated(animLook()),
+			newEntityBone("body", 0, 14, 2, newEntityBox(18, 14, -4, -2, -3, 6, 9, 6)).rotated(math.Pi/2, 0, 0),
+			newEntityBone("mane", -1, 14, -3, newEntityBox(21, 0, -4, -3, -3, 8, 6, 7)).rotated(math.Pi/2, 0, 0),
+			newEntityBone("leg_back_right", -2.5, 16, 7, leg).animated(animWalk(1)),
+			newEntityBone("leg_back_left", 0.5, 16, 7, leg).animated(animWalk(-1)),
+			newEntityBone("leg_front_right", -2.5, 16, -4, leg).animated(animWalk(-1)),
+			newEntityBone("leg_front_left", 0.5, 16, -4, leg).animated(animWalk(1)),
+			newEntityBone("tail", -1, 12, 8, newEntityBox(9, 18, -1, 0, -1, 2, 8, 2)).
+				rotated(math.Pi/5, 0, 0).
+				animated(entityAnimation{Type: entityAnimWalk, Axis: entityAxisY, Scale: 1}),
+		},
+	}
+}
+
+func ocelotModel() *entityModel {
+	backLeg := newEntityBox(8, 13, -1, 0, 1, 2, 6, 2)
+	frontLeg := newEntityBox(40, 0, -1, 0, 0, 2, 10, 2)
+	return &entityModel{
+		Textures: []entityTexture{{"entity/cat/ocelot", 64, 32}},
+		Bones: []entityBone{
+			newEntityBone("head", 0, 15, -9,
+				newEntityBox(0, 0, -2.5, -2, -3, 5, 4, 5),
+				newEntityBox(0, 24, -1.5, 0, -4, 3, 2, 2),
+				newEntityBox(0, 10, -2, -3, 0, 1, 1, 2),
+				newEntityBox(6, 10, 1, -3, 0, 1, 1, 2),
+			).animated(animLook()),
+			newEntityBone("body", 0, 12, -10, newEntityBox(20, 0, -2, 3, -8, 4, 16, 6)).rotated(math.Pi/2, 0, 0),
+			newEntityBone("tail_1", 0, 15, 8, newEntityBox(0, 15, -0.5, 0, 0, 1, 8, 1)).rotated(0.9, 0, 0),
+			newEntityBone("tail_2", 0, 20, 14, newEntityBox(4, 15, -0.5, 0, 0, 1, 8, 1)).rotated(1.7278761, 0, 0),
+			newEntityBone("leg_back_right", -1.1, 18, 5, backLeg).animated(animWalk(1)),
+			newEntityBone("leg_back_left", 1.1, 18, 5, backLeg).animated(animWalk(-1)),
+			newEntityBone("leg_front_right", -1.2, 13.8, -5, frontLeg).animated(animWalk(-1)),
+			newEntityBone("leg_front_left", 1.2, 13.8, -5, frontLeg).animated(animWalk(1)),
+		},
+	}
+}
+
+func rabbitModel() *entityModel {
+	// Rabbits hop rather than walk so the legs move together
+	return &entityModel{
+		Textures: []entityTexture{{"entity/rabbit/brown", 64, 32}},
+		Bones: []entityBone{
+			newEntityBone("head", 0, 16, -1,
+				newEntityBox(32, 0, -2.5, -4, -5, 5, 4, 5),
+				newEntityBox(32, 9, -0.5, -2.5, -5.5, 1, 1, 1),
+				newEntityBox(52, 0, -2.5, -9, -1, 2, 5, 1).rotated(0, -0.2617994, 0),
+				newEntityBox(58, 0, 0.5, -9, -1, 2, 5, 1).rotated(0, 0.2617994, 0),
+			).animated(animLook()),
+			newEntityBone("body", 0, 19, 8, newEntityBox(0, 0, -3, -2, -10, 6, 5, 10)).rotated(-0.3490659, 0, 0),
+			newEntityBone("tail", 0, 20, 7, newEntityBox(52, 6, -1.5, -1.5, 0, 3, 3, 2)).rotated(-0.3490659, 0, 0),
+			newEntityBone("arm_right", -3, 17, -1, newEntityBox(0, 15, -1, 0, -1, 2, 7, 2)).
+				rotated(-0.1745329, 0, 0).
+				animated(animWalk(0.5)),
+			newEntityBone("arm_left", 3, 17, -1, newEntityBox(8, 15, -1, 0, -1, 2, 7, 2)).
+				rotated(-0.1745329, 0, 0).
+				animated(animWalk(0.5)),
+			newEntityBone("thigh_right", -3, 17.5, 3.7, newEntityBox(16, 15, -1, 0, 0, 2, 4, 5)).
+				rotated(-0.3490659, 0, 0).
+				animated(animWalk(-0.5)),
+			newEntityBone("thigh_left", 3, 17.5, 3.7, newEntityBox(30, 15, -1, 0, 0, 2, 4, 5)).
+				rotated(-0.3490659, 0, 0).
+				animated(animWalk(-0.5)),
+			newEntityBone("foot_right", -3, 17.5, 3.7, newEntityBox(8, 24, -1, 5.5, -3.7, 2, 1, 7)).animated(animWalk(-0.5)),
+			newEntityBone("foot_left", 3, 17.5, 3.7, newEntityBox(26, 24, -1, 5.5, -3.7, 2, 1, 7)).animated(animWalk(-0.5)),
+		},
+	}
+}
+
+func horseModel() *entityModel {
+	leg := func(name string, x, z float64, u int, front bool) entityBone {
+		boxes := []entityBox{
+			newEntityBox(u, 29, -2.5, -2, -2.5, 4, 9, 5),
+			newEntityBox(u, 43, -2, 0, -1.5, 3, 5, 3).at(0, 7, 0),
+			newEntityBox(u, 51, -2.5, 5.1, -2, 4, 3, 4).at(0, 7, 0),
+		}
+		if front {
+			boxes = []entityBox{
+				newEntityBox(u, 29, -1.9, -1, -2.1, 3, 8, 4),
+				newEntityBox(u, 41, -1.9, 0, -1.6, 3, 5, 3).at(0, 7, 0),
+				newEntityBox(u, 51, -2.4, 5.1, -2.1, 4, 3, 4).at(0, 7, 0),
+			}
+		}
+		return newEntityBone(name, x, 9, z, boxes...)
+	}
+	return &entityModel{
+		Textures: []entityTexture{{"entity/horse/horse_white", 128, 128}},
+		Bones: []entityBone{
+			newEntityBone("head", 0, 4, -10,
+				newEntityBox(0, 0, -2.5, -10, -1.5, 5, 5, 7),
+				newEntityBox(24, 18, -2, -10, -7, 4, 3, 6),
+				newEntityBox(24, 27, -2, -7, -6.5, 4, 2, 5),
+				newEntityBox(0, 0, 0.45, -12, 4, 2, 3, 1).rotated(0, 0, 0.08726646),
+				newEntityBox(0, 0, -2.45, -12, 4, 2, 3, 1).rotated(0, 0, -0.08726646),
+				newEntityBox(0, 12, -2.05, -9.8, -2, 4, 14, 8),
+				newEntityBox(58, 0, -1, -11.5, 5, 2, 16, 4),
+			).rotated(0.5235988, 0, 0).animated(animLook()),
+			newEntityBone("body", 0, 11, 9, newEntityBox(0, 34, -5, -8, -19, 10, 10, 24)),
+			newEntityBone("tail", 0, 3, 14,
+				newEntityBox(44, 0, -1, -1, 0, 2, 2, 3),
+				newEntityBox(38, 7, -1.5, -2, 3, 3, 4, 7),
+				newEntityBox(24, 3, -1.5, -4.5, 9, 3, 4, 7).rotated(-0.267686, 0, 0),
+			).rotated(-1.134464, 0, 0),
+			leg("leg_back_right", -4, 11, 96, false).animated(animWalk(1)),
+			leg("leg_back_left", 4, 11, 78, false).animated(animWalk(-1)),
+			leg("leg_front_right", -4, -8, 60, true).animated(animWalk(-1)),
+			leg("leg_front_left", 4, -8, 44, true).animated(animWalk(1)),
+		},
+	}
+}
+
+// arrowModel returns the model for arrows. Vanilla draws arrows
+// with flat quads, boxes without depth give the same result but
+// as they only have one side each one is repeated facing the
+// other way.
+func arrowModel() *entityModel {
+	shaft := newEntityBox(0, -16, 0, -2.5, -8, 0, 5, 16)
+	end := newEntityBox(0, 5, -2.5, -2.5, 0, 5, 5, 0)
+	return &entityModel{
+		Textures: []entityTexture{{"entity/arrow", 32, 32}},
+		Bones: []entityBone{
+			newEntityBone("arrow", 0, 24, 0,
+				shaft,
+				shaft.rotated(0, 0, math.Pi/2),
+				shaft.rotated(0, 0, math.Pi),
+				shaft.rotated(0, 0, math.Pi*1.5),
+				end.at(0, 0, 8),
+				end.at(0, 0, 8).rotated(0, math.Pi, 0),
+			).animated(animLook()),
+		},
+	}
+}
+
+// boxSides returns the sides of an open box like the ones used
+// by boats and minecarts. width is the distance between the
+// left and right sides and length the distance between the
+// front and back.
+func boxSides(side entityBox, y, width, length float64) []entityBone {
+	return []entityBone{
+		newEntityBone("side_right", -width/2, y, 0, side).rotated(0, math.Pi*1.5, 0),
+		newEntityBone("side_left", width/2, y, 0, side).rotated(0, math.Pi/2, 0),
+		newEntityBone("side_front", 0, y, -length/2, side).rotated(0, math.Pi, 0),
+		newEntityBone("side_back", 0, y, length/2, side),
+	}
+}
+
+func boatModel() *entityModel {
+	return &entityModel{
+		Textures: []entityTexture{{"entity/boat", 64, 32}},
+		Bones: append([]entityBone{
+			newEntityBone("bottom", 0, 24, 0, newEntityBox(0, 8, -12, -8, -3, 24, 16, 4)).rotated(math.Pi/2, 0, 0),
+		}, boxSides(newEntityBox(0, 0, -10, -7, -1, 20, 6, 2), 24, 22, 18)...),
+	}
+}
+
+func minecartModel() *entityModel {
+	return &entityModel{
+		Textures: []entityTexture{{"entity/minecart", 64, 32}},
+		Bones: append([]entityBone{
+			newEntityBone("bottom", 0, 22, 0, newEntityBox(0, 10, -10, -8, -1, 20, 16, 2)).rotated(math.Pi/2, 0, 0),
+			newEntityBone("inside", 0, 22, 0, newEntityBox(44, 10, -9, -7, -1, 18, 14, 1)).rotated(-math.Pi/2, 0, 0),
+		}, boxSides(newEntityBox(0, 0, -8, -9, -1, 16, 8, 2), 22, 18, 14)...),
+	}
+}
+
+// armorModel returns the model for armor worn by bipeds. Each
+// piece of armor uses its own texture, the bones are matched
+// to the bones of the entity wearing it by name.
+func armorModel() *entityModel {
+	const (
+		helmet = iota
+		chestplate
+		leggings
+		boots
+	)
+	leg := newEntityBox(0, 16, -2, 0, -2, 4, 12, 4)
+	return &entityModel{
+		Textures: []entityTexture{
+			helmet:     {"#helmet", 64, 32},
+			chestplate: {"#chestplate", 64, 32},
+			leggings:   {"#leggings", 64, 32},
+			boots:      {"#boots", 64, 32},
+		},
+		Bones: []entityBone{
+			newEntityBone("head", 0, 0, 0,
+				newEntityBox(0, 0, -4, -8, -4, 8, 8, 8).grow(1).texture(helmet),
+			),
+			newEntityBone("body", 0, 0, 0,
+				newEntityBox(16, 16, -4, 0, -2, 8, 12, 4).grow(1).texture(chestplate),
+				newEntityBox(16, 16, -4, 0, -2, 8, 12, 4).grow(0.5).texture(leggings),
+			),
+			newEntityBone("arm_right", -5, 2, 0,
+				newEntityBox(40, 16, -3, -2, -2, 4, 12, 4).grow(1).texture(chestplate),
+			),
+			newEntityBone("arm_left", 5, 2, 0,
+				newEntityBox(40, 16, -1, -2, -2, 4, 12, 4).grow(1).texture(chestplate).mirrored(),
+			),
+			newEntityBone("leg_right", -1.9, 12, 0,
+				leg.grow(0.5).texture(leggings),
+				leg.grow(1).texture(boots),
+			),
+			newEntityBone("leg_left", 1.9, 12, 0,
+				leg.grow(0.5).texture(leggings).mirrored(),
+				leg.grow(1).texture(boots).mirrored(),
+			),
+		},
+	}
+}
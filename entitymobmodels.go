@@ -0,0 +1,125 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/thinkofdeath/steven/entitysys"
+	"github.com/thinkofdeath/steven/render"
+)
+
+func init() {
+	addSystem(entitysys.Add, esMobModelAdd)
+	addSystem(entitysys.Tick, esMobModelTick)
+}
+
+type mobModelComponent struct {
+	model *render.StaticModel
+	name  string
+	def   *entityModel
+
+	dir  float64
+	time float64
+	age  float64
+}
+
+func (m *mobModelComponent) Model() *render.StaticModel { return m.model }
+
+// bone returns the matrix of the named bone and its pivot
+// including the pivots of its parents.
+func (m *mobModelComponent) bone(name string) (mgl32.Mat4, [3]float64, bool) {
+	if m.model == nil {
+		return mgl32.Mat4{}, [3]float64{}, false
+	}
+	i := m.def.boneIndex(name)
+	if i == -1 {
+		return mgl32.Mat4{}, [3]float64{}, false
+	}
+	var pivot [3]float64
+	for b := i; b != -1; b = m.def.parents[b] {
+		for j, v := range m.def.Bones[b].Pivot {
+			pivot[j] += v
+		}
+	}
+	return m.model.Matrix[i], pivot, true
+}
+
+func esMobModelAdd(m *mobModelComponent) {
+	m.def = getEntityModel(m.name)
+	if m.def == nil {
+		return
+	}
+	parts, radius := m.def.parts(nil)
+	m.model = render.NewStaticModel(parts)
+	m.model.Radius = radius
+}
+
+func esMobModelTick(m *mobModelComponent,
+	pos PositionComponent, t *targetPositionComponent, r RotationComponent) {
+	if m.model == nil {
+		return
+	}
+	x, y, z := pos.Position()
+	model := m.model
+	model.X, model.Y, model.Z = -float32(x), -float32(y), float32(z)
+
+	scale := float32(m.def.Scale)
+	offMat := mgl32.Translate3D(float32(x), -float32(y), float32(z)).
+		Mul4(mgl32.Rotate3DY(math.Pi - float32(r.Yaw())).Mat4()).
+		Mul4(mgl32.Scale3D(scale, scale, scale))
+
+	// Age is kept in game ticks to match the speeds used by
+	// vanilla's models
+	m.age += Client.delta / 3
+
+	time := m.time
+	dir := m.dir
+	if dir == 0 {
+		dir = 1
+		time = 15
+	}
+	walk := ((time / 15) - 1) * (math.Pi / 4)
+	pitch := r.Pitch()
+	if pitch > math.Pi {
+		pitch -= math.Pi * 2
+	}
+
+	for i, b := range m.def.Bones {
+		rot := b.Rotation
+		var off [3]float64
+		for _, a := range b.Animations {
+			val := a.value(walk, pitch, m.age)
+			if a.Type == entityAnimBob {
+				off[a.Axis] += val
+			} else {
+				rot[a.Axis] += val
+			}
+		}
+		pivot := b.Pivot
+		mat := offMat
+		if p := m.def.parents[i]; p != -1 {
+			mat = model.Matrix[p]
+		} else {
+			pivot[1] -= 24
+		}
+		model.Matrix[i] = mat.Mul4(entityTransform(pivot, rot)).
+			Mul4(entityTransform(off, [3]float64{}))
+	}
+
+	moving := t.X != t.sX || t.Y != t.sY || t.Z != t.sZ
+	m.time, m.dir = stepWalk(t, time, dir, moving)
+}
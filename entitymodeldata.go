@@ -0,0 +1,397 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/thinkofdeath/steven/console"
+	"github.com/thinkofdeath/steven/render"
+	"github.com/thinkofdeath/steven/type/direction"
+)
+
+// maxEntityBones is the number of parts a static model can have,
+// limited by the size of the matrix array in the shader.
+const maxEntityBones = 10
+
+const entityModelExportDir = "entity_models/"
+
+// placeholderEntityModel replaces built in models that fail to
+// validate so that a broken model shows up as a box instead of
+// crashing the game.
+var placeholderEntityModel = &entityModel{
+	Textures: []entityTexture{{"missing_texture", 32, 16}},
+	Bones: []entityBone{
+		newEntityBone("body", 0, 24, 0, newEntityBox(0, 0, -4, -8, -4, 8, 8, 8)),
+	},
+}
+
+func init() {
+	console.Register("entity_model_export %", exportEntityModel)
+
+	if err := placeholderEntityModel.init(); err != nil {
+		panic(err)
+	}
+	for name, mdl := range entityModels {
+		if err := mdl.init(); err != nil {
+			console.Text("Invalid built in entity model %s: %s", name, err)
+			entityModels[name] = placeholderEntityModel
+		}
+	}
+}
+
+var entityModelCache = map[string]*entityModel{}
+
+// getEntityModel returns the named entity model. Models are
+// loaded from steven:models/entity/<name>.json allowing resource
+// packs to replace or add them, falling back to the built in
+// model if the file is missing or invalid. Built in models are
+// validated at startup. entity_model_export writes out a built
+// in model in this format.
+func getEntityModel(name string) *entityModel {
+	if mdl, ok := entityModelCache[name]; ok {
+		return mdl
+	}
+	mdl := &entityModel{}
+	err := loadJSON("steven", "models/entity/"+name+".json", mdl)
+	if err == nil {
+		err = mdl.init()
+		if err != nil {
+			console.Text("Invalid entity model %s: %s", name, err)
+		}
+	}
+	if err != nil {
+		mdl = entityModels[name]
+	}
+	entityModelCache[name] = mdl
+	return mdl
+}
+
+// exportEntityModel writes the built in version of the named
+// model as json to be used as a starting point for a resource
+// pack.
+func exportEntityModel(name string) {
+	mdl, ok := entityModels[name]
+	if !ok {
+		console.Text("Unknown entity model %s", name)
+		return
+	}
+	data, err := json.MarshalIndent(mdl, "", "  ")
+	if err != nil {
+		console.Text("Failed to export entity model: %s", err)
+		return
+	}
+	path := filepath.Join(entityModelExportDir, name+".json")
+	os.MkdirAll(filepath.Dir(path), 0777)
+	if err := ioutil.WriteFile(path, data, 0666); err != nil {
+		console.Text("Failed to export entity model: %s", err)
+		return
+	}
+	console.Text("Exported entity model %s to %s", name, path)
+}
+
+// entityModel describes a model as a set of bones each made up of
+// boxes. Positions, sizes and texture offsets use the same
+// pixel coordinates as vanilla's models (24 is the ground and
+// +Y points downwards) so they can be copied across directly.
+type entityModel struct {
+	Textures []entityTexture `json:"textures"`
+	Scale    float64         `json:"scale"`
+	Bones    []entityBone    `json:"bones"`
+
+	parents []int
+}
+
+// entityTexture is a texture used by a model. Names starting
+// with '#' are provided by the entity instead (e.g. a player's
+// skin) and are used as is.
+type entityTexture struct {
+	Name   string `json:"name"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// entityBone is a single part of a model. The pivot of a root bone
+// is relative to the model's origin, the pivot of a child is
+// relative to its parent's pivot.
+type entityBone struct {
+	Name       string            `json:"name"`
+	Parent     string            `json:"parent"`
+	Pivot      [3]float64        `json:"pivot"`
+	Rotation   [3]float64        `json:"rotation"`
+	Boxes      []entityBox       `json:"boxes"`
+	Animations []entityAnimation `json:"animations"`
+}
+
+// entityBox is a textured box within a bone. Origin and Rotation
+// are optional and are baked into the vertices of the box when
+// the model is created.
+type entityBox struct {
+	Texture  int        `json:"texture"`
+	U        int        `json:"u"`
+	V        int        `json:"v"`
+	X        float64    `json:"x"`
+	Y        float64    `json:"y"`
+	Z        float64    `json:"z"`
+	W        int        `json:"w"`
+	H        int        `json:"h"`
+	D        int        `json:"d"`
+	Inflate  float64    `json:"inflate"`
+	Mirror   bool       `json:"mirror"`
+	Origin   [3]float64 `json:"origin"`
+	Rotation [3]float64 `json:"rotation"`
+}
+
+type entityAnimationType int
+
+const (
+	// entityAnimWalk swings the bone with the walk cycle of the entity.
+	entityAnimWalk entityAnimationType = iota
+	// entityAnimLook rotates the bone with the pitch of the entity.
+	entityAnimLook
+	// entityAnimWave rotates the bone back and forth over time.
+	entityAnimWave
+	// entityAnimSpin rotates the bone continuously over time.
+	entityAnimSpin
+	// entityAnimBob moves the bone back and forth over time.
+	entityAnimBob
+)
+
+var entityAnimationNames = map[string]entityAnimationType{
+	"walk": entityAnimWalk,
+	"look": entityAnimLook,
+	"wave": entityAnimWave,
+	"spin": entityAnimSpin,
+	"bob":  entityAnimBob,
+}
+
+func (e entityAnimationType) MarshalJSON() ([]byte, error) {
+	for name, t := range entityAnimationNames {
+		if t == e {
+			return json.Marshal(name)
+		}
+	}
+	return nil, fmt.Errorf("unknown animation %d", e)
+}
+
+func (e *entityAnimationType) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err != nil {
+		return err
+	}
+	t, ok := entityAnimationNames[name]
+	if !ok {
+		return fmt.Errorf("unknown animation %q", name)
+	}
+	*e = t
+	return nil
+}
+
+type entityAxis int
+
+const (
+	entityAxisX entityAxis = iota
+	entityAxisY
+	entityAxisZ
+)
+
+func (e entityAxis) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string("xyz"[e]))
+}
+
+func (e *entityAxis) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err != nil {
+		return err
+	}
+	switch name {
+	case "x":
+		*e = entityAxisX
+	case "y":
+		*e = entityAxisY
+	case "z":
+		*e = entityAxisZ
+	default:
+		return fmt.Errorf("unknown axis %q", name)
+	}
+	return nil
+}
+
+// entityAnimation modifies the rotation (or position for bobbing)
+// of a bone about a single axis. Speed is in radians per game
+// tick.
+type entityAnimation struct {
+	Type  entityAnimationType `json:"type"`
+	Axis  entityAxis          `json:"axis"`
+	Scale float64             `json:"scale"`
+	Speed float64             `json:"speed"`
+	Phase float64             `json:"phase"`
+}
+
+// value returns the amount the animation currently applies.
+func (a entityAnimation) value(walk, pitch, age float64) float64 {
+	switch a.Type {
+	case entityAnimWalk:
+		return walk * a.Scale
+	case entityAnimLook:
+		return pitch * a.Scale
+	case entityAnimWave, entityAnimBob:
+		return math.Sin(age*a.Speed+a.Phase) * a.Scale
+	case entityAnimSpin:
+		return age*a.Speed + a.Phase
+	}
+	return 0
+}
+
+// init validates the model and resolves the parents of its
+// bones.
+func (m *entityModel) init() error {
+	if len(m.Bones) > maxEntityBones {
+		return fmt.Errorf("too many bones (%d > %d)", len(m.Bones), maxEntityBones)
+	}
+	if m.Scale == 0 {
+		m.Scale = 1
+	}
+	m.parents = make([]int, len(m.Bones))
+	for i, b := range m.Bones {
+		m.parents[i] = -1
+		if b.Parent != "" {
+			m.parents[i] = m.boneIndex(b.Parent)
+			if m.parents[i] == -1 || m.parents[i] >= i {
+				return fmt.Errorf("bone %s must come after its parent %s", b.Name, b.Parent)
+			}
+		}
+		for _, box := range b.Boxes {
+			if box.Texture < 0 || box.Texture >= len(m.Textures) {
+				return fmt.Errorf("bone %s uses missing texture %d", b.Name, box.Texture)
+			}
+		}
+	}
+	return nil
+}
+
+// boneIndex returns the index of the named bone or -1 if it
+// doesn't exist.
+func (m *entityModel) boneIndex(name string) int {
+	for i, b := range m.Bones {
+		if b.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// parts returns the vertices of each bone ready to be passed to
+// render.NewStaticModel along with the radius of the model.
+// vars provides the textures whose names start with '#'.
+func (m *entityModel) parts(vars map[string]render.TextureInfo) ([][]*render.StaticVertex, float32) {
+	textures := make([]render.TextureInfo, len(m.Textures))
+	for i, t := range m.Textures {
+		if len(t.Name) > 0 && t.Name[0] == '#' {
+			textures[i] = vars[t.Name[1:]]
+			continue
+		}
+		textures[i] = render.RelativeTexture(render.GetTexture(t.Name), t.Width, t.Height)
+	}
+
+	var radius float64
+	offsets := make([]float64, len(m.Bones))
+	parts := make([][]*render.StaticVertex, len(m.Bones))
+	for i, b := range m.Bones {
+		if p := m.parents[i]; p != -1 {
+			offsets[i] = offsets[p]
+		}
+		offsets[i] += vecLength(b.Pivot)
+		for _, box := range b.Boxes {
+			tex := textures[box.Texture]
+			if tex == nil {
+				continue
+			}
+			parts[i] = box.appendTo(parts[i], tex)
+			radius = math.Max(radius, offsets[i]+box.extent())
+		}
+	}
+	return parts, float32(1 + (radius/16)*m.Scale)
+}
+
+// entityTransform returns the matrix for the passed pivot and
+// rotation (both in model coordinates). Vanilla's models are
+// mirrored on the x axis compared to ours which flips the
+// direction of rotations around the y and z axis.
+func entityTransform(pivot, rot [3]float64) mgl32.Mat4 {
+	return mgl32.Translate3D(-float32(pivot[0]/16), float32(pivot[1]/16), float32(pivot[2]/16)).
+		Mul4(mgl32.Rotate3DZ(-float32(rot[2])).Mat4()).
+		Mul4(mgl32.Rotate3DY(-float32(rot[1])).Mat4()).
+		Mul4(mgl32.Rotate3DX(float32(rot[0])).Mat4())
+}
+
+// appendTo appends the vertices for the box to the passed slice
+// using the vanilla box texture layout.
+func (b entityBox) appendTo(verts []*render.StaticVertex, tex render.TextureInfo) []*render.StaticVertex {
+	u, v, w, h, d := b.U, b.V, b.W, b.H, b.D
+	sub := func(x, y, w, h int) render.TextureInfo {
+		if w == 0 || h == 0 {
+			return nil
+		}
+		return tex.Sub(x, y, w, h)
+	}
+	textures := [6]render.TextureInfo{
+		direction.North: sub(u+d, v+d, w, h),
+		direction.South: sub(u+d+w+d, v+d, w, h),
+		direction.West:  sub(u, v+d, d, h),
+		direction.East:  sub(u+d+w, v+d, d, h),
+		direction.Up:    sub(u+d, v, w, d),
+		direction.Down:  sub(u+d+w, v, w, d),
+	}
+	if b.Mirror {
+		textures[direction.West], textures[direction.East] = textures[direction.East], textures[direction.West]
+	}
+
+	start := len(verts)
+	i := b.Inflate
+	verts = appendBox(verts,
+		float32(-(b.X+float64(w)+i)/16), float32(-(b.Y+float64(h)+i)/16), float32((b.Z-i)/16),
+		float32((float64(w)+i*2)/16), float32((float64(h)+i*2)/16), float32((float64(d)+i*2)/16),
+		textures,
+	)
+	if b.Origin == ([3]float64{}) && b.Rotation == ([3]float64{}) {
+		return verts
+	}
+	mat := entityTransform(b.Origin, b.Rotation)
+	for _, vert := range verts[start:] {
+		p := mat.Mul4x1(mgl32.Vec4{vert.X, -vert.Y, vert.Z, 1})
+		vert.X, vert.Y, vert.Z = p.X(), -p.Y(), p.Z()
+	}
+	return verts
+}
+
+// extent returns the furthest distance of the box from its
+// bone's pivot.
+func (b entityBox) extent() float64 {
+	far := func(p float64, s int) float64 {
+		return math.Max(math.Abs(p), math.Abs(p+float64(s))) + b.Inflate
+	}
+	return vecLength(b.Origin) + vecLength([3]float64{far(b.X, b.W), far(b.Y, b.H), far(b.Z, b.D)})
+}
+
+func vecLength(v [3]float64) float64 {
+	return math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+}
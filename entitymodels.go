@@ -29,6 +29,13 @@ func init() {
 	addSystem(entitysys.Tick, esPlayerModelTick)
 	addSystem(entitysys.Remove, esPlayerModelRemove)
 
+	// These modify or attach to the positioned models so they
+	// must come after them
+	addSystem(entitysys.Tick, esHurtTick)
+	addSystem(entitysys.Tick, esEquipmentTick)
+	addSystem(entitysys.Remove, esEquipmentRemove)
+	addSystem(entitysys.Tick, esInvisibleTick)
+
 	// Generic removal
 	addSystem(entitysys.Remove, esModelRemove)
 }
@@ -78,6 +85,7 @@ func appendBoxExtra(verts []*render.StaticVertex, x, y, z, w, h, d float32, text
 
 type playerModelComponent struct {
 	model         *render.StaticModel
+	def           *entityModel
 	skin          string
 	cape          string
 	hasHead       bool
@@ -89,54 +97,72 @@ type playerModelComponent struct {
 	idleTime   float64
 	manualMove bool
 	walking    bool
+	riding     bool
 
 	armTime  float64
 	capeTime float64
 
 	heldModel *render.StaticModel
 	heldMat   mgl32.Mat4
+
+	name        string
+	nameTag     *render.StaticModel
+	nameTagText [2]string
 }
 
-func (p *playerModelComponent) Model() *render.StaticModel { return p.model }
+func (p *playerModelComponent) Model() *render.StaticModel     { return p.model }
+func (p *playerModelComponent) HeldModel() *render.StaticModel { return p.heldModel }
+
+func (p *playerModelComponent) bone(name string) (mgl32.Mat4, [3]float64, bool) {
+	if p.model == nil || (name == "head" && !p.hasHead) {
+		return mgl32.Mat4{}, [3]float64{}, false
+	}
+	for i, n := range playerModelBones {
+		if n != name {
+			continue
+		}
+		b := p.def.boneIndex(name)
+		if b == -1 {
+			break
+		}
+		return p.model.Matrix[i], p.def.Bones[b].Pivot, true
+	}
+	return mgl32.Mat4{}, [3]float64{}, false
+}
 
 func (p *playerModelComponent) SwingArm() {
 	p.armTime = 15
 }
 
+func (p *playerModelComponent) SetRiding(riding bool) {
+	p.riding = riding
+}
+
 func (p *playerModelComponent) SetCurrentItem(item *ItemStack) {
 	if p.heldModel != nil {
 		p.heldModel.Free()
+		p.heldModel = nil
 	}
 	if item == nil {
 		return
 	}
-	mdl := getModel(item.Type.Name())
-	if mdl == nil {
+	out, mat, ok := itemStaticModel(item, "thirdperson")
+	if !ok {
 		return
 	}
-
-	var blk Block
-	if bt, ok := item.Type.(*blockItem); ok {
-		blk = bt.block
-	}
-	mode := "thirdperson"
-
-	var out []*render.StaticVertex
-	if mdl.builtIn == builtInGenerated {
-		out, p.heldMat = genStaticModelFromItem(mdl, blk, mode)
-	} else if mdl.builtIn == builtInFalse {
-		out, p.heldMat = staticModelFromItem(mdl, blk, mode)
-	}
+	p.heldMat = mat
 
 	p.heldModel = render.NewStaticModel([][]*render.StaticVertex{
 		out,
 	})
 	p.heldModel.Radius = 3
+	p.heldModel.Glint[0] = item.enchanted()
 }
 
 type PlayerModelComponent interface {
 	SwingArm()
 	SetCurrentItem(item *ItemStack)
+	SetRiding(riding bool)
 }
 
 const (
@@ -147,9 +173,53 @@ const (
 	playerModelArmLeft
 	playerModelArmRight
 	playerModelCape
-	playerModelNameTag
 )
 
+// playerModelBones maps the parts of the player model to the
+// bones of the player's entity model.
+var playerModelBones = [...]string{
+	playerModelHead:     "head",
+	playerModelBody:     "body",
+	playerModelLegLeft:  "leg_left",
+	playerModelLegRight: "leg_right",
+	playerModelArmLeft:  "arm_left",
+	playerModelArmRight: "arm_right",
+	playerModelCape:     "cape",
+}
+
+// playerEntityModel returns the built in model for players. The
+// animation of the player is handled by esPlayerModelTick so
+// the pivots here only match the positions it uses.
+func playerEntityModel() *entityModel {
+	limb := func(name string, x, y float64, u, v, ou, ov int) entityBone {
+		return newEntityBone(name, x, y, 0,
+			newEntityBox(u, v, -2, 0, -2, 4, 12, 4),
+			newEntityBox(ou, ov, -2, 0, -2, 4, 12, 4).grow(0.2),
+		)
+	}
+	return &entityModel{
+		Textures: []entityTexture{{"#skin", 64, 64}, {"#cape", 64, 32}},
+		Bones: []entityBone{
+			newEntityBone("head", 0, 0, 0,
+				newEntityBox(0, 0, -4, -8, -4, 8, 8, 8),
+				newEntityBox(32, 0, -4, -8, -4, 8, 8, 8).grow(0.2),
+			),
+			newEntityBone("body", 0, 6, 0,
+				newEntityBox(16, 16, -4, -6, -2, 8, 12, 4),
+				newEntityBox(16, 32, -4, -6, -2, 8, 12, 4).grow(0.2),
+			),
+			limb("leg_left", 2, 12, 16, 48, 0, 48),
+			limb("leg_right", -2, 12, 0, 16, 0, 32),
+			limb("arm_left", 6, 0, 40, 16, 40, 32),
+			limb("arm_right", -6, 0, 32, 48, 48, 48),
+			// Vanilla's cape is turned around to face backwards
+			newEntityBone("cape", 0, 0, 2,
+				newEntityBox(0, 0, -5, 0, -1, 10, 16, 1).texture(1).rotated(0, math.Pi, 0),
+			),
+		},
+	}
+}
+
 func esPlayerModelAdd(p *playerModelComponent, pl PlayerComponent) {
 	uuid := pl.UUID()
 	info := Client.playerList.info[uuid]
@@ -168,146 +238,119 @@ func esPlayerModelAdd(p *playerModelComponent, pl PlayerComponent) {
 		render.RefSkin(p.cape)
 	}
 
-	var hverts []*render.StaticVertex
-	if p.hasHead {
-		hverts = appendBox(hverts, -4/16.0, 0, -4/16.0, 8/16.0, 8/16.0, 8/16.0, [6]render.TextureInfo{
-			direction.North: skin.Sub(8, 8, 8, 8),
-			direction.South: skin.Sub(24, 8, 8, 8),
-			direction.East:  skin.Sub(0, 8, 8, 8),
-			direction.West:  skin.Sub(16, 8, 8, 8),
-			direction.Up:    skin.Sub(8, 0, 8, 8),
-			direction.Down:  skin.Sub(16, 0, 8, 8),
-		})
-		hverts = appendBox(hverts, -4.2/16.0, -.2/16.0, -4.2/16.0, 8.4/16.0, 8.4/16.0, 8.4/16.0, [6]render.TextureInfo{
-			direction.North: skin.Sub(8+32, 8, 8, 8),
-			direction.South: skin.Sub(24+32, 8, 8, 8),
-			direction.East:  skin.Sub(0+32, 8, 8, 8),
-			direction.West:  skin.Sub(16+32, 8, 8, 8),
-			direction.Up:    skin.Sub(8+32, 0, 8, 8),
-			direction.Down:  skin.Sub(16+32, 0, 8, 8),
-		})
-	}
-
-	var cverts []*render.StaticVertex
-	if p.cape != "" {
-		cverts = appendBox(cverts, -5/16.0, -16/16.0, 0, 10/16.0, 16/16.0, 1/16.0, [6]render.TextureInfo{
-			direction.North: cape.Sub(11, 1, 10, 16),
-			direction.South: cape.Sub(1, 1, 10, 16),
-			direction.West:  cape.Sub(0, 1, 1, 16),
-			direction.East:  cape.Sub(21, 1, 1, 16),
-			direction.Up:    cape.Sub(1, 0, 10, 1),
-			direction.Down:  cape.Sub(11, 0, 10, 1),
-		})
-	}
-
-	bverts := appendBox(nil, -4/16.0, -6/16.0, -2/16.0, 8/16.0, 12/16.0, 4/16.0, [6]render.TextureInfo{
-		direction.North: skin.Sub(20, 20, 8, 12),
-		direction.South: skin.Sub(32, 20, 8, 12),
-		direction.West:  skin.Sub(16, 20, 4, 12),
-		direction.East:  skin.Sub(28, 20, 4, 12),
-		direction.Up:    skin.Sub(20, 16, 8, 4),
-		direction.Down:  skin.Sub(28, 16, 8, 4),
-	})
-	bverts = appendBox(bverts, -4.2/16.0, -6.2/16.0, -2.2/16.0, 8.4/16.0, 12.4/16.0, 4.4/16.0, [6]render.TextureInfo{
-		direction.North: skin.Sub(20, 20+16, 8, 12),
-		direction.South: skin.Sub(32, 20+16, 8, 12),
-		direction.West:  skin.Sub(16, 20+16, 4, 12),
-		direction.East:  skin.Sub(28, 20+16, 4, 12),
-		direction.Up:    skin.Sub(20, 16+16, 8, 4),
-		direction.Down:  skin.Sub(28, 16+16, 8, 4),
+	def := getEntityModel("player")
+	if def == nil {
+		Client.network.SignalClose(errors.New("missing player model"))
+		return
+	}
+	p.def = def
+	verts, _ := def.parts(map[string]render.TextureInfo{
+		"skin": skin,
+		"cape": cape,
 	})
+	parts := make([][]*render.StaticVertex, playerModelCape+1)
+	for i, name := range playerModelBones {
+		if b := def.boneIndex(name); b != -1 {
+			parts[i] = verts[b]
+		}
+	}
+	if !p.hasHead {
+		parts[playerModelHead] = nil
+	}
 
-	var lverts [4][]*render.StaticVertex
-
-	for i, off := range [][4]int{
-		{0, 16, 0, 32},
-		{16, 48, 0, 48},
-		{32, 48, 48, 48},
-		{40, 16, 40, 32},
-	} {
-		ox, oy := off[0], off[1]
-		lverts[i] = appendBox(nil, -2/16.0, -12/16.0, -2/16.0, 4/16.0, 12/16.0, 4/16.0, [6]render.TextureInfo{
-			direction.North: skin.Sub(ox+4, oy+4, 4, 12),
-			direction.South: skin.Sub(ox+12, oy+4, 4, 12),
-			direction.West:  skin.Sub(ox+0, oy+4, 4, 12),
-			direction.East:  skin.Sub(ox+8, oy+4, 4, 12),
-			direction.Up:    skin.Sub(ox+4, oy, 4, 4),
-			direction.Down:  skin.Sub(ox+8, oy, 4, 4),
-		})
-		ox, oy = off[2], off[3]
-		lverts[i] = appendBox(lverts[i], -2.2/16.0, -12.2/16.0, -2.2/16.0, 4.4/16.0, 12.4/16.0, 4.4/16.0, [6]render.TextureInfo{
-			direction.North: skin.Sub(ox+4, oy+4, 4, 12),
-			direction.South: skin.Sub(ox+12, oy+4, 4, 12),
-			direction.West:  skin.Sub(ox+0, oy+4, 4, 12),
-			direction.East:  skin.Sub(ox+8, oy+4, 4, 12),
-			direction.Up:    skin.Sub(ox+4, oy, 4, 4),
-			direction.Down:  skin.Sub(ox+8, oy, 4, 4),
-		})
-	}
-
-	var nverts []*render.StaticVertex
-	if p.hasNameTag {
-		nverts = createNameTag(info.name)
-	}
-
-	model := render.NewStaticModel([][]*render.StaticVertex{
-		playerModelHead:     hverts,
-		playerModelBody:     bverts,
-		playerModelLegRight: lverts[0],
-		playerModelLegLeft:  lverts[1],
-		playerModelArmRight: lverts[2],
-		playerModelArmLeft:  lverts[3],
-		playerModelCape:     cverts,
-		playerModelNameTag:  nverts,
-	})
+	p.name = info.name
+
+	model := render.NewStaticModel(parts)
 	p.model = model
 	model.Radius = 3
 }
 
-func createNameTag(name string) (verts []*render.StaticVertex) {
-	width := render.SizeOfString(name) + 4
-	offset := -(width/2)*0.01 + (2 * 0.01)
-	for _, r := range name {
-		tex := render.CharacterTexture(r)
-		if tex == nil {
+// createNameTag creates a name tag model containing each of the
+// passed lines with the first line at the top. Legacy color
+// codes in the lines are respected.
+func createNameTag(lines ...string) (verts []*render.StaticVertex) {
+	for i, line := range lines {
+		if line == "" {
 			continue
 		}
-		s := render.SizeOfCharacter(r)
-		for _, v := range faceVertices[direction.North].verts {
-			vert := &render.StaticVertex{
-				X:        float32(v.X)*float32(s*0.01) - float32(offset+s*0.01) - 0.01,
-				Y:        float32(v.Y)*0.16 - 0.08 - 0.01,
-				Z:        0.05,
-				Texture:  tex,
-				TextureX: float64(v.TOffsetX),
-				TextureY: float64(v.TOffsetY),
-				R:        64,
-				G:        64,
-				B:        64,
-				A:        255,
+		y := float32(len(lines)-1-i) * 0.25
+		width := render.SizeOfString(stripLegacy(line)) + 4
+		offset := -(width/2)*0.01 + (2 * 0.01)
+		var rr, gg, bb byte = 255, 255, 255
+		text := []rune(line)
+		for j := 0; j < len(text); j++ {
+			r := text[j]
+			if r == '§' && j+1 < len(text) {
+				j++
+				rr, gg, bb = legacyColor(text[j], rr, gg, bb)
+				continue
 			}
-			verts = append(verts, vert)
-		}
-		for _, v := range faceVertices[direction.North].verts {
-			vert := &render.StaticVertex{
-				X:        float32(v.X)*float32(s*0.01) - float32(offset+s*0.01),
-				Y:        float32(v.Y)*0.16 - 0.08,
-				Z:        0,
-				Texture:  tex,
-				TextureX: float64(v.TOffsetX),
-				TextureY: float64(v.TOffsetY),
-				R:        255,
-				G:        255,
-				B:        255,
-				A:        255,
+			tex := render.CharacterTexture(r)
+			if tex == nil {
+				continue
 			}
-			verts = append(verts, vert)
+			s := render.SizeOfCharacter(r)
+			for _, v := range faceVertices[direction.North].verts {
+				vert := &render.StaticVertex{
+					X:        float32(v.X)*float32(s*0.01) - float32(offset+s*0.01) - 0.01,
+					Y:        float32(v.Y)*0.16 - 0.08 - 0.01 + y,
+					Z:        0.05,
+					Texture:  tex,
+					TextureX: float64(v.TOffsetX),
+					TextureY: float64(v.TOffsetY),
+					R:        rr / 4,
+					G:        gg / 4,
+					B:        bb / 4,
+					A:        255,
+				}
+				verts = append(verts, vert)
+			}
+			for _, v := range faceVertices[direction.North].verts {
+				vert := &render.StaticVertex{
+					X:        float32(v.X)*float32(s*0.01) - float32(offset+s*0.01),
+					Y:        float32(v.Y)*0.16 - 0.08 + y,
+					Z:        0,
+					Texture:  tex,
+					TextureX: float64(v.TOffsetX),
+					TextureY: float64(v.TOffsetY),
+					R:        rr,
+					G:        gg,
+					B:        bb,
+					A:        255,
+				}
+				verts = append(verts, vert)
+			}
+			offset += (s + 2) * 0.01
 		}
-		offset += (s + 2) * 0.01
 	}
 	return verts
 }
 
+// updateNameTag rebuilds the name tag model if the team or
+// scoreboard state of the player has changed.
+func (p *playerModelComponent) updateNameTag() {
+	var text [2]string
+	if Client.scoreboard.nameTagVisible(p.name) {
+		text[0] = Client.scoreboard.legacyName(p.name)
+		text[1] = Client.scoreboard.belowName(p.name)
+	}
+	if p.nameTag != nil && text == p.nameTagText {
+		return
+	}
+	p.nameTagText = text
+	if p.nameTag != nil {
+		p.nameTag.Free()
+		p.nameTag = nil
+	}
+	if text[0] == "" {
+		return
+	}
+	p.nameTag = render.NewStaticModel([][]*render.StaticVertex{
+		createNameTag(text[0], text[1]),
+	})
+	p.nameTag.Radius = 3
+}
+
 func esPlayerModelRemove(p *playerModelComponent) {
 	if p.skin != "" {
 		render.FreeSkin(p.skin)
@@ -318,6 +361,9 @@ func esPlayerModelRemove(p *playerModelComponent) {
 	if p.heldModel != nil {
 		p.heldModel.Free()
 	}
+	if p.nameTag != nil {
+		p.nameTag.Free()
+	}
 }
 
 func esModelRemove(p interface {
@@ -364,8 +410,13 @@ func esPlayerModelTick(p *playerModelComponent,
 
 	// TODO This isn't the most optimal way of doing this
 	if p.hasNameTag {
+		p.updateNameTag()
+	}
+	if p.nameTag != nil {
 		val := math.Atan2(x-render.Camera.X, z-render.Camera.Z)
-		model.Matrix[playerModelNameTag] = mgl32.Translate3D(float32(x), -float32(y), float32(z)).
+		p.nameTag.X, p.nameTag.Y, p.nameTag.Z = -float32(x), -float32(y), float32(z)
+		p.nameTag.BlockLight, p.nameTag.SkyLight = p.model.BlockLight, p.model.SkyLight
+		p.nameTag.Matrix[0] = mgl32.Translate3D(float32(x), -float32(y), float32(z)).
 			Mul4(mgl32.Translate3D(0, -12/16.0-12/16.0-0.6, 0)).
 			Mul4(mgl32.Rotate3DY(float32(val)).Mat4())
 	}
@@ -382,10 +433,20 @@ func esPlayerModelTick(p *playerModelComponent,
 	}
 	ang := ((time / 15) - 1) * (math.Pi / 4)
 
-	model.Matrix[playerModelLegRight] = offMat.Mul4(mgl32.Translate3D(2/16.0, -12/16.0, 0)).
-		Mul4(mgl32.Rotate3DX(float32(ang)).Mat4())
-	model.Matrix[playerModelLegLeft] = offMat.Mul4(mgl32.Translate3D(-2/16.0, -12/16.0, 0)).
-		Mul4(mgl32.Rotate3DX(-float32(ang)).Mat4())
+	if p.riding {
+		// Sat down with the legs spread around the vehicle
+		model.Matrix[playerModelLegRight] = offMat.Mul4(mgl32.Translate3D(2/16.0, -12/16.0, 0)).
+			Mul4(mgl32.Rotate3DY(-math.Pi / 10).Mat4()).
+			Mul4(mgl32.Rotate3DX(-1.4).Mat4())
+		model.Matrix[playerModelLegLeft] = offMat.Mul4(mgl32.Translate3D(-2/16.0, -12/16.0, 0)).
+			Mul4(mgl32.Rotate3DY(math.Pi / 10).Mat4()).
+			Mul4(mgl32.Rotate3DX(-1.4).Mat4())
+	} else {
+		model.Matrix[playerModelLegRight] = offMat.Mul4(mgl32.Translate3D(2/16.0, -12/16.0, 0)).
+			Mul4(mgl32.Rotate3DX(float32(ang)).Mat4())
+		model.Matrix[playerModelLegLeft] = offMat.Mul4(mgl32.Translate3D(-2/16.0, -12/16.0, 0)).
+			Mul4(mgl32.Rotate3DX(-float32(ang)).Mat4())
+	}
 
 	iTime := p.idleTime
 	iTime += Client.delta * 0.02
@@ -421,8 +482,16 @@ func esPlayerModelTick(p *playerModelComponent,
 		Mul4(mgl32.Rotate3DZ(-float32(math.Cos(iTime)*0.06) + 0.06).Mat4()).
 		Mul4(mgl32.Rotate3DX(-float32(math.Sin(iTime) * 0.06)).Mat4())
 
+	moving := !((!p.manualMove && t.X == t.sX && t.Y == t.sY && t.Z == t.sZ) || (p.manualMove && !p.walking))
+	p.time, p.dir = stepWalk(t, time, dir, moving)
+}
+
+// stepWalk advances the walk cycle of an entity. Once the
+// entity stops moving the cycle is returned to its center
+// before stopping.
+func stepWalk(t *targetPositionComponent, time, dir float64, moving bool) (float64, float64) {
 	update := true
-	if (!p.manualMove && t.X == t.sX && t.Y == t.sY && t.Z == t.sZ) || (p.manualMove && !p.walking) {
+	if !moving {
 		if t.stillTime > 5.0 {
 			if math.Abs(time-15) <= 1.5*Client.delta {
 				time = 15
@@ -446,6 +515,5 @@ func esPlayerModelTick(p *playerModelComponent,
 			dir = 1
 		}
 	}
-	p.dir = dir
-	p.time = time
+	return time, dir
 }
@@ -0,0 +1,206 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/thinkofdeath/steven/entitysys"
+	"github.com/thinkofdeath/steven/protocol"
+	"github.com/thinkofdeath/steven/render"
+	"github.com/thinkofdeath/steven/type/direction"
+)
+
+func init() {
+	addSystem(entitysys.Tick, esItemModelTick)
+	addSystem(entitysys.Add, esBlockModelAdd)
+	addSystem(entitysys.Tick, esBlockModelTick)
+	addSystem(entitysys.Add, esThrownItemAdd)
+	addSystem(entitysys.Tick, esThrownItemTick)
+	addSystem(entitysys.Add, esExperienceOrbAdd)
+	addSystem(entitysys.Tick, esExperienceOrbTick)
+}
+
+// Dropped items
+
+type itemModelComponent struct {
+	model *render.StaticModel
+	mat   mgl32.Mat4
+	age   float64
+	// offset stops items dropped at the same time from
+	// spinning in sync
+	offset float64
+}
+
+func (i *itemModelComponent) Model() *render.StaticModel { return i.model }
+
+// SetMetadata updates the model of the item to match the item
+// stack in the metadata.
+func (i *itemModelComponent) SetMetadata(m protocol.Metadata) {
+	it, ok := m[10].(protocol.ItemStack)
+	if !ok {
+		return
+	}
+	if i.model != nil {
+		i.model.Free()
+		i.model = nil
+	}
+	item := ItemStackFromProtocol(it)
+	if item == nil {
+		return
+	}
+	out, mat, ok := itemStaticModel(item, "ground")
+	if !ok {
+		return
+	}
+	i.mat = mat
+	i.model = render.NewStaticModel([][]*render.StaticVertex{
+		out,
+	})
+	i.model.Radius = 1
+}
+
+func esItemModelTick(i *itemModelComponent, p PositionComponent) {
+	// Age is in game ticks to match vanilla
+	i.age += Client.delta / 3
+	if i.model == nil {
+		return
+	}
+	x, y, z := p.Position()
+	i.model.X, i.model.Y, i.model.Z = -float32(x), -float32(y), float32(z)
+
+	bob := math.Sin(i.age/10+i.offset)*0.1 + 0.1
+	i.model.Matrix[0] = mgl32.Translate3D(float32(x), -float32(y+bob+0.25), float32(z)).
+		Mul4(mgl32.Rotate3DY(float32(i.age/20 + i.offset)).Mat4()).
+		Mul4(i.mat)
+}
+
+// Blocks
+
+type blockModelComponent struct {
+	model *render.StaticModel
+	block Block
+}
+
+func (b *blockModelComponent) Model() *render.StaticModel { return b.model }
+
+func esBlockModelAdd(b *blockModelComponent) {
+	variants := b.block.Models()
+	if len(variants) == 0 || variants[0] == nil {
+		return
+	}
+	b.model = render.NewStaticModel([][]*render.StaticVertex{
+		staticModelFromProcessed(variants[0], b.block),
+	})
+	b.model.Radius = 1.5
+}
+
+func esBlockModelTick(b *blockModelComponent, p PositionComponent) {
+	if b.model == nil {
+		return
+	}
+	x, y, z := p.Position()
+	b.model.X, b.model.Y, b.model.Z = -float32(x), -float32(y), float32(z)
+	b.model.Matrix[0] = mgl32.Translate3D(float32(x), -float32(y+0.5), float32(z))
+}
+
+// Projectiles
+
+type thrownItemComponent struct {
+	model *render.StaticModel
+	mat   mgl32.Mat4
+	name  string
+}
+
+func (t *thrownItemComponent) Model() *render.StaticModel { return t.model }
+
+func esThrownItemAdd(t *thrownItemComponent) {
+	mdl := getModel(t.name)
+	if mdl == nil {
+		return
+	}
+	var out []*render.StaticVertex
+	if mdl.builtIn == builtInGenerated {
+		out, t.mat = genStaticModelFromItem(mdl, nil, "ground")
+	} else {
+		out, t.mat = staticModelFromItem(mdl, nil, "ground")
+	}
+	t.model = render.NewStaticModel([][]*render.StaticVertex{
+		out,
+	})
+	t.model.Radius = 1
+}
+
+func esThrownItemTick(t *thrownItemComponent, p PositionComponent) {
+	if t.model == nil {
+		return
+	}
+	x, y, z := p.Position()
+	t.model.X, t.model.Y, t.model.Z = -float32(x), -float32(y), float32(z)
+	// Always faces the camera
+	t.model.Matrix[0] = mgl32.Translate3D(float32(x), -float32(y+0.125), float32(z)).
+		Mul4(mgl32.Rotate3DY(math.Pi + float32(Client.Yaw)).Mat4()).
+		Mul4(t.mat)
+}
+
+// Experience orbs
+
+type experienceOrbComponent struct {
+	model *render.StaticModel
+	count int
+	age   float64
+}
+
+func (e *experienceOrbComponent) Model() *render.StaticModel { return e.model }
+
+// experienceOrbSizes are the minimum amount of experience needed
+// for each size of orb.
+var experienceOrbSizes = [...]int{3, 7, 17, 37, 73, 149, 307, 617, 1237, 2477}
+
+func esExperienceOrbAdd(e *experienceOrbComponent) {
+	size := 0
+	for i, min := range experienceOrbSizes {
+		if e.count >= min {
+			size = i + 1
+		}
+	}
+	tex := render.RelativeTexture(render.GetTexture("entity/experience_orb"), 64, 64).
+		Sub((size%4)*16, (size/4)*16, 16, 16)
+	var textures [6]render.TextureInfo
+	textures[direction.North] = tex
+	textures[direction.South] = tex
+	e.model = render.NewStaticModel([][]*render.StaticVertex{
+		appendBox(nil, -0.15, -0.15, 0, 0.3, 0.3, 0, textures),
+	})
+	e.model.Radius = 1
+}
+
+func esExperienceOrbTick(e *experienceOrbComponent, p PositionComponent) {
+	e.age += Client.delta / 3
+	x, y, z := p.Position()
+	e.model.X, e.model.Y, e.model.Z = -float32(x), -float32(y), float32(z)
+	e.model.Matrix[0] = mgl32.Translate3D(float32(x), -float32(y+0.15), float32(z)).
+		Mul4(mgl32.Rotate3DY(math.Pi + float32(Client.Yaw)).Mat4())
+
+	// Pulses between green and yellow
+	t := e.age / 2
+	e.model.Colors[0] = [4]float32{
+		float32(math.Sin(t)+1) * 0.5,
+		1.0,
+		float32(math.Sin(t+4.1887903)+1) * 0.1,
+		1.0,
+	}
+}
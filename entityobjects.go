@@ -0,0 +1,199 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/thinkofdeath/steven/type/vmath"
+)
+
+func newBoat(data int) Entity {
+	type boat struct {
+		networkComponent
+		positionComponent
+		rotationComponent
+		targetRotationComponent
+		targetPositionComponent
+		sizeComponent
+		vehicleComponent
+
+		mobModelComponent
+	}
+	b := &boat{
+		mobModelComponent: mobModelComponent{name: "boat"},
+	}
+	b.NetworkID = 1
+	b.bounds = vmath.NewAABB(-0.75, 0, -0.75, 1.5, 0.6, 1.5)
+	b.mountOffset = -0.3
+	return b
+}
+
+func newItem(data int) Entity {
+	type item struct {
+		networkComponent
+		positionComponent
+		rotationComponent
+		targetRotationComponent
+		targetPositionComponent
+		sizeComponent
+		velocityComponent
+		pickupComponent
+
+		itemModelComponent
+	}
+	i := &item{}
+	i.NetworkID = 2
+	i.bounds = vmath.NewAABB(-0.125, 0, -0.125, 0.25, 0.25, 0.25)
+	i.gravity, i.drag = 0.04, 0.02
+	i.offset = rand.Float64() * math.Pi * 2
+	return i
+}
+
+// newMinecart returns a minecart, data is the type of minecart
+// however they all share the same model currently.
+func newMinecart(data int) Entity {
+	type minecart struct {
+		networkComponent
+		positionComponent
+		rotationComponent
+		targetRotationComponent
+		targetPositionComponent
+		sizeComponent
+		vehicleComponent
+
+		mobModelComponent
+	}
+	m := &minecart{
+		mobModelComponent: mobModelComponent{name: "minecart"},
+	}
+	m.NetworkID = 10
+	m.bounds = vmath.NewAABB(-0.49, 0, -0.49, 0.98, 0.7, 0.98)
+	m.mountOffset = 0.15
+	return m
+}
+
+func newPrimedTNT(data int) Entity {
+	type primedTNT struct {
+		networkComponent
+		positionComponent
+		rotationComponent
+		targetRotationComponent
+		targetPositionComponent
+		sizeComponent
+		velocityComponent
+
+		blockModelComponent
+	}
+	t := &primedTNT{
+		blockModelComponent: blockModelComponent{block: Blocks.TNT.Base},
+	}
+	t.NetworkID = 50
+	t.bounds = vmath.NewAABB(-0.49, 0, -0.49, 0.98, 0.98, 0.98)
+	t.gravity, t.drag = 0.04, 0.02
+	return t
+}
+
+func newArrow(data int) Entity {
+	type arrow struct {
+		networkComponent
+		positionComponent
+		rotationComponent
+		targetRotationComponent
+		targetPositionComponent
+		sizeComponent
+		velocityComponent
+		pickupComponent
+
+		mobModelComponent
+	}
+	a := &arrow{
+		mobModelComponent: mobModelComponent{name: "arrow"},
+	}
+	a.NetworkID = 60
+	a.bounds = vmath.NewAABB(-0.25, 0, -0.25, 0.5, 0.5, 0.5)
+	a.gravity, a.drag = 0.05, 0.01
+	return a
+}
+
+// newThrownItem returns a constructor for projectiles that are
+// drawn as the named item.
+func newThrownItem(name string) func(data int) Entity {
+	return func(data int) Entity {
+		type thrownItem struct {
+			networkComponent
+			positionComponent
+			rotationComponent
+			targetRotationComponent
+			targetPositionComponent
+			sizeComponent
+			velocityComponent
+
+			thrownItemComponent
+		}
+		t := &thrownItem{
+			thrownItemComponent: thrownItemComponent{name: name},
+		}
+		t.bounds = vmath.NewAABB(-0.125, 0, -0.125, 0.25, 0.25, 0.25)
+		t.gravity, t.drag = 0.03, 0.01
+		return t
+	}
+}
+
+// newFallingBlock returns a falling block, data contains the
+// id of the block in the lower 12 bits and its data value in
+// the upper 4.
+func newFallingBlock(data int) Entity {
+	type fallingBlock struct {
+		networkComponent
+		positionComponent
+		rotationComponent
+		targetRotationComponent
+		targetPositionComponent
+		sizeComponent
+		velocityComponent
+
+		blockModelComponent
+	}
+	f := &fallingBlock{
+		blockModelComponent: blockModelComponent{
+			block: GetBlockByCombinedID(uint16((data&0xFFF)<<4 | (data>>12)&0xF)),
+		},
+	}
+	f.NetworkID = 70
+	f.bounds = vmath.NewAABB(-0.49, 0, -0.49, 0.98, 0.98, 0.98)
+	f.gravity, f.drag = 0.04, 0.02
+	return f
+}
+
+func newExperienceOrb(count int) Entity {
+	type experienceOrb struct {
+		networkComponent
+		positionComponent
+		targetPositionComponent
+		sizeComponent
+		velocityComponent
+		pickupComponent
+
+		experienceOrbComponent
+	}
+	e := &experienceOrb{
+		experienceOrbComponent: experienceOrbComponent{count: count},
+	}
+	e.bounds = vmath.NewAABB(-0.25, 0, -0.25, 0.5, 0.5, 0.5)
+	e.gravity, e.drag = 0.03, 0.02
+	return e
+}
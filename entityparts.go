@@ -140,6 +140,52 @@ type SizeComponent interface {
 	Bounds() vmath.AABB
 }
 
+// Velocity
+
+type velocityComponent struct {
+	VX, VY, VZ float64
+	// gravity and drag are applied every game tick
+	gravity, drag float64
+}
+
+func (v *velocityComponent) Velocity() (x, y, z float64) {
+	return v.VX, v.VY, v.VZ
+}
+
+func (v *velocityComponent) SetVelocity(x, y, z float64) {
+	v.VX, v.VY, v.VZ = x, y, z
+}
+
+type VelocityComponent interface {
+	Velocity() (x, y, z float64)
+	SetVelocity(x, y, z float64)
+}
+
+// Vehicle
+
+type vehicleComponent struct {
+	// mountOffset is the height passengers sit at above the
+	// vehicle's position
+	mountOffset float64
+	// chargedJump is set for vehicles that jump using the
+	// jump bar instead of whilst jump is held
+	chargedJump bool
+}
+
+func (v *vehicleComponent) MountOffset() float64 { return v.mountOffset }
+func (v *vehicleComponent) ChargedJump() bool    { return v.chargedJump }
+
+type VehicleComponent interface {
+	MountOffset() float64
+	ChargedJump() bool
+}
+
+// Metadata
+
+type MetadataComponent interface {
+	SetMetadata(m protocol.Metadata)
+}
+
 // Player
 
 type playerComponent struct {
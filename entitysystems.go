@@ -20,9 +20,12 @@ import (
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/thinkofdeath/steven/entitysys"
 	"github.com/thinkofdeath/steven/render"
+	"github.com/thinkofdeath/steven/type/vmath"
 )
 
 func init() {
+	addSystem(entitysys.Tick, esVelocity)
+	addSystem(entitysys.Tick, esPickup)
 	addSystem(entitysys.Tick, esMoveToTarget)
 	addSystem(entitysys.Tick, esRotateToTarget)
 	addSystem(entitysys.Tick, esDrawOutline)
@@ -128,6 +131,105 @@ func esMoveToTarget(p PositionComponent, t *targetPositionComponent) {
 	p.SetPosition(px, py, pz)
 }
 
+// Moves the entity by its velocity between updates from the
+// server, stopping it when it hits a block.
+func esVelocity(t *targetPositionComponent, s SizeComponent, v *velocityComponent) {
+	ticks := Client.delta / 3
+	x, y, z := t.TargetPosition()
+	bounds := s.Bounds()
+
+	v.VY -= v.gravity * ticks
+	nx, ny, nz := x+v.VX*ticks, y+v.VY*ticks, z+v.VZ*ticks
+	if collidesAt(bounds, x, ny, z) {
+		ny, v.VY = y, 0
+		// Slide to a stop along the ground
+		v.VX *= math.Pow(0.5, ticks)
+		v.VZ *= math.Pow(0.5, ticks)
+	}
+	if collidesAt(bounds, nx, ny, z) {
+		nx, v.VX = x, 0
+	}
+	if collidesAt(bounds, nx, ny, nz) {
+		nz, v.VZ = z, 0
+	}
+	if nx != x || ny != y || nz != z {
+		t.SetTargetPosition(nx, ny, nz)
+	}
+
+	drag := math.Pow(1-v.drag, ticks)
+	v.VX *= drag
+	v.VY *= drag
+	v.VZ *= drag
+}
+
+// collidesAt returns whether the bounds would hit a block at the
+// passed position.
+func collidesAt(bounds vmath.AABB, x, y, z float64) bool {
+	bounds = bounds.Shift(float32(x), float32(y), float32(z))
+	minX, minY, minZ := int(math.Floor(float64(bounds.Min.X()))), int(math.Floor(float64(bounds.Min.Y()))), int(math.Floor(float64(bounds.Min.Z())))
+	maxX, maxY, maxZ := int(math.Floor(float64(bounds.Max.X()))), int(math.Floor(float64(bounds.Max.Y()))), int(math.Floor(float64(bounds.Max.Z())))
+	for by := minY; by <= maxY; by++ {
+		for bz := minZ; bz <= maxZ; bz++ {
+			for bx := minX; bx <= maxX; bx++ {
+				b := chunkMap.Block(bx, by, bz)
+				if !b.Collidable() {
+					continue
+				}
+				for _, bb := range b.CollisionBounds() {
+					if bb.Shift(float32(bx), float32(by), float32(bz)).Intersects(bounds) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// pickupDuration is how long the pickup animation lasts
+const pickupDuration = 3 * 3
+
+type pickupComponent struct {
+	collector  PositionComponent
+	sX, sY, sZ float64
+	time       float64
+}
+
+// Pickup starts moving the entity towards the entity that
+// collected it.
+func (p *pickupComponent) Pickup(by PositionComponent) {
+	p.collector = by
+}
+
+func (p *pickupComponent) finished() bool {
+	return p.collector != nil && p.time >= pickupDuration
+}
+
+type PickupComponent interface {
+	Pickup(by PositionComponent)
+}
+
+// Moves collected entities into the entity that picked them up
+func esPickup(p PositionComponent, t *targetPositionComponent, pc *pickupComponent) {
+	if pc.collector == nil {
+		return
+	}
+	if pc.time == 0 {
+		pc.sX, pc.sY, pc.sZ = p.Position()
+	}
+	pc.time = math.Min(pickupDuration, pc.time+Client.delta)
+	cx, cy, cz := pc.collector.Position()
+	cy += 0.5
+
+	f := pc.time / pickupDuration
+	f *= f
+	x := pc.sX + (cx-pc.sX)*f
+	y := pc.sY + (cy-pc.sY)*f
+	z := pc.sZ + (cz-pc.sZ)*f
+	p.SetPosition(x, y, z)
+	t.SetTargetPosition(x, y, z)
+}
+
 // Smoothly rotates the entity from its current rotation to the target
 // rotation
 func esRotateToTarget(r RotationComponent, t *targetRotationComponent) {
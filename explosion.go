@@ -0,0 +1,130 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/thinkofdeath/steven/render"
+	"github.com/thinkofdeath/steven/type/direction"
+)
+
+// explosionEffect is a short lived billboard that plays the
+// explosion animation at a location in the world.
+type explosionEffect struct {
+	model   *render.StaticModel
+	x, y, z float64
+	size    float64
+	time    float64
+	life    float64
+}
+
+// The explosion texture is a 4x4 grid of animation frames
+const explosionFrames = 16
+
+func newExplosionEffect(x, y, z, size float64) *explosionEffect {
+	e := &explosionEffect{
+		x: x, y: y, z: z,
+		size: size,
+		// Between 6 and 10 ticks like vanilla
+		life: (6 + rand.Float64()*4) * 3,
+	}
+	shade := byte(255 * (0.6 + rand.Float64()*0.4))
+	var verts []*render.StaticVertex
+	for _, v := range faceVertices[direction.North].verts {
+		verts = append(verts, &render.StaticVertex{
+			X:        float32(v.X)*float32(size) - float32(size/2),
+			Y:        float32(v.Y)*float32(size) - float32(size/2),
+			Z:        0,
+			TextureX: float64(v.TOffsetX),
+			TextureY: float64(v.TOffsetY),
+			R:        shade,
+			G:        shade,
+			B:        shade,
+			A:        255,
+		})
+	}
+	e.setFrame(verts, 0)
+	e.model = render.NewStaticModel([][]*render.StaticVertex{verts})
+	e.model.Radius = float32(size)
+	e.model.X, e.model.Y, e.model.Z = -float32(x), -float32(y), float32(z)
+	e.model.BlockLight, e.model.SkyLight = 15, 15
+	return e
+}
+
+func (e *explosionEffect) setFrame(verts []*render.StaticVertex, frame int) {
+	tex := render.RelativeTexture(render.GetTexture("entity/explosion"), 64, 64).
+		Sub((frame%4)*16, (frame/4)*16, 16, 16)
+	for _, v := range verts {
+		v.Texture = tex
+	}
+}
+
+// tick advances the animation returning false once the
+// effect has finished.
+func (e *explosionEffect) tick(delta float64) bool {
+	e.time += delta
+	if e.time >= e.life {
+		return false
+	}
+	frame := int((e.time / e.life) * explosionFrames)
+	e.setFrame(e.model.Verts, frame)
+	e.model.Refresh()
+
+	// Face the camera
+	yaw := math.Atan2(e.x-render.Camera.X, e.z-render.Camera.Z)
+	e.model.Matrix[0] = mgl32.Translate3D(float32(e.x), -float32(e.y), float32(e.z)).
+		Mul4(mgl32.Rotate3DY(float32(yaw)).Mat4())
+	return true
+}
+
+func (e *explosionEffect) free() {
+	e.model.Free()
+}
+
+// spawnExplosion creates the explosion effects for an explosion
+// with the given radius. Larger explosions are made up of multiple
+// effects spread over the area.
+func (c *ClientState) spawnExplosion(x, y, z, radius float64) {
+	if radius < 2 {
+		c.explosions = append(c.explosions, newExplosionEffect(x, y, z, 2))
+		return
+	}
+	count := int(radius * 2)
+	if count > 16 {
+		count = 16
+	}
+	for i := 0; i < count; i++ {
+		c.explosions = append(c.explosions, newExplosionEffect(
+			x+(rand.Float64()-rand.Float64())*radius,
+			y+(rand.Float64()-rand.Float64())*radius,
+			z+(rand.Float64()-rand.Float64())*radius,
+			radius,
+		))
+	}
+}
+
+func (c *ClientState) tickExplosions(delta float64) {
+	for i := 0; i < len(c.explosions); i++ {
+		e := c.explosions[i]
+		if !e.tick(delta) {
+			e.free()
+			c.explosions = append(c.explosions[:i], c.explosions[i+1:]...)
+			i--
+		}
+	}
+}
@@ -221,6 +221,7 @@ const (
 	OpenFile       ClickAction = "open_file"
 	RunCommand     ClickAction = "run_command"
 	SuggestCommand ClickAction = "suggest_command"
+	ChangePage     ClickAction = "change_page"
 )
 
 // Valid HoverActions.
@@ -228,6 +229,7 @@ const (
 	ShowText        HoverAction = "show_text"
 	ShowAchievement HoverAction = "show_achievement"
 	ShowItem        HoverAction = "show_item"
+	ShowEntity      HoverAction = "show_entity"
 )
 
 // ClickEvent is an event which will be preformed when the
@@ -239,7 +241,13 @@ type ClickEvent struct {
 
 // HoverEvent is an event which will be preformed when the
 // area of text is hovered over.
+//
+// The value may be a full component or a plain string
+// depending on the action (e.g. the item's NBT for ShowItem).
+// Value used to be a string which failed to decode components,
+// plain strings are now decoded as a TextComponent and
+// Value.String() returns the original text.
 type HoverEvent struct {
-	Action HoverAction `json:"action"`
-	Value  string      `json:"value"`
+	Action HoverAction  `json:"action"`
+	Value  AnyComponent `json:"value"`
 }
@@ -0,0 +1,43 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestHoverEventValue(t *testing.T) {
+	tests := []struct {
+		json string
+		want string
+	}{
+		// Older servers and show_item/show_achievement send
+		// plain strings
+		{`{"action":"show_item","value":"{id:minecraft:stone}"}`, "{id:minecraft:stone}"},
+		{`{"action":"show_text","value":{"text":"hello","extra":[" world"]}}`, "hello world"},
+		{`{"action":"show_text","value":["a",{"text":"b"}]}`, "ab"},
+	}
+	for _, test := range tests {
+		var ev HoverEvent
+		if err := json.Unmarshal([]byte(test.json), &ev); err != nil {
+			t.Errorf("%s: unexpected error %s", test.json, err)
+			continue
+		}
+		if got := ev.Value.String(); got != test.want {
+			t.Errorf("%s: got %q, wanted %q", test.json, got, test.want)
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"errors"
 	"math"
+	"math/rand"
 	"reflect"
 	"strings"
 
@@ -66,6 +67,10 @@ func (h handler) Handle(packet interface{}) {
 
 func (handler) ServerMessage(msg *protocol.ServerMessage) {
 	console.Text("MSG(%d): %s", msg.Type, msg.Message.Value)
+	if msg.Type == 2 {
+		Client.titles.setActionBar(msg.Message)
+		return
+	}
 	Client.chat.Add(msg.Message)
 }
 
@@ -74,6 +79,7 @@ func (handler) JoinGame(j *protocol.JoinGame) {
 	sendPluginMessage(&pmMinecraftBrand{
 		Brand: "Steven",
 	})
+	Client.entityID = int(j.EntityID)
 	Client.GameMode = gameMode(j.Gamemode & 0x7)
 	Client.HardCore = j.Gamemode&0x8 != 0
 	Client.updateWorldType(worldType(j.Dimension))
@@ -81,6 +87,9 @@ func (handler) JoinGame(j *protocol.JoinGame) {
 
 func (handler) Respawn(r *protocol.Respawn) {
 	clearChunks()
+	Client.entities.unlink(Client.entityID)
+	Client.effects.clear()
+	Client.attributes = attributeMap{}
 	Client.GameMode = gameMode(r.Gamemode & 0x7)
 	Client.HardCore = r.Gamemode&0x8 != 0
 	Client.updateWorldType(worldType(r.Dimension))
@@ -107,10 +116,26 @@ func (handler) UpdateHealth(u *protocol.UpdateHealth) {
 	Client.UpdateHunger(float64(u.Food))
 }
 
+func (handler) SetExperience(e *protocol.SetExperience) {
+	Client.UpdateExperience(float64(e.ExperienceBar), int(e.Level))
+}
+
 func (handler) ChangeGameState(c *protocol.ChangeGameState) {
 	switch c.Reason {
+	// Vanilla sends 1 when rain starts and 2 when it
+	// stops, the level is then faded by 7
+	case 1: // Begin raining
+		Client.weather.raining = true
+		Client.weather.rain = 0
+	case 2: // End raining
+		Client.weather.raining = false
+		Client.weather.rain = 1
 	case 3: // Change game mode
 		Client.GameMode = gameMode(c.Value)
+	case 7: // Rain level
+		Client.weather.rain = clampFloat(float64(c.Value), 0, 1)
+	case 8: // Thunder level
+		Client.weather.thunder = clampFloat(float64(c.Value), 0, 1)
 	}
 }
 
@@ -249,6 +274,10 @@ func (handler) SignUpdate(p *protocol.UpdateSign) {
 	})
 }
 
+func (handler) SignEditorOpen(p *protocol.SignEditorOpen) {
+	setScreen(newSignEditScreen(p.Location))
+}
+
 func (handler) BlockBreakAnimation(p *protocol.BlockBreakAnimation) {
 	if p.Stage < 0 || p.Stage > 9 {
 		bb := Client.blockBreakers[int(p.EntityID)]
@@ -273,6 +302,96 @@ func (handler) BlockBreakAnimation(p *protocol.BlockBreakAnimation) {
 	b.(BlockBreakComponent).Update()
 }
 
+func (handler) Explosion(p *protocol.Explosion) {
+	// Records are relative to the truncated position of the
+	// explosion (not floored).
+	ox, oy, oz := int(p.X), int(p.Y), int(p.Z)
+	for _, r := range p.Records {
+		x, y, z := ox+int(r.X), oy+int(r.Y), oz+int(r.Z)
+		cp := chunkPosition{x >> 4, z >> 4}
+		if f, ok := loadingChunks[cp]; ok {
+			loadingChunks[cp] = append(f, func() {
+				chunkMap.SetBlock(Blocks.Air.Base, x, y, z)
+				chunkMap.UpdateBlock(x, y, z)
+			})
+			continue
+		}
+		chunkMap.SetBlock(Blocks.Air.Base, x, y, z)
+		chunkMap.UpdateBlock(x, y, z)
+	}
+
+	// The velocity is sent as blocks per a tick and the
+	// client moves per a frame (3 frames per a tick)
+	Client.VelocityX += float64(p.VelocityX) / 3
+	Client.VelocityZ += float64(p.VelocityZ) / 3
+	if p.VelocityY != 0 {
+		Client.VSpeed += float64(p.VelocityY) / 3
+		if p.VelocityY > 0 {
+			Client.OnGround = false
+		}
+	}
+
+	pitch := (1 + (soundRandom.Float64()-soundRandom.Float64())*0.2) * 0.7
+	PlaySoundAt("random.explode", 4, pitch, mgl32.Vec3{p.X, p.Y, p.Z})
+	Client.spawnExplosion(float64(p.X), float64(p.Y), float64(p.Z), float64(p.Radius))
+}
+
+func (handler) WorldBorder(p *protocol.WorldBorder) {
+	Client.border.handle(p)
+}
+
+func (handler) SpawnGlobalEntity(p *protocol.SpawnGlobalEntity) {
+	// Lightning is the only global entity
+	if p.Type != 1 {
+		return
+	}
+	Client.weather.spawnLightning(
+		float64(p.X)/32,
+		float64(p.Y)/32,
+		float64(p.Z)/32,
+	)
+}
+
+func (handler) Particle(p *protocol.Particle) {
+	Client.particles.handleParticle(p)
+}
+
+func (handler) Effect(p *protocol.Effect) {
+	Client.playEffect(p)
+}
+
+func (handler) Maps(p *protocol.Maps) {
+	getMap(int(p.ItemDamage)).update(p)
+}
+
+func (handler) ScoreboardObjective(p *protocol.ScoreboardObjective) {
+	Client.scoreboard.handleObjective(p)
+}
+
+func (handler) UpdateScore(p *protocol.UpdateScore) {
+	Client.scoreboard.handleScore(p)
+}
+
+func (handler) ScoreboardDisplay(p *protocol.ScoreboardDisplay) {
+	Client.scoreboard.handleDisplay(p)
+}
+
+func (handler) Teams(p *protocol.Teams) {
+	Client.scoreboard.handleTeam(p)
+}
+
+func (handler) Title(p *protocol.Title) {
+	Client.titles.handle(p)
+}
+
+func (handler) PlayerListHeaderFooter(p *protocol.PlayerListHeaderFooter) {
+	Client.playerList.setHeaderFooter(p.Header, p.Footer)
+}
+
+func (handler) TabCompleteReply(p *protocol.TabCompleteReply) {
+	Client.chat.handleCompletion(p.Matches)
+}
+
 func (handler) SpawnPlayer(s *protocol.SpawnPlayer) {
 	e := newPlayer()
 	if p, ok := e.(PositionComponent); ok {
@@ -299,6 +418,7 @@ func (handler) SpawnPlayer(s *protocol.SpawnPlayer) {
 	}
 	e.(PlayerComponent).SetUUID(s.UUID)
 	e.(NetworkComponent).SetEntityID(int(s.EntityID))
+	updateLivingMetadata(e, s.Metadata)
 	Client.entities.add(int(s.EntityID), e)
 }
 
@@ -332,10 +452,202 @@ func (handler) SpawnMob(s *protocol.SpawnMob) {
 	}
 
 	e.(NetworkComponent).SetEntityID(int(s.EntityID))
+	updateLivingMetadata(e, s.Metadata)
+
+	Client.entities.add(int(s.EntityID), e)
+}
+
+func (handler) SpawnObject(s *protocol.SpawnObject) {
+	et, ok := objectTypes[int(s.Type)]
+	if !ok {
+		return
+	}
+	e := et(int(s.Data))
+	if p, ok := e.(PositionComponent); ok {
+		p.SetPosition(
+			float64(s.X)/32,
+			float64(s.Y)/32,
+			float64(s.Z)/32,
+		)
+	}
+	if p, ok := e.(TargetPositionComponent); ok {
+		p.SetTargetPosition(
+			float64(s.X)/32,
+			float64(s.Y)/32,
+			float64(s.Z)/32,
+		)
+	}
+	if r, ok := e.(RotationComponent); ok {
+		r.SetYaw((float64(s.Yaw) / 256) * math.Pi * 2)
+		r.SetPitch((float64(s.Pitch) / 256) * math.Pi * 2)
+	}
+	if r, ok := e.(TargetRotationComponent); ok {
+		r.SetTargetYaw((float64(s.Yaw) / 256) * math.Pi * 2)
+		r.SetTargetPitch((float64(s.Pitch) / 256) * math.Pi * 2)
+	}
+	// The velocity is only sent when the data is set
+	if v, ok := e.(VelocityComponent); ok && s.Data != 0 {
+		v.SetVelocity(
+			float64(s.VelocityX)/8000,
+			float64(s.VelocityY)/8000,
+			float64(s.VelocityZ)/8000,
+		)
+	}
+
+	e.(NetworkComponent).SetEntityID(int(s.EntityID))
+
+	Client.entities.add(int(s.EntityID), e)
+}
+
+func (handler) SpawnPainting(s *protocol.SpawnPainting) {
+	e := newPainting(
+		s.Title,
+		s.Location.X(), s.Location.Y(), s.Location.Z(),
+		hangingFacing(s.Direction),
+	)
+	e.(NetworkComponent).SetEntityID(int(s.EntityID))
+	Client.entities.add(int(s.EntityID), e)
+}
 
+func (handler) SpawnExperienceOrb(s *protocol.SpawnExperienceOrb) {
+	e := newExperienceOrb(int(s.Count))
+	if p, ok := e.(PositionComponent); ok {
+		p.SetPosition(
+			float64(s.X)/32,
+			float64(s.Y)/32,
+			float64(s.Z)/32,
+		)
+	}
+	if p, ok := e.(TargetPositionComponent); ok {
+		p.SetTargetPosition(
+			float64(s.X)/32,
+			float64(s.Y)/32,
+			float64(s.Z)/32,
+		)
+	}
+	e.(NetworkComponent).SetEntityID(int(s.EntityID))
 	Client.entities.add(int(s.EntityID), e)
 }
 
+func (handler) EntityVelocity(v *protocol.EntityVelocity) {
+	e, ok := Client.entities.entities[int(v.EntityID)]
+	if !ok {
+		return
+	}
+	if vc, ok := e.(VelocityComponent); ok {
+		vc.SetVelocity(
+			float64(v.VelocityX)/8000,
+			float64(v.VelocityY)/8000,
+			float64(v.VelocityZ)/8000,
+		)
+	}
+}
+
+func (handler) EntityMetadata(m *protocol.EntityMetadata) {
+	if int(m.EntityID) == Client.entityID {
+		if air, ok := m.Metadata[1].(int16); ok {
+			Client.UpdateAir(int(air))
+		}
+		return
+	}
+	e, ok := Client.entities.entities[int(m.EntityID)]
+	if !ok {
+		return
+	}
+	if mc, ok := e.(MetadataComponent); ok {
+		mc.SetMetadata(m.Metadata)
+	}
+	updateLivingMetadata(e, m.Metadata)
+}
+
+// updateLivingMetadata applies the metadata values shared by all
+// living entities.
+func updateLivingMetadata(e Entity, m protocol.Metadata) {
+	if ec, ok := e.(EffectsComponent); ok {
+		if invisible, ok := metadataInvisible(m); ok {
+			ec.SetInvisible(invisible)
+		}
+	}
+	if hc, ok := e.(HurtComponent); ok {
+		if health, ok := m[6].(float32); ok {
+			hc.SetHealth(float64(health))
+		}
+	}
+}
+
+func (handler) EntityEffect(p *protocol.EntityEffect) {
+	if int(p.EntityID) == Client.entityID {
+		Client.effects.add(int(p.EffectID), int(p.Amplifier), int(p.Duration))
+		return
+	}
+	e, ok := Client.entities.entities[int(p.EntityID)]
+	if !ok {
+		return
+	}
+	if ec, ok := e.(EffectsComponent); ok {
+		ec.AddEffect(int(p.EffectID), int(p.Amplifier))
+	}
+}
+
+func (handler) EntityRemoveEffect(p *protocol.EntityRemoveEffect) {
+	if int(p.EntityID) == Client.entityID {
+		Client.effects.remove(int(p.EffectID))
+		return
+	}
+	e, ok := Client.entities.entities[int(p.EntityID)]
+	if !ok {
+		return
+	}
+	if ec, ok := e.(EffectsComponent); ok {
+		ec.RemoveEffect(int(p.EffectID))
+	}
+}
+
+func (handler) EntityProperties(p *protocol.EntityProperties) {
+	if int(p.EntityID) == Client.entityID {
+		Client.attributes.update(p.Properties)
+		Client.applySprintModifier()
+		return
+	}
+	e, ok := Client.entities.entities[int(p.EntityID)]
+	if !ok {
+		return
+	}
+	if ac, ok := e.(AttributesComponent); ok {
+		ac.UpdateAttributes(p.Properties)
+	}
+}
+
+func (handler) CollectItem(c *protocol.CollectItem) {
+	e, ok := Client.entities.entities[int(c.CollectedEntityID)]
+	if !ok {
+		return
+	}
+	collector, ok := Client.entities.get(int(c.CollectorEntityID))
+	if !ok {
+		return
+	}
+	p, ok := e.(PickupComponent)
+	if !ok {
+		return
+	}
+	cp, ok := collector.(PositionComponent)
+	if !ok {
+		return
+	}
+	p.Pickup(cp)
+	// The server removes the entity straight after this so it
+	// has to be detached for the animation to play
+	Client.entities.detach(int(c.CollectedEntityID))
+
+	if pos, ok := e.(PositionComponent); ok {
+		x, y, z := pos.Position()
+		PlaySoundAt("random.pop", 0.2, ((rand.Float64()-rand.Float64())*0.7+1)*2, mgl32.Vec3{
+			float32(x), float32(y), float32(z),
+		})
+	}
+}
+
 func (handler) EntityTeleport(t *protocol.EntityTeleport) {
 	e, ok := Client.entities.entities[int(t.EntityID)]
 	if !ok {
@@ -431,7 +743,7 @@ func (handler) DestroyEntities(e *protocol.EntityDestroy) {
 }
 
 func (handler) Animation(p *protocol.Animation) {
-	e, ok := Client.entities.entities[int(p.EntityID)]
+	e, ok := Client.entities.get(int(p.EntityID))
 	if !ok {
 		return
 	}
@@ -440,21 +752,60 @@ func (handler) Animation(p *protocol.Animation) {
 		if p, ok := e.(PlayerModelComponent); ok {
 			p.SwingArm()
 		}
+	case 1: // Take damage
+		if h, ok := e.(HurtComponent); ok {
+			h.Hurt()
+		}
+	case 4, 5: // Critical hit, magic critical hit
+		Client.particles.spawnCrit(e, p.AnimationID == 5)
 	}
 }
 
+// EntityAction is the entity status packet, used for events that
+// happen to entities.
+func (handler) EntityAction(p *protocol.EntityAction) {
+	e, ok := Client.entities.get(int(p.EntityID))
+	if !ok {
+		return
+	}
+	switch p.ActionID {
+	case 2: // Hurt
+		if h, ok := e.(HurtComponent); ok {
+			h.Hurt()
+		}
+	case 3: // Death
+		if h, ok := e.(HurtComponent); ok {
+			h.Die()
+		}
+	default:
+		if sp, ok := entityStatusParticles[p.ActionID]; ok {
+			Client.particles.spawnAround(e, sp.id, sp.count)
+		}
+	}
+}
+
+func (handler) EntityAttach(p *protocol.EntityAttach) {
+	id := int(p.EntityID)
+	if id == Client.entityID && !p.Leash && p.Vehicle == -1 {
+		Client.dismount()
+	}
+	Client.entities.attach(id, int(p.Vehicle), p.Leash)
+}
+
 func (handler) EntityEquipment(p *protocol.EntityEquipment) {
 	e, ok := Client.entities.entities[int(p.EntityID)]
 	if !ok {
 		return
 	}
-	switch p.Slot {
-	case 0: // Swing arm
+	item := ItemStackFromProtocol(p.Item)
+	if eq, ok := e.(EquipmentComponent); ok {
+		eq.SetEquipment(int(p.Slot), item)
+	}
+	if p.Slot == equipmentHeld {
 		if pl, ok := e.(PlayerModelComponent); ok {
-			pl.SetCurrentItem(ItemStackFromProtocol(p.Item))
+			pl.SetCurrentItem(item)
 		}
 	}
-
 }
 
 func (handler) PlayerListInfo(p *protocol.PlayerInfo) {
@@ -542,43 +893,83 @@ func (handler) PlayerListInfo(p *protocol.PlayerInfo) {
 	}
 }
 
-func (handler) WindowItems(p *protocol.WindowItems) {
-	var inv *Inventory
-	if p.ID == 0 {
-		inv = Client.playerInventory
+func (handler) WindowOpen(p *protocol.WindowOpen) {
+	wt, ok := windowTypes[p.Type]
+	if !ok {
+		// Unknown windows are most likely ones created
+		// by plugins so treat them as a chest
+		wt = windowTypes["minecraft:chest"]
 	}
-	if inv = Client.activeInventory; inv == nil || inv.ID != int(p.ID) {
-		inv = nil
+	size := wt.slots
+	if size == 0 {
+		size = int(p.SlotCount)
 	}
+	inv := NewInventory(wt.ty, int(p.ID), size+36)
+	inv.Title = p.Title
+	inv.playerSlots = size
+	copy(inv.Items[size:], Client.playerInventory.Items[9:])
+	openInventory(inv)
+}
+
+func (handler) WindowClose(p *protocol.WindowClose) {
+	inv := Client.activeInventory
+	if inv == nil || inv.ID != int(p.ID) {
+		return
+	}
+	inv.Hide()
+	Client.activeInventory = nil
+	setScreen(nil)
+	Client.playerInventory.Update()
+}
+
+func (handler) WindowItems(p *protocol.WindowItems) {
+	inv := inventoryByID(int(p.ID))
 	if inv == nil {
 		return
 	}
 	for i, item := range p.Items {
-		if i >= len(inv.Items) {
-			break
-		}
-		it := ItemStackFromProtocol(item)
-		inv.Items[i] = it
+		inv.setItem(i, ItemStackFromProtocol(item))
 	}
-	inv.Update()
+	inv.updateLinked()
 }
 
 func (handler) WindowItem(p *protocol.WindowSetSlot) {
-	var inv *Inventory
-	if p.ID == 0 {
-		inv = Client.playerInventory
+	// Window -1 slot -1 is the item held by the cursor
+	if p.ID == 0xFF && p.Slot == -1 {
+		invScreen.setCursor(ItemStackFromProtocol(p.ItemStack))
+		return
 	}
+	inv := inventoryByID(int(p.ID))
 	if inv == nil {
 		return
 	}
-	if p.Slot == -999 {
-		invScreen.setCursor(ItemStackFromProtocol(p.ItemStack))
-	}
-	if p.Slot >= int16(len(inv.Items)) {
+	inv.setItem(int(p.Slot), ItemStackFromProtocol(p.ItemStack))
+	inv.updateLinked()
+}
+
+func (handler) WindowProperty(p *protocol.WindowProperty) {
+	inv := inventoryByID(int(p.ID))
+	if inv == nil {
 		return
 	}
-	inv.Items[p.Slot] = ItemStackFromProtocol(p.ItemStack)
-	inv.Update()
+	inv.Properties[int(p.Property)] = int(p.Value)
+	inv.UpdateProperties()
+}
+
+func (handler) ConfirmTransaction(p *protocol.ConfirmTransaction) {
+	inv := inventoryByID(int(p.ID))
+	if inv != nil {
+		inv.confirm(p.ActionNumber, p.Accepted)
+	}
+	if !p.Accepted {
+		// The server won't accept any more clicks until the
+		// rejection is acknowledged
+		Client.network.Write(&protocol.ConfirmTransactionServerbound{
+			ID:           p.ID,
+			ActionNumber: p.ActionNumber,
+			Accepted:     true,
+		})
+	}
 }
 
 func (handler) PlaySound(p *protocol.SoundEffect) {
@@ -602,3 +993,8 @@ etc.
 func (h handler) ServerBrand(b *pmMinecraftBrand) {
 	serverBrand.SetValue(b.Brand)
 }
+
+func (h handler) BookOpen(b *pmBookOpen) {
+	item := Client.playerInventory.Items[invPlayerHotbarOffset+Client.currentHotbarSlot]
+	setScreen(newBookScreen(item))
+}
@@ -16,8 +16,10 @@ package steven
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/thinkofdeath/steven/format"
 	"github.com/thinkofdeath/steven/protocol"
 	"github.com/thinkofdeath/steven/render"
 	"github.com/thinkofdeath/steven/ui"
@@ -34,21 +36,52 @@ type InventoryType interface {
 	Draw(scene *scene.Type, inv *Inventory)
 }
 
+// InventoryProperties is implemented by inventory types that display
+// the properties of the window, e.g. a furnace's progress.
+type InventoryProperties interface {
+	DrawProperties(scene *scene.Type, inv *Inventory)
+}
+
+// The maximum number of clicks that can be waiting on a reply from
+// the server before the oldest is forgotten.
+const maxPendingTransactions = 64
+
 type Inventory struct {
-	Type InventoryType
-	ID   int
+	Type  InventoryType
+	ID    int
+	Title format.AnyComponent
+
+	Items      []*ItemStack
+	Properties map[int]int
+
+	// The slot the player's main inventory starts at when this
+	// is a container window.
+	playerSlots int
+
+	scene      *scene.Type
+	propScene  *scene.Type
+	background *ui.Image
 
-	Items []*ItemStack
+	lastAction int16
+	pending    []inventoryTransaction
+}
 
-	scene *scene.Type
+// inventoryTransaction is a click that the server hasn't confirmed
+// yet along with the state of the window before the click.
+type inventoryTransaction struct {
+	action int16
+	items  []*ItemStack
+	cursor *ItemStack
 }
 
 func NewInventory(ty InventoryType, id, size int) *Inventory {
 	return &Inventory{
-		Type:  ty,
-		ID:    id,
-		Items: make([]*ItemStack, size),
-		scene: scene.New(true),
+		Type:       ty,
+		ID:         id,
+		Items:      make([]*ItemStack, size),
+		Properties: map[int]int{},
+		scene:      scene.New(true),
+		propScene:  scene.New(true),
 	}
 }
 
@@ -56,23 +89,138 @@ func (inv *Inventory) Update() {
 	was := inv.scene.IsVisible()
 	inv.scene.Hide()
 	inv.scene = scene.New(was)
+	inv.background = nil
 	inv.Type.Draw(inv.scene, inv)
+	inv.UpdateProperties()
+}
+
+// UpdateProperties redraws the parts of the window that depend on
+// its properties without redrawing the items.
+func (inv *Inventory) UpdateProperties() {
+	inv.propScene.Hide()
+	inv.propScene = scene.New(inv.scene.IsVisible())
+	if p, ok := inv.Type.(InventoryProperties); ok && inv.background != nil {
+		p.DrawProperties(inv.propScene, inv)
+	}
+}
+
+// updateLinked updates the inventory along with any other window
+// that shares the player's slots with it.
+func (inv *Inventory) updateLinked() {
+	inv.Update()
+	if inv != Client.playerInventory {
+		Client.playerInventory.Update()
+	} else if active := Client.activeInventory; active != nil && active != inv {
+		active.Update()
+	}
 }
 
 func (inv *Inventory) Close() {
-	inv.scene.Hide()
+	inv.Hide()
+	inv.pending = nil
 	Client.network.Write(&protocol.CloseWindow{ID: byte(inv.ID)})
 }
 
 func (inv *Inventory) Hide() {
 	inv.scene.Hide()
+	inv.propScene.Hide()
 }
 
 func (inv *Inventory) Show() {
 	inv.scene.Show()
+	inv.propScene.Show()
+}
+
+// setItem changes the item in the slot. Container windows include
+// the player's inventory so changes are copied between the two.
+func (inv *Inventory) setItem(slot int, item *ItemStack) {
+	if slot < 0 || slot >= len(inv.Items) {
+		return
+	}
+	inv.Items[slot] = item
+	player := Client.playerInventory
+	if inv != player {
+		if slot >= inv.playerSlots && inv.playerSlots > 0 {
+			player.Items[slot-inv.playerSlots+9] = item
+		}
+	} else if active := Client.activeInventory; active != nil && active != player && slot >= 9 {
+		active.Items[active.playerSlots+slot-9] = item
+	}
+}
+
+// click sends a click on the slot to the server. The state of the
+// window is saved first so that it can be restored if the server
+// rejects the click.
+func (inv *Inventory) click(slot, button, mode int) {
+	inv.lastAction++
+	items := make([]*ItemStack, len(inv.Items))
+	for i, item := range inv.Items {
+		items[i] = item.clone()
+	}
+	inv.pending = append(inv.pending, inventoryTransaction{
+		action: inv.lastAction,
+		items:  items,
+		cursor: invScreen.cursorItem.clone(),
+	})
+	if len(inv.pending) > maxPendingTransactions {
+		inv.pending = inv.pending[1:]
+	}
+	var clicked *ItemStack
+	// Drags don't report the item that was clicked
+	if slot >= 0 && slot < len(inv.Items) && mode != clickDrag {
+		clicked = inv.Items[slot]
+	}
+	Client.network.Write(&protocol.ClickWindow{
+		ID:           byte(inv.ID),
+		Slot:         int16(slot),
+		Button:       byte(button),
+		Mode:         byte(mode),
+		ActionNumber: inv.lastAction,
+		ClickedItem:  ItemStackToProtocol(clicked),
+	})
+}
+
+// confirm handles the server's reply to a click. A rejected click
+// rolls the window back to how it was before the click was made.
+func (inv *Inventory) confirm(action int16, accepted bool) {
+	for i, t := range inv.pending {
+		if t.action != action {
+			continue
+		}
+		if accepted {
+			inv.pending = inv.pending[i+1:]
+			return
+		}
+		// Later clicks were predicted on top of this one so they
+		// are invalid as well
+		inv.pending = nil
+		for slot, item := range t.items {
+			inv.setItem(slot, item)
+		}
+		inv.updateLinked()
+		if Client.activeInventory == inv {
+			invScreen.setCursor(t.cursor)
+		}
+		return
+	}
+}
+
+// inventoryByID returns the open inventory with the passed window
+// id, if any.
+func inventoryByID(id int) *Inventory {
+	if inv := Client.activeInventory; inv != nil && inv.ID == id {
+		return inv
+	}
+	if id == 0 {
+		return Client.playerInventory
+	}
+	return nil
 }
 
 func openInventory(inv *Inventory) {
+	if active := Client.activeInventory; active != nil && active != inv {
+		active.Hide()
+	}
 	Client.activeInventory = inv
 	Client.activeInventory.Show()
 	Client.activeInventory.Update()
@@ -98,12 +246,24 @@ type inventoryScreen struct {
 	cursorIcon     *ui.Container
 	lastMX, lastMY float64
 	scene          *scene.Type
+
+	dragging   bool
+	dragButton int
+	dragSlots  []int
+
+	doubleClick     bool
+	lastClickSlot   int
+	lastClickButton int
+	lastClickTime   time.Time
 }
 
 func (i *inventoryScreen) init() {
 	i.prev = window.SetKeyCallback(i.onKey)
 	i.activeSlot = -1
 	i.cursorItem = nil
+	i.dragging = false
+	i.dragSlots = nil
+	i.lastClickSlot = -1
 	if i.scene != nil {
 		i.scene.Hide()
 	}
@@ -118,39 +278,16 @@ func (i *inventoryScreen) hover(x, y float64, w, h int) {
 		i.cursorIcon.SetY(y - 16)
 	}
 	ui.Hover(x, y, w, h)
+	i.updateDrag()
 }
 func (i *inventoryScreen) click(down bool, x, y float64, w, h int) {
-	if down {
-		if i.activeSlot != -1 {
-			item := Client.activeInventory.Items[i.activeSlot]
-			Client.activeInventory.Items[i.activeSlot] = i.cursorItem
-			Client.network.Write(&protocol.ClickWindow{
-				ID:           byte(Client.activeInventory.ID),
-				Slot:         int16(i.activeSlot),
-				Button:       0,
-				Mode:         0,
-				ActionNumber: 42,
-				ClickedItem:  ItemStackToProtocol(item),
-			})
-			i.setCursor(item)
-		} else if !i.inWindow {
-			Client.network.Write(&protocol.ClickWindow{
-				ID:           byte(Client.activeInventory.ID),
-				Slot:         int16(-999),
-				Button:       0,
-				Mode:         0,
-				ActionNumber: 42,
-				ClickedItem:  ItemStackToProtocol(nil),
-			})
-			i.setCursor(nil)
-		}
-		return
-	}
-	ui.Click(x, y, w, h)
+	i.mouseButton(glfw.MouseButtonLeft, down, 0, x, y, w, h)
 }
 
 func (i *inventoryScreen) setCursor(item *ItemStack) {
-	i.scene.Hide()
+	if i.scene != nil {
+		i.scene.Hide()
+	}
 	i.scene = scene.New(true)
 	i.cursorItem = item
 	i.cursorIcon = nil
@@ -158,10 +295,15 @@ func (i *inventoryScreen) setCursor(item *ItemStack) {
 		i.cursorIcon = createItemIcon(item, i.scene, i.lastMX-16, i.lastMY-16)
 		i.cursorIcon.SetLayer(100)
 	}
-	Client.activeInventory.Update()
+	if Client.activeInventory != nil {
+		Client.activeInventory.Update()
+	}
 }
 
 func (i *inventoryScreen) onKey(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+	if action == glfw.Press {
+		i.keyPress(key, mods)
+	}
 	if action != glfw.Release {
 		return
 	}
@@ -175,6 +317,80 @@ func (i *inventoryScreen) remove() {
 	i.scene.Hide()
 }
 
+// drawInventoryBackground adds the window's texture to the scene
+// centered on the screen.
+func drawInventoryBackground(s *scene.Type, inv *Inventory, texture string, w, h float64) *ui.Image {
+	background := ui.NewImage(
+		render.GetTexture(texture),
+		0, 0, w*2, h*2,
+		0, 0, w/256.0, h/256.0,
+		255, 255, 255,
+	)
+	s.AddDrawable(background.Attach(ui.Middle, ui.Center))
+	inv.background = background
+	addInventoryBounds(s, w, h)
+	return background
+}
+
+// addInventoryBounds tracks whether the cursor is inside the window
+// so that clicking outside of it can drop the held item.
+func addInventoryBounds(s *scene.Type, w, h float64) {
+	check := ui.NewContainer(0, 0, w*2, h*2)
+	s.AddDrawable(check.Attach(ui.Middle, ui.Center))
+	check.HoverFunc = func(over bool) {
+		invScreen.inWindow = over
+	}
+}
+
+// drawInventoryTitle adds the component as a label on the window.
+func drawInventoryTitle(s *scene.Type, background *ui.Image, title format.AnyComponent, x, y float64) {
+	if title.Value == nil {
+		return
+	}
+	f := ui.NewFormatted(title, x*2, y*2)
+	f.AttachTo(background)
+	// Labels are dark gray unless the title sets its own color
+	for _, t := range f.Text {
+		if t.R() == 255 && t.G() == 255 && t.B() == 255 {
+			t.SetR(64)
+			t.SetG(64)
+			t.SetB(64)
+		}
+	}
+	s.AddDrawable(f.Attach(ui.Top, ui.Left))
+}
+
+// drawSlot adds the item in the slot to the scene along with the
+// highlight shown whilst the slot is hovered.
+func drawSlot(s *scene.Type, background *ui.Image, inv *Inventory, slot int, x, y float64) {
+	ctn := ui.NewContainer(x*2, y*2, 32, 32)
+	ctn.AttachTo(background)
+	s.AddDrawable(ctn)
+
+	if item := inv.Items[slot]; item != nil {
+		container := createItemIcon(item, s, x*2, y*2)
+		container.AttachTo(background)
+	}
+
+	highlight := ui.NewImage(render.GetTexture("solid"), x*2, y*2, 32, 32, 0, 0, 1, 1, 255, 255, 255)
+	highlight.SetA(0)
+	highlight.AttachTo(background)
+	highlight.SetLayer(25)
+	s.AddDrawable(highlight)
+
+	ctn.HoverFunc = func(over bool) {
+		if over {
+			highlight.SetA(100)
+			invScreen.activeSlot = slot
+		} else {
+			highlight.SetA(0)
+			if slot == invScreen.activeSlot {
+				invScreen.activeSlot = -1
+			}
+		}
+	}
+}
+
 // Player
 
 type playerInventory struct {
@@ -182,13 +398,18 @@ type playerInventory struct {
 
 const invPlayerHotbarOffset = 36
 
+// invPlayerArmorOffset is the first armor slot, the slots go
+// from the helmet down to the boots.
+const invPlayerArmorOffset = 5
+
 func (playerInventory) Draw(s *scene.Type, inv *Inventory) {
 	full := Client.activeInventory == Client.playerInventory
 
 	if !full {
 		// Slots 36-44 are the hotbar
+		visible := Client.hotbarScene.IsVisible()
 		Client.hotbarScene.Hide()
-		Client.hotbarScene = scene.New(true)
+		Client.hotbarScene = scene.New(visible)
 		hs := Client.hotbarScene
 		for i := invPlayerHotbarOffset; i < invPlayerHotbarOffset+9; i++ {
 			if inv.Items[i] == nil {
@@ -202,19 +423,7 @@ func (playerInventory) Draw(s *scene.Type, inv *Inventory) {
 		return
 	}
 
-	background := ui.NewImage(
-		render.GetTexture("gui/container/inventory"),
-		0, 0, 176*2, 166*2,
-		0, 0, 176/256.0, 166/256.0,
-		255, 255, 255,
-	)
-	s.AddDrawable(background.Attach(ui.Middle, ui.Center))
-
-	check := ui.NewContainer(0, 0, 176*2, 166*2)
-	s.AddDrawable(check.Attach(ui.Middle, ui.Center))
-	check.HoverFunc = func(over bool) {
-		invScreen.inWindow = over
-	}
+	background := drawInventoryBackground(s, inv, "gui/container/inventory", 176, 166)
 
 	var slotPositions = [45][2]float64{
 		0: {144, 36}, // Craft-out
@@ -242,19 +451,9 @@ func (playerInventory) Draw(s *scene.Type, inv *Inventory) {
 		}
 	}
 
-	solid := render.GetTexture("solid")
-
 	for i, pos := range slotPositions {
-		i := i
-		ctn := ui.NewContainer(pos[0]*2, pos[1]*2, 32, 32)
-		ctn.AttachTo(background)
-		s.AddDrawable(ctn)
-
-		item := inv.Items[i]
-		if item != nil {
-			container := createItemIcon(item, s, pos[0]*2, pos[1]*2)
-			container.AttachTo(background)
-		} else if i >= 5 && i <= 8 {
+		drawSlot(s, background, inv, i, pos[0], pos[1])
+		if inv.Items[i] == nil && i >= 5 && i <= 8 {
 			tex := render.GetTexture([]string{
 				"items/empty_armor_slot_helmet",
 				"items/empty_armor_slot_chestplate",
@@ -265,25 +464,16 @@ func (playerInventory) Draw(s *scene.Type, inv *Inventory) {
 			img.AttachTo(background)
 			s.AddDrawable(img)
 		}
+	}
+}
 
-		highlight := ui.NewImage(solid, pos[0]*2, pos[1]*2, 32, 32, 0, 0, 1, 1, 255, 255, 255)
-		highlight.SetA(0)
-		highlight.AttachTo(background)
-		highlight.SetLayer(25)
-		s.AddDrawable(highlight)
-
-		ctn.HoverFunc = func(over bool) {
-			if over {
-				highlight.SetA(100)
-				invScreen.activeSlot = i
-			} else {
-				highlight.SetA(0)
-				if i == invScreen.activeSlot {
-					invScreen.activeSlot = -1
-				}
-			}
-		}
+// DrawProperties draws the player's status effects next to the
+// window.
+func (playerInventory) DrawProperties(s *scene.Type, inv *Inventory) {
+	if Client.activeInventory != inv {
+		return
 	}
+	Client.effects.drawInventory(s, inv)
 }
 
 func createItemIcon(item *ItemStack, scene *scene.Type, x, y float64) *ui.Container {
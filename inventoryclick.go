@@ -0,0 +1,494 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/thinkofdeath/steven/ui"
+)
+
+// Click modes used by protocol.ClickWindow
+const (
+	clickNormal = iota
+	clickShift
+	clickHotbar
+	clickClone
+	clickDrop
+	clickDrag
+	clickCollect
+)
+
+// The slot used for clicks outside of the window
+const slotOutside = -999
+
+// The longest time between two clicks for them to count as a
+// double click.
+const doubleClickTime = 250 * time.Millisecond
+
+// inventoryLayout is implemented by inventory types to describe how
+// items move between their slots.
+type inventoryLayout interface {
+	// isOutput returns whether the slot is a result slot that
+	// items can only be taken from.
+	isOutput(slot int) bool
+	// shiftTargets returns the slots the item in the passed slot
+	// will be moved to when shift clicked, in order.
+	shiftTargets(inv *Inventory, slot int, item *ItemStack) []slotRange
+}
+
+// slotRange is the range of slots [start, end) optionally searched
+// from the end.
+type slotRange struct {
+	start, end int
+	reverse    bool
+}
+
+// playerShiftTargets moves items between the player's main inventory
+// and their hotbar, the main inventory starting at the passed slot.
+func playerShiftTargets(start, slot int) []slotRange {
+	if slot < start+27 {
+		return []slotRange{{start + 27, start + 36, false}}
+	}
+	return []slotRange{{start, start + 27, false}}
+}
+
+func (inv *Inventory) isOutput(slot int) bool {
+	l, ok := inv.Type.(inventoryLayout)
+	return ok && l.isOutput(slot)
+}
+
+// hotbarSlot returns the slot in the window for the hotbar slot.
+func (inv *Inventory) hotbarSlot(n int) int {
+	if inv == Client.playerInventory {
+		return invPlayerHotbarOffset + n
+	}
+	return inv.playerSlots + 27 + n
+}
+
+// mergeItem moves as much of the item as possible into the range of
+// slots, topping up existing stacks before using empty slots.
+func (inv *Inventory) mergeItem(item *ItemStack, r slotRange) {
+	for pass := 0; pass < 2; pass++ {
+		for n := 0; n < r.end-r.start && item.Count > 0; n++ {
+			slot := r.start + n
+			if r.reverse {
+				slot = r.end - 1 - n
+			}
+			if slot >= len(inv.Items) || inv.isOutput(slot) {
+				continue
+			}
+			cur := inv.Items[slot]
+			if pass == 0 {
+				if !cur.stacksWith(item) {
+					continue
+				}
+				move := cur.maxStack() - cur.Count
+				if move > item.Count {
+					move = item.Count
+				}
+				if move <= 0 {
+					continue
+				}
+				cur = cur.clone()
+				cur.Count += move
+				item.Count -= move
+				inv.setItem(slot, cur)
+			} else if cur == nil {
+				inv.setItem(slot, item.clone())
+				item.Count = 0
+			}
+		}
+	}
+}
+
+func (i *inventoryScreen) mouseButton(button glfw.MouseButton, down bool, mods glfw.ModifierKey, x, y float64, w, h int) {
+	inv := Client.activeInventory
+	b := int(button)
+	if inv == nil || b > 2 {
+		return
+	}
+	if !down {
+		i.release(inv, b)
+		if button == glfw.MouseButtonLeft {
+			ui.Click(x, y, w, h)
+		}
+		return
+	}
+
+	slot := i.activeSlot
+	now := time.Now()
+	i.doubleClick = slot != -1 && slot == i.lastClickSlot &&
+		b == i.lastClickButton && now.Sub(i.lastClickTime) < doubleClickTime
+	i.lastClickSlot, i.lastClickButton, i.lastClickTime = slot, b, now
+
+	switch {
+	case slot == -1:
+		if !i.inWindow && i.cursorItem != nil && b != 2 {
+			inv.click(slotOutside, b, clickNormal)
+			cursor := i.cursorItem.clone()
+			// Right click only drops a single item
+			cursor.Count--
+			if b == 0 || cursor.Count <= 0 {
+				cursor = nil
+			}
+			i.setCursor(cursor)
+		}
+	case b == 2:
+		item := inv.Items[slot]
+		if Client.GameMode == gmCreative && i.cursorItem == nil && item != nil {
+			inv.click(slot, b, clickClone)
+			cursor := item.clone()
+			cursor.Count = cursor.maxStack()
+			i.setCursor(cursor)
+		}
+	case mods&glfw.ModShift != 0:
+		inv.click(slot, b, clickShift)
+		i.predictShiftClick(inv, slot)
+	case i.cursorItem == nil:
+		inv.click(slot, b, clickNormal)
+		i.predictClick(inv, slot, b)
+	default:
+		// Holding an item starts a drag, if the mouse doesn't
+		// leave the slot this becomes a normal click on release.
+		i.dragging = true
+		i.dragButton = b
+		i.dragSlots = []int{slot}
+	}
+}
+
+func (i *inventoryScreen) release(inv *Inventory, b int) {
+	slot := i.activeSlot
+	if i.doubleClick && b == 0 && slot != -1 && i.cursorItem != nil && !inv.isOutput(slot) {
+		i.dragging = false
+		i.doubleClick = false
+		i.lastClickTime = time.Time{}
+		inv.click(slot, b, clickCollect)
+		i.predictCollect(inv)
+		return
+	}
+	if !i.dragging || b != i.dragButton {
+		return
+	}
+	slots := i.dragSlots
+	i.dragging = false
+	i.dragSlots = nil
+	if len(slots) == 1 {
+		inv.click(slots[0], b, clickNormal)
+		i.predictClick(inv, slots[0], b)
+		return
+	}
+	// Drags are sent as a start packet, a packet per slot and
+	// then an end packet. Right click drags use buttons 4-6.
+	base := b * 4
+	inv.click(slotOutside, base, clickDrag)
+	for _, s := range slots {
+		inv.click(s, base+1, clickDrag)
+	}
+	inv.click(slotOutside, base+2, clickDrag)
+	i.predictDrag(inv, slots, b)
+}
+
+// updateDrag adds the hovered slot to the current drag if the held
+// item can be placed in it.
+func (i *inventoryScreen) updateDrag() {
+	inv := Client.activeInventory
+	slot := i.activeSlot
+	if !i.dragging || inv == nil || slot == -1 || i.cursorItem == nil {
+		return
+	}
+	if len(i.dragSlots) >= i.cursorItem.Count || inv.isOutput(slot) {
+		return
+	}
+	for _, s := range i.dragSlots {
+		if s == slot {
+			return
+		}
+	}
+	cur := inv.Items[slot]
+	if cur != nil && (!cur.stacksWith(i.cursorItem) || cur.Count >= cur.maxStack()) {
+		return
+	}
+	i.dragSlots = append(i.dragSlots, slot)
+}
+
+func (i *inventoryScreen) keyPress(key glfw.Key, mods glfw.ModifierKey) {
+	inv := Client.activeInventory
+	slot := i.activeSlot
+	// Keys only work on the hovered slot whilst nothing is held
+	if inv == nil || slot == -1 || i.cursorItem != nil {
+		return
+	}
+	switch {
+	case key >= glfw.Key1 && key <= glfw.Key9:
+		hotbar := int(key - glfw.Key1)
+		inv.click(slot, hotbar, clickHotbar)
+		i.predictHotbarSwap(inv, slot, inv.hotbarSlot(hotbar))
+	case key == glfw.KeyQ:
+		if inv.Items[slot] == nil {
+			return
+		}
+		b := 0
+		if mods&glfw.ModControl != 0 {
+			b = 1
+		}
+		inv.click(slot, b, clickDrop)
+		item := inv.Items[slot].clone()
+		item.Count--
+		if b == 1 || item.Count <= 0 {
+			item = nil
+		}
+		inv.setItem(slot, item)
+		i.setCursor(nil)
+	}
+}
+
+// predictClick handles a left (button 0) or right (button 1) click
+// on the slot.
+func (i *inventoryScreen) predictClick(inv *Inventory, slot, button int) {
+	item, cursor := clickResult(inv.Items[slot], i.cursorItem, button, inv.isOutput(slot))
+	inv.setItem(slot, item)
+	i.setCursor(cursor)
+}
+
+// clickResult returns the contents of a slot and the cursor after
+// a normal left (button 0) or right (button 1) click on the slot.
+func clickResult(item, cursor *ItemStack, button int, output bool) (*ItemStack, *ItemStack) {
+	item, cursor = item.clone(), cursor.clone()
+	switch {
+	case item == nil && cursor == nil:
+	case output:
+		// Results can only be taken as a whole
+		if item == nil {
+			break
+		}
+		if cursor == nil {
+			cursor, item = item, nil
+		} else if cursor.stacksWith(item) && cursor.Count+item.Count <= cursor.maxStack() {
+			cursor.Count += item.Count
+			item = nil
+		}
+	case cursor == nil:
+		if button == 0 {
+			cursor, item = item, nil
+			break
+		}
+		// Right click takes half, rounded up
+		cursor = item.clone()
+		cursor.Count = (item.Count + 1) / 2
+		item.Count -= cursor.Count
+	case item == nil:
+		if button == 0 {
+			item, cursor = cursor, nil
+			break
+		}
+		item = cursor.clone()
+		item.Count = 1
+		cursor.Count--
+	case item.stacksWith(cursor):
+		n := cursor.Count
+		if button == 1 {
+			n = 1
+		}
+		if space := item.maxStack() - item.Count; n > space {
+			n = space
+		}
+		item.Count += n
+		cursor.Count -= n
+	default:
+		item, cursor = cursor, item
+	}
+	if item != nil && item.Count <= 0 {
+		item = nil
+	}
+	if cursor != nil && cursor.Count <= 0 {
+		cursor = nil
+	}
+	return item, cursor
+}
+
+func (i *inventoryScreen) predictShiftClick(inv *Inventory, slot int) {
+	item := inv.Items[slot].clone()
+	l, ok := inv.Type.(inventoryLayout)
+	if item == nil || !ok {
+		return
+	}
+	inv.setItem(slot, nil)
+	for _, r := range l.shiftTargets(inv, slot, item) {
+		inv.mergeItem(item, r)
+	}
+	if item.Count > 0 {
+		inv.setItem(slot, item)
+	}
+	i.setCursor(i.cursorItem)
+}
+
+func (i *inventoryScreen) predictHotbarSwap(inv *Inventory, slot, hotbar int) {
+	if slot == hotbar {
+		return
+	}
+	item, held := inv.Items[slot], inv.Items[hotbar]
+	// Results can't have items put into them
+	if inv.isOutput(slot) && held != nil {
+		return
+	}
+	inv.setItem(slot, held)
+	inv.setItem(hotbar, item)
+	i.setCursor(i.cursorItem)
+}
+
+// predictDrag spreads the held item across the dragged slots. Left
+// click drags split the stack evenly, right click drags place a
+// single item in each slot.
+func (i *inventoryScreen) predictDrag(inv *Inventory, slots []int, button int) {
+	cursor := i.cursorItem.clone()
+	if cursor == nil {
+		return
+	}
+	per := 1
+	if button == 0 {
+		per = cursor.Count / len(slots)
+	}
+	for _, slot := range slots {
+		item := inv.Items[slot].clone()
+		if item == nil {
+			item = cursor.clone()
+			item.Count = 0
+		}
+		n := per
+		if space := item.maxStack() - item.Count; n > space {
+			n = space
+		}
+		if n > cursor.Count {
+			n = cursor.Count
+		}
+		item.Count += n
+		cursor.Count -= n
+		if item.Count > 0 {
+			inv.setItem(slot, item)
+		}
+	}
+	if cursor.Count <= 0 {
+		cursor = nil
+	}
+	i.setCursor(cursor)
+}
+
+// predictCollect fills the held stack with matching items from the
+// window, partial stacks are used before full ones.
+func (i *inventoryScreen) predictCollect(inv *Inventory) {
+	cursor := i.cursorItem.clone()
+	max := cursor.maxStack()
+	for pass := 0; pass < 2; pass++ {
+		for slot, item := range inv.Items {
+			if cursor.Count >= max {
+				break
+			}
+			if !item.stacksWith(cursor) || inv.isOutput(slot) {
+				continue
+			}
+			if pass == 0 && item.Count >= item.maxStack() {
+				continue
+			}
+			n := max - cursor.Count
+			if n > item.Count {
+				n = item.Count
+			}
+			item = item.clone()
+			item.Count -= n
+			cursor.Count += n
+			if item.Count <= 0 {
+				item = nil
+			}
+			inv.setItem(slot, item)
+		}
+	}
+	i.setCursor(cursor)
+}
+
+// armorSlot returns the slot in the player's inventory the item can
+// be worn in, or -1 if it isn't armor.
+func armorSlot(item *ItemStack) int {
+	name := item.Type.Name()
+	for i, suffix := range []string{"_helmet", "_chestplate", "_leggings", "_boots"} {
+		if strings.HasSuffix(name, suffix) {
+			return 5 + i
+		}
+	}
+	return -1
+}
+
+func (playerInventory) isOutput(slot int) bool { return slot == 0 }
+
+func (playerInventory) shiftTargets(inv *Inventory, slot int, item *ItemStack) []slotRange {
+	switch {
+	case slot == 0:
+		return []slotRange{{9, 45, true}}
+	case slot < 9:
+		return []slotRange{{9, 45, false}}
+	}
+	if a := armorSlot(item); a != -1 && inv.Items[a] == nil {
+		return []slotRange{{a, a + 1, false}}
+	}
+	return playerShiftTargets(9, slot)
+}
+
+func (c containerInventory) isOutput(slot int) bool {
+	for _, o := range c.outputs {
+		if o == slot {
+			return true
+		}
+	}
+	return false
+}
+
+func (c containerInventory) shiftTargets(inv *Inventory, slot int, item *ItemStack) []slotRange {
+	if slot < inv.playerSlots {
+		return []slotRange{{inv.playerSlots, len(inv.Items), c.shiftInto || c.isOutput(slot)}}
+	}
+	if c.shiftInto {
+		return []slotRange{{0, inv.playerSlots, false}}
+	}
+	return playerShiftTargets(inv.playerSlots, slot)
+}
+
+func (chestInventory) isOutput(slot int) bool { return false }
+
+func (chestInventory) shiftTargets(inv *Inventory, slot int, item *ItemStack) []slotRange {
+	if slot < inv.playerSlots {
+		return []slotRange{{inv.playerSlots, len(inv.Items), true}}
+	}
+	return []slotRange{{0, inv.playerSlots, false}}
+}
+
+func (horseInventory) isOutput(slot int) bool { return false }
+
+func (horseInventory) shiftTargets(inv *Inventory, slot int, item *ItemStack) []slotRange {
+	if slot < inv.playerSlots {
+		return []slotRange{{inv.playerSlots, len(inv.Items), false}}
+	}
+	if item.Type.Name() == "saddle" && inv.Items[0] == nil {
+		return []slotRange{{0, 1, false}}
+	}
+	if strings.HasSuffix(item.Type.Name(), "_horse_armor") && inv.Items[1] == nil {
+		return []slotRange{{1, 2, false}}
+	}
+	if inv.playerSlots > 2 {
+		return []slotRange{{2, inv.playerSlots, false}}
+	}
+	return playerShiftTargets(inv.playerSlots, slot)
+}
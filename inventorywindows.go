@@ -0,0 +1,285 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"fmt"
+
+	"github.com/thinkofdeath/steven/format"
+	"github.com/thinkofdeath/steven/protocol"
+	"github.com/thinkofdeath/steven/render"
+	"github.com/thinkofdeath/steven/ui"
+	"github.com/thinkofdeath/steven/ui/scene"
+)
+
+var (
+	InvChest     = chestInventory{}
+	InvHorse     = horseInventory{}
+	InvCrafting  = containerInventory{texture: "gui/container/crafting_table", width: 176, height: 166, player: [2]float64{8, 84}}
+	InvFurnace   = containerInventory{texture: "gui/container/furnace", width: 176, height: 166, player: [2]float64{8, 84}, progress: furnaceProgress}
+	InvDispenser = containerInventory{texture: "gui/container/dispenser", width: 176, height: 166, player: [2]float64{8, 84}}
+	InvHopper    = containerInventory{texture: "gui/container/hopper", width: 176, height: 133, player: [2]float64{8, 51}}
+	InvBrewing   = containerInventory{texture: "gui/container/brewing_stand", width: 176, height: 166, player: [2]float64{8, 84}, progress: brewingProgress}
+	InvEnchant   = containerInventory{texture: "gui/container/enchanting_table", width: 176, height: 166, player: [2]float64{8, 84}, progress: enchantingOptions}
+	InvAnvil     = containerInventory{texture: "gui/container/anvil", width: 176, height: 166, player: [2]float64{8, 84}, progress: anvilCost}
+	InvBeacon    = containerInventory{texture: "gui/container/beacon", width: 230, height: 219, player: [2]float64{36, 137}}
+	InvVillager  = containerInventory{texture: "gui/container/villager", width: 176, height: 166, player: [2]float64{8, 84}}
+)
+
+func init() {
+	InvCrafting.slots = [][2]float64{{124, 35}}
+	for i := 0; i < 9; i++ {
+		InvCrafting.slots = append(InvCrafting.slots, [2]float64{30 + 18*float64(i%3), 17 + 18*float64(i/3)})
+		InvDispenser.slots = append(InvDispenser.slots, [2]float64{62 + 18*float64(i%3), 17 + 18*float64(i/3)})
+	}
+	for i := 0; i < 5; i++ {
+		InvHopper.slots = append(InvHopper.slots, [2]float64{44 + 18*float64(i), 20})
+	}
+	InvFurnace.slots = [][2]float64{{56, 17}, {56, 53}, {116, 35}}
+	InvBrewing.slots = [][2]float64{{56, 46}, {79, 53}, {102, 46}, {79, 17}}
+	InvEnchant.slots = [][2]float64{{15, 47}, {35, 47}}
+	InvAnvil.slots = [][2]float64{{27, 47}, {76, 47}, {134, 47}}
+	InvBeacon.slots = [][2]float64{{136, 110}}
+	InvVillager.slots = [][2]float64{{36, 53}, {62, 53}, {120, 53}}
+
+	InvCrafting.outputs = []int{0}
+	InvFurnace.outputs = []int{2}
+	InvAnvil.outputs = []int{2}
+	InvVillager.outputs = []int{2}
+	InvDispenser.shiftInto = true
+	InvHopper.shiftInto = true
+}
+
+// windowType maps the type sent by the server when opening a window
+// to how it is displayed.
+type windowType struct {
+	ty InventoryType
+	// The number of slots the window has excluding the player's
+	// inventory, zero if the server's count should be used.
+	slots int
+}
+
+var windowTypes = map[string]windowType{
+	"minecraft:chest":            {ty: InvChest},
+	"minecraft:container":        {ty: InvChest},
+	"minecraft:crafting_table":   {InvCrafting, 10},
+	"minecraft:furnace":          {InvFurnace, 3},
+	"minecraft:dispenser":        {InvDispenser, 9},
+	"minecraft:dropper":          {InvDispenser, 9},
+	"minecraft:hopper":           {InvHopper, 5},
+	"minecraft:brewing_stand":    {InvBrewing, 4},
+	"minecraft:enchanting_table": {InvEnchant, 2},
+	"minecraft:anvil":            {InvAnvil, 3},
+	"minecraft:beacon":           {InvBeacon, 1},
+	"minecraft:villager":         {InvVillager, 3},
+	"EntityHorse":                {ty: InvHorse},
+}
+
+var inventoryLabel = format.Wrap(&format.TranslateComponent{Translate: "container.inventory"})
+
+// containerInventory is a window with a fixed layout of slots
+// followed by the player's inventory.
+type containerInventory struct {
+	texture       string
+	width, height float64
+	slots         [][2]float64
+	player        [2]float64
+	progress      func(s *scene.Type, inv *Inventory)
+	// Slots that hold the result of the window, e.g. crafting
+	outputs []int
+	// Whether shift clicking in the player's inventory moves
+	// the item into the window's slots
+	shiftInto bool
+}
+
+func (c containerInventory) Draw(s *scene.Type, inv *Inventory) {
+	background := drawInventoryBackground(s, inv, c.texture, c.width, c.height)
+	drawInventoryTitle(s, background, inv.Title, 8, 6)
+	drawInventoryTitle(s, background, inventoryLabel, c.player[0], c.player[1]-12)
+	for i, pos := range c.slots {
+		drawSlot(s, background, inv, i, pos[0], pos[1])
+	}
+	drawPlayerSlots(s, background, inv, c.player[0], c.player[1])
+}
+
+func (c containerInventory) DrawProperties(s *scene.Type, inv *Inventory) {
+	if c.progress != nil {
+		c.progress(s, inv)
+	}
+}
+
+// drawPlayerSlots adds the player's main inventory and hotbar to a
+// container window.
+func drawPlayerSlots(s *scene.Type, background *ui.Image, inv *Inventory, x, y float64) {
+	for i := 0; i < 27; i++ {
+		drawSlot(s, background, inv, inv.playerSlots+i, x+18*float64(i%9), y+18*float64(i/9))
+	}
+	for i := 0; i < 9; i++ {
+		drawSlot(s, background, inv, inv.playerSlots+27+i, x+18*float64(i), y+58)
+	}
+}
+
+// drawWindowPart draws part of the window's texture on top of the
+// background, e.g. the furnace's progress arrow.
+func drawWindowPart(s *scene.Type, inv *Inventory, texture string, x, y, tx, ty, w, h float64) *ui.Image {
+	img := ui.NewImage(
+		render.GetTexture(texture),
+		x*2, y*2, w*2, h*2,
+		tx/256.0, ty/256.0, w/256.0, h/256.0,
+		255, 255, 255,
+	)
+	img.AttachTo(inv.background)
+	img.SetLayer(1)
+	s.AddDrawable(img.Attach(ui.Top, ui.Left))
+	return img
+}
+
+func furnaceProgress(s *scene.Type, inv *Inventory) {
+	const texture = "gui/container/furnace"
+	burn, burnTotal := inv.Properties[0], inv.Properties[1]
+	if burnTotal == 0 {
+		burnTotal = 200
+	}
+	if burn > 0 {
+		flame := float64(burn * 13 / burnTotal)
+		drawWindowPart(s, inv, texture, 56, 36+12-flame, 176, 12-flame, 14, flame+1)
+	}
+	cook, cookTotal := inv.Properties[2], inv.Properties[3]
+	if cookTotal == 0 {
+		cookTotal = 200
+	}
+	if cook > 0 {
+		arrow := float64(cook * 24 / cookTotal)
+		drawWindowPart(s, inv, texture, 79, 34, 176, 14, arrow+1, 16)
+	}
+}
+
+func brewingProgress(s *scene.Type, inv *Inventory) {
+	const brewTime = 400
+	if time := inv.Properties[0]; time > 0 {
+		arrow := float64(28 * (brewTime - time) / brewTime)
+		drawWindowPart(s, inv, "gui/container/brewing_stand", 97, 16, 176, 0, 9, arrow)
+	}
+}
+
+// enchantingOptions draws the three enchantments on offer, properties
+// 0-2 hold the level each option costs.
+func enchantingOptions(s *scene.Type, inv *Inventory) {
+	const texture = "gui/container/enchanting_table"
+	for i := 0; i < 3; i++ {
+		i := i
+		cost := inv.Properties[i]
+		if cost <= 0 {
+			continue
+		}
+		y := 14 + 19*float64(i)
+		option := drawWindowPart(s, inv, texture, 60, y, 0, 166, 108, 19)
+		txt := ui.NewText(fmt.Sprint(cost), -4, 0, 128, 255, 32).Attach(ui.Middle, ui.Right)
+		txt.AttachTo(option)
+		s.AddDrawable(txt)
+
+		btn := ui.NewContainer(60*2, y*2, 108*2, 19*2)
+		btn.AttachTo(inv.background)
+		s.AddDrawable(btn.Attach(ui.Top, ui.Left))
+		btn.HoverFunc = func(over bool) {
+			if over {
+				option.SetTextureY(204 / 256.0)
+			} else {
+				option.SetTextureY(166 / 256.0)
+			}
+		}
+		btn.ClickFunc = func() {
+			Client.network.Write(&protocol.EnchantItem{
+				ID:          byte(inv.ID),
+				Enchantment: byte(i),
+			})
+		}
+	}
+}
+
+// anvilCost displays the level cost of the repair, stored in
+// property 0.
+func anvilCost(s *scene.Type, inv *Inventory) {
+	cost := inv.Properties[0]
+	if cost <= 0 {
+		return
+	}
+	var txt *ui.Text
+	if cost >= 40 && Client.GameMode != gmCreative {
+		txt = ui.NewText("Too Expensive!", 16, 67*2, 255, 96, 96)
+	} else {
+		txt = ui.NewText(fmt.Sprintf("Enchantment Cost: %d", cost), 16, 67*2, 128, 255, 32)
+	}
+	txt.AttachTo(inv.background)
+	s.AddDrawable(txt.Attach(ui.Top, ui.Right))
+}
+
+// chestInventory is a window with a variable number of rows. The
+// texture is split in two so that the rows can be cut down.
+type chestInventory struct{}
+
+func (chestInventory) Draw(s *scene.Type, inv *Inventory) {
+	rows := float64((inv.playerSlots + 8) / 9)
+	top := rows*18 + 17
+	height := top + 96
+
+	tex := render.GetTexture("gui/container/generic_54")
+	background := ui.NewImage(tex,
+		0, top-height, 176*2, top*2,
+		0, 0, 176/256.0, top/256.0,
+		255, 255, 255,
+	)
+	s.AddDrawable(background.Attach(ui.Middle, ui.Center))
+	inv.background = background
+	bottom := ui.NewImage(tex,
+		0, top*2, 176*2, 96*2,
+		0, 126/256.0, 176/256.0, 96/256.0,
+		255, 255, 255,
+	)
+	bottom.AttachTo(background)
+	s.AddDrawable(bottom.Attach(ui.Top, ui.Left))
+	addInventoryBounds(s, 176, height)
+
+	drawInventoryTitle(s, background, inv.Title, 8, 6)
+	drawInventoryTitle(s, background, inventoryLabel, 8, top+2)
+	for i := 0; i < inv.playerSlots; i++ {
+		drawSlot(s, background, inv, i, 8+18*float64(i%9), 18+18*float64(i/9))
+	}
+	drawPlayerSlots(s, background, inv, 8, top+14)
+}
+
+// horseInventory is the window for a horse's saddle and armor along
+// with the contents of its chest, if it has one.
+type horseInventory struct{}
+
+func (horseInventory) Draw(s *scene.Type, inv *Inventory) {
+	const texture = "gui/container/horse"
+	background := drawInventoryBackground(s, inv, texture, 176, 166)
+	drawInventoryTitle(s, background, inv.Title, 8, 6)
+	drawInventoryTitle(s, background, inventoryLabel, 8, 72)
+
+	// Saddle and armor
+	drawWindowPart(s, inv, texture, 7, 17, 18, 220, 18, 18)
+	drawWindowPart(s, inv, texture, 7, 35, 0, 220, 18, 18)
+	drawSlot(s, background, inv, 0, 8, 18)
+	drawSlot(s, background, inv, 1, 8, 36)
+	if inv.playerSlots > 2 {
+		drawWindowPart(s, inv, texture, 79, 17, 0, 166, 90, 54)
+		for i := 2; i < inv.playerSlots; i++ {
+			drawSlot(s, background, inv, i, 80+18*float64((i-2)%5), 18+18*float64((i-2)/5))
+		}
+	}
+	drawPlayerSlots(s, background, inv, 8, 84)
+}
@@ -15,6 +15,9 @@
 package steven
 
 import (
+	"reflect"
+	"strings"
+
 	"github.com/thinkofdeath/steven/encoding/nbt"
 	"github.com/thinkofdeath/steven/protocol"
 )
@@ -58,6 +61,112 @@ func ItemStackToProtocol(i *ItemStack) protocol.ItemStack {
 	}
 }
 
+// clone returns a copy of the stack that can be modified without
+// changing the original.
+func (i *ItemStack) clone() *ItemStack {
+	if i == nil {
+		return nil
+	}
+	c := *i
+	if i.rawTag != nil {
+		c.rawTag = i.rawTag.Copy()
+	}
+	return &c
+}
+
+// enchanted returns whether the item has any enchantments and
+// should be drawn with the enchantment glint.
+func (i *ItemStack) enchanted() bool {
+	if i == nil || i.rawTag == nil {
+		return false
+	}
+	ench, ok := i.rawTag.Items["ench"].(*nbt.List)
+	return ok && len(ench.Elements) > 0
+}
+
+// dyeColor returns the color the item has been dyed, e.g. for
+// leather armor.
+func (i *ItemStack) dyeColor() (r, g, b byte, ok bool) {
+	if i == nil || i.rawTag == nil {
+		return
+	}
+	display, ok := i.rawTag.Items["display"].(*nbt.Compound)
+	if !ok {
+		return
+	}
+	col, ok := display.Items["color"].(int32)
+	if !ok {
+		return
+	}
+	return byte(col >> 16), byte(col >> 8), byte(col), true
+}
+
+// itemStackSizes lists the items that stack to less than 64
+// which can't be worked out from their name.
+var itemStackSizes = map[string]int{
+	"ender_pearl":            16,
+	"snowball":               16,
+	"egg":                    16,
+	"sign":                   16,
+	"bucket":                 16,
+	"written_book":           16,
+	"banner":                 16,
+	"armor_stand":            16,
+	"flint_and_steel":        1,
+	"bow":                    1,
+	"fishing_rod":            1,
+	"carrot_on_a_stick":      1,
+	"shears":                 1,
+	"saddle":                 1,
+	"minecart":               1,
+	"boat":                   1,
+	"cake":                   1,
+	"bed":                    1,
+	"bottle_drinkable":       1,
+	"bottle_splash":          1,
+	"mushroom_stew":          1,
+	"rabbit_stew":            1,
+	"writable_book":          1,
+	"enchanted_book":         1,
+	"command_block_minecart": 1,
+}
+
+// Items with these suffixes can't be stacked, e.g. tools and
+// armor.
+var unstackableSuffixes = []string{
+	"_shovel", "_pickaxe", "_axe", "_hoe", "_sword",
+	"_helmet", "_chestplate", "_leggings", "_boots",
+	"_bucket", "_minecart", "_horse_armor",
+}
+
+// maxStack returns the most items of this type that can be held
+// in a single slot.
+func (i *ItemStack) maxStack() int {
+	name := i.Type.Name()
+	if n, ok := itemStackSizes[name]; ok {
+		return n
+	}
+	if strings.HasPrefix(name, "record_") {
+		return 1
+	}
+	for _, suffix := range unstackableSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return 1
+		}
+	}
+	return 64
+}
+
+// stacksWith returns whether the two stacks are the same item and
+// could be combined into a single stack.
+func (i *ItemStack) stacksWith(o *ItemStack) bool {
+	if i == nil || o == nil {
+		return false
+	}
+	return i.rawID == o.rawID && i.rawDamage == o.rawDamage &&
+		reflect.DeepEqual(i.rawTag, o.rawTag)
+}
+
 type ItemType interface {
 	Name() string
 	NameLocaleKey() string
@@ -83,6 +192,36 @@ func ItemById(id int) (ty ItemType) {
 	return ty
 }
 
+// validItemID returns whether the id is a known item or block.
+// ItemById panics for ids of unknown blocks so ids sent by the
+// server should be checked with this first.
+func validItemID(id int) bool {
+	if id >= 0 && id < len(blockSetsByID) {
+		return blockSetsByID[id] != nil
+	}
+	_, ok := itemsByID[id]
+	return ok
+}
+
+// itemByName returns the item type with the passed name
+// (e.g. minecraft:stone), the namespace is optional.
+func itemByName(name string) ItemType {
+	if strings.HasPrefix(name, "minecraft:") {
+		name = name[len("minecraft:"):]
+	}
+	for _, f := range itemsByID {
+		if it := f(); it.Name() == name {
+			return it
+		}
+	}
+	for _, bs := range blockSetsByID {
+		if bs != nil && bs.Base.Name() == name {
+			return ItemOfBlock(bs.Base)
+		}
+	}
+	return nil
+}
+
 type displayTag struct {
 	name string
 	lore []string
@@ -94,13 +233,13 @@ func (d *displayTag) ParseTag(tag *nbt.Compound) {
 		return
 	}
 	d.name, _ = display.Items["Name"].(string)
-	lore, ok := display.Items["Lore"].([]interface{})
+	lore, ok := display.Items["Lore"].(*nbt.List)
 	if !ok {
 		return
 	}
-	d.lore = make([]string, len(lore))
-	for i := range lore {
-		d.lore[i], _ = lore[i].(string)
+	d.lore = make([]string, len(lore.Elements))
+	for i := range lore.Elements {
+		d.lore[i], _ = lore.Elements[i].(string)
 	}
 }
 func (d *displayTag) DisplayName() string { return d.name }
@@ -58,7 +58,7 @@ var itemsByID = map[int]func() ItemType{
 		return i
 	},
 	263: func() ItemType {
-		i := &itemBasic{}
+		i := &itemVariants{variants: coalVariants}
 		i.locale = "item.coal.name"
 		i.itemNamed.name = "coal"
 		return i
@@ -417,7 +417,7 @@ var itemsByID = map[int]func() ItemType{
 		return i
 	},
 	322: func() ItemType {
-		i := &itemBasic{}
+		i := &itemVariants{variants: goldenAppleVariants}
 		i.locale = "item.appleGold.name"
 		i.itemNamed.name = "golden_apple"
 		return i
@@ -579,19 +579,19 @@ var itemsByID = map[int]func() ItemType{
 		return i
 	},
 	349: func() ItemType {
-		i := &itemBasic{}
+		i := &itemVariants{variants: fishVariants}
 		i.locale = "item.fish.name"
 		i.itemNamed.name = "fish"
 		return i
 	},
 	350: func() ItemType {
-		i := &itemBasic{}
+		i := &itemVariants{variants: cookedFishVariants}
 		i.locale = "item.fish.name"
 		i.itemNamed.name = "cooked_fish"
 		return i
 	},
 	351: func() ItemType {
-		i := &itemBasic{}
+		i := &itemVariants{variants: dyeVariants}
 		i.locale = "item.dyePowder.name"
 		i.itemNamed.name = "dye"
 		return i
@@ -633,7 +633,7 @@ var itemsByID = map[int]func() ItemType{
 		return i
 	},
 	358: func() ItemType {
-		i := &itemBasic{}
+		i := &itemMap{}
 		i.locale = "item.map.name"
 		i.itemNamed.name = "filled_map"
 		return i
@@ -723,7 +723,7 @@ var itemsByID = map[int]func() ItemType{
 		return i
 	},
 	373: func() ItemType {
-		i := &itemBasic{}
+		i := &itemPotion{}
 		i.locale = "item.potion.name"
 		i.itemNamed.name = "potion"
 		return i
@@ -783,7 +783,7 @@ var itemsByID = map[int]func() ItemType{
 		return i
 	},
 	383: func() ItemType {
-		i := &itemBasic{}
+		i := &itemVariants{variants: spawnEggVariants}
 		i.locale = "item.monsterPlacer.name"
 		i.itemNamed.name = "spawn_egg"
 		return i
@@ -867,7 +867,7 @@ var itemsByID = map[int]func() ItemType{
 		return i
 	},
 	397: func() ItemType {
-		i := &itemBasic{}
+		i := &itemVariants{variants: skullVariants}
 		i.locale = "item.skull.name"
 		i.itemNamed.name = "skull"
 		return i
@@ -1035,7 +1035,7 @@ var itemsByID = map[int]func() ItemType{
 		return i
 	},
 	425: func() ItemType {
-		i := &itemBasic{}
+		i := &itemVariants{variants: bannerVariants}
 		i.locale = "tile.banner.name"
 		i.itemNamed.name = "banner"
 		return i
@@ -0,0 +1,186 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import "fmt"
+
+// ItemVariants is implemented by items that use their damage
+// value to pick between variants.
+type ItemVariants interface {
+	// Variants returns the damage values of the variants in the
+	// order vanilla lists them in the creative inventory.
+	Variants() []int16
+}
+
+// itemVariant is a variant of an item with its own model and
+// name.
+type itemVariant struct {
+	damage int16
+	name   string
+	locale string
+}
+
+// itemVariants is an item where the damage value picks the
+// variant, e.g. the color of dye. Unknown damage values use the
+// first variant.
+type itemVariants struct {
+	itemBasic
+	variants []itemVariant
+}
+
+func (i *itemVariants) ParseDamage(d int16) {
+	v := i.variants[0]
+	for _, vv := range i.variants {
+		if vv.damage == d {
+			v = vv
+			break
+		}
+	}
+	i.itemNamed.name = v.name
+	if v.locale != "" {
+		i.locale = v.locale
+	}
+}
+
+func (i *itemVariants) Variants() []int16 {
+	damages := make([]int16, len(i.variants))
+	for j, v := range i.variants {
+		damages[j] = v.damage
+	}
+	return damages
+}
+
+var coalVariants = []itemVariant{
+	{0, "coal", "item.coal.name"},
+	{1, "charcoal", "item.charcoal.name"},
+}
+
+// The enchanted golden apple shares the model of the normal one.
+var goldenAppleVariants = []itemVariant{
+	{0, "golden_apple", ""},
+	{1, "golden_apple", ""},
+}
+
+var fishVariants = []itemVariant{
+	{0, "fish_cod_raw", "item.fish.cod.raw.name"},
+	{1, "fish_salmon_raw", "item.fish.salmon.raw.name"},
+	{2, "fish_clownfish_raw", "item.fish.clownfish.raw.name"},
+	{3, "fish_pufferfish_raw", "item.fish.pufferfish.raw.name"},
+}
+
+var cookedFishVariants = []itemVariant{
+	{0, "fish_cod_cooked", "item.fish.cod.cooked.name"},
+	{1, "fish_salmon_cooked", "item.fish.salmon.cooked.name"},
+}
+
+var skullVariants = []itemVariant{
+	{0, "skull_skeleton", "item.skull.skeleton.name"},
+	{1, "skull_wither", "item.skull.wither.name"},
+	{2, "skull_zombie", "item.skull.zombie.name"},
+	{3, "skull_char", "item.skull.char.name"},
+	{4, "skull_creeper", "item.skull.creeper.name"},
+}
+
+// Spawn eggs all share a model, the entity is only used to
+// color it.
+var spawnEggVariants = func() (variants []itemVariant) {
+	ids := []int16{
+		50, 51, 52, 54, 55, 56, 57, 58, 59, 60, 61, 62, 65, 66, 67, 68,
+		90, 91, 92, 93, 94, 95, 96, 98, 100, 101, 120,
+	}
+	for _, id := range ids {
+		variants = append(variants, itemVariant{id, "spawn_egg", ""})
+	}
+	return variants
+}()
+
+// colorNames are the names of the 16 colors in damage value order
+// as used by the models and the locale keys.
+var colorNames = [16]struct{ model, locale string }{
+	{"black", "black"}, {"red", "red"}, {"green", "green"},
+	{"brown", "brown"}, {"blue", "blue"}, {"purple", "purple"},
+	{"cyan", "cyan"}, {"silver", "silver"}, {"gray", "gray"},
+	{"pink", "pink"}, {"lime", "lime"}, {"yellow", "yellow"},
+	{"light_blue", "lightBlue"}, {"magenta", "magenta"},
+	{"orange", "orange"}, {"white", "white"},
+}
+
+var dyeVariants = func() (variants []itemVariant) {
+	for i, c := range colorNames {
+		variants = append(variants, itemVariant{
+			int16(i), "dye_" + c.model, fmt.Sprintf("item.dyePowder.%s.name", c.locale),
+		})
+	}
+	return variants
+}()
+
+// Banners all share a model, the colors come from the item's
+// tag.
+var bannerVariants = func() (variants []itemVariant) {
+	for i, c := range colorNames {
+		variants = append(variants, itemVariant{
+			int16(i), "banner", fmt.Sprintf("item.banner.%s.name", c.locale),
+		})
+	}
+	return variants
+}()
+
+// Potions that can be picked from the creative inventory, water
+// followed by the drinkable and then the splash version of each
+// potion.
+var creativePotions = func() []int16 {
+	potions := []int16{
+		8193, 8257, 8225, // Regeneration
+		8194, 8258, 8226, // Swiftness
+		8195, 8259, // Fire resistance
+		8196, 8260, 8228, // Poison
+		8197, 8229, // Healing
+		8198, 8262, // Night vision
+		8200, 8264, // Weakness
+		8201, 8265, 8233, // Strength
+		8202, 8266, // Slowness
+		8203, 8267, 8235, // Leaping
+		8204, 8236, // Harming
+		8205, 8269, // Water breathing
+		8206, 8270, // Invisibility
+	}
+	damages := []int16{0}
+	damages = append(damages, potions...)
+	for _, p := range potions {
+		damages = append(damages, p&^potionDrinkable|potionSplash)
+	}
+	return damages
+}()
+
+const (
+	potionDrinkable = 0x2000
+	potionSplash    = 0x4000
+)
+
+// itemPotion is a potion, the model depends on whether the potion
+// is a splash potion.
+type itemPotion struct {
+	itemBasic
+}
+
+func (i *itemPotion) ParseDamage(d int16) {
+	if d&potionSplash != 0 {
+		i.itemNamed.name = "bottle_splash"
+	} else {
+		i.itemNamed.name = "bottle_drinkable"
+	}
+}
+
+func (i *itemPotion) Variants() []int16 { return creativePotions }
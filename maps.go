@@ -0,0 +1,333 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"fmt"
+	"image"
+	icolor "image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/thinkofdeath/steven/console"
+	"github.com/thinkofdeath/steven/protocol"
+	"github.com/thinkofdeath/steven/render"
+	"github.com/thinkofdeath/steven/resource"
+	"github.com/thinkofdeath/steven/type/direction"
+)
+
+const (
+	mapSize      = 128
+	mapExportDir = "maps/"
+)
+
+func init() {
+	console.Register("map_export %", exportMap)
+}
+
+// mapBaseColors is the vanilla map colour table. Each colour
+// has 4 shades selected by the lower 2 bits of the colour id.
+var mapBaseColors = [...]uint32{
+	0x000000, // Transparent
+	0x7FB238, // Grass
+	0xF7E9A3, // Sand
+	0xC7C7C7, // Cloth
+	0xFF0000, // TNT
+	0xA0A0FF, // Ice
+	0xA7A7A7, // Iron
+	0x007C00, // Foliage
+	0xFFFFFF, // Snow
+	0xA4A8B8, // Clay
+	0x976D4D, // Dirt
+	0x707070, // Stone
+	0x4040FF, // Water
+	0x8F7748, // Wood
+	0xFFFCF5, // Quartz
+	0xD87F33, // Orange
+	0xB24CD8, // Magenta
+	0x6699D8, // Light blue
+	0xE5E533, // Yellow
+	0x7FCC19, // Lime
+	0xF27FA5, // Pink
+	0x4C4C4C, // Gray
+	0x999999, // Silver
+	0x4C7F99, // Cyan
+	0x7F3FB2, // Purple
+	0x334CB2, // Blue
+	0x664C33, // Brown
+	0x667F33, // Green
+	0x993333, // Red
+	0x191919, // Black
+	0xFAEE4D, // Gold
+	0x5CDBD5, // Diamond
+	0x4A80FF, // Lapis
+	0x00D93A, // Emerald
+	0x815631, // Podzol
+	0x700200, // Netherrack
+}
+
+var mapShades = [4]uint32{180, 220, 255, 135}
+
+// mapColor converts a map colour id into its colour
+func mapColor(id byte) icolor.NRGBA {
+	base := int(id >> 2)
+	if base == 0 || base >= len(mapBaseColors) {
+		return icolor.NRGBA{}
+	}
+	col := mapBaseColors[base]
+	shade := mapShades[id&3]
+	return icolor.NRGBA{
+		R: byte((col >> 16 & 0xFF) * shade / 255),
+		G: byte((col >> 8 & 0xFF) * shade / 255),
+		B: byte((col & 0xFF) * shade / 255),
+		A: 255,
+	}
+}
+
+// mapIcon is a decoration drawn on top of a map (players,
+// item frames etc)
+type mapIcon struct {
+	Type, Direction int
+	X, Z            int
+}
+
+// mapData is the client's copy of a single map
+type mapData struct {
+	id     int
+	scale  int
+	colors [mapSize * mapSize]byte
+	icons  []mapIcon
+
+	hasIcon bool
+}
+
+var (
+	maps         = map[int]*mapData{}
+	mapIconImage image.Image
+)
+
+func mapIconName(id int) string {
+	return fmt.Sprintf("steven:map/%d", id)
+}
+
+// getMap returns the map with the passed id, creating an empty
+// one if it hasn't been received yet.
+func getMap(id int) *mapData {
+	m, ok := maps[id]
+	if !ok {
+		m = &mapData{id: id}
+		maps[id] = m
+	}
+	return m
+}
+
+func freeMaps() {
+	for _, m := range maps {
+		if m.hasIcon {
+			render.FreeIcon(mapIconName(m.id))
+		}
+	}
+	maps = map[int]*mapData{}
+}
+
+func (m *mapData) update(p *protocol.Maps) {
+	m.scale = int(p.Scale)
+	m.icons = m.icons[:0]
+	for _, i := range p.Icons {
+		m.icons = append(m.icons, mapIcon{
+			Type:      int(i.DirectionType>>4) & 0xF,
+			Direction: int(i.DirectionType) & 0xF,
+			X:         int(i.X),
+			Z:         int(i.Z),
+		})
+	}
+	// Only a section of the map may be sent
+	cols, rows := int(p.Columns), int(p.Rows)
+	for x := 0; x < cols; x++ {
+		for z := 0; z < rows; z++ {
+			mx, mz := x+int(p.X), z+int(p.Z)
+			idx := x + z*cols
+			if mx >= mapSize || mz >= mapSize || idx >= len(p.Data) {
+				continue
+			}
+			m.colors[mx+mz*mapSize] = p.Data[idx]
+		}
+	}
+	m.refresh()
+}
+
+// image renders the map to an image including its icons
+func (m *mapData) image() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, mapSize, mapSize))
+	for i, c := range m.colors {
+		img.SetNRGBA(i%mapSize, i/mapSize, mapColor(c))
+	}
+	icons := loadMapIcons()
+	if icons == nil {
+		return img
+	}
+	for _, i := range m.icons {
+		// Icons are stored in a 4x4 grid of 8x8 sprites and
+		// positioned between -128 and 127.
+		sx, sy := (i.Type%4)*8, (i.Type/4)*8
+		cx, cz := float64(i.X+128)/2, float64(i.Z+128)/2
+		ang := float64(i.Direction) * (math.Pi * 2 / 16)
+		sin, cos := math.Sincos(-ang)
+		for y := -6; y < 6; y++ {
+			for x := -6; x < 6; x++ {
+				// Rotate back into the sprite's space
+				fx := float64(x) + 0.5
+				fy := float64(y) + 0.5
+				ix := int(math.Floor(fx*cos-fy*sin)) + 4
+				iy := int(math.Floor(fx*sin+fy*cos)) + 4
+				if ix < 0 || ix >= 8 || iy < 0 || iy >= 8 {
+					continue
+				}
+				col := icolor.NRGBAModel.Convert(icons.At(sx+ix, sy+iy)).(icolor.NRGBA)
+				if col.A == 0 {
+					continue
+				}
+				px, py := int(cx)+x, int(cz)+y
+				if px < 0 || px >= mapSize || py < 0 || py >= mapSize {
+					continue
+				}
+				img.SetNRGBA(px, py, col)
+			}
+		}
+	}
+	return img
+}
+
+// refresh updates the map's texture
+func (m *mapData) refresh() {
+	img := m.image()
+	if !m.hasIcon {
+		render.AddIcon(mapIconName(m.id), img)
+		m.hasIcon = true
+		return
+	}
+	render.UpdateIcon(mapIconName(m.id), img)
+}
+
+// texture returns the texture for the map creating it if
+// required.
+func (m *mapData) texture() render.TextureInfo {
+	if !m.hasIcon {
+		m.refresh()
+	}
+	return render.Icon(mapIconName(m.id))
+}
+
+func loadMapIcons() image.Image {
+	if mapIconImage != nil {
+		return mapIconImage
+	}
+	f, err := resource.Open("minecraft", "textures/map/map_icons.png")
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil
+	}
+	// The texture may be a higher resolution than vanilla's
+	if img.Bounds().Dx() != 32 {
+		scaled := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+		w, h := img.Bounds().Dx(), img.Bounds().Dy()
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 32; x++ {
+				scaled.Set(x, y, img.At(x*w/32, y*h/32))
+			}
+		}
+		img = scaled
+	}
+	mapIconImage = img
+	return img
+}
+
+// createMapModel creates the vertices for a flat map with the map's
+// contents on one side and the background of the map on the other.
+// The map is centered on 0,0,0 with a width and height of 1.
+func createMapModel(id int) (verts []*render.StaticVertex) {
+	m := getMap(id)
+	tex := m.texture()
+	bg := render.GetTexture("map/map_background")
+	for _, side := range []struct {
+		dir direction.Type
+		tex render.TextureInfo
+		z   float32
+	}{
+		{direction.North, tex, -1 / 64.0},
+		{direction.South, bg, 1 / 64.0},
+	} {
+		for _, v := range faceVertices[side.dir].verts {
+			verts = append(verts, &render.StaticVertex{
+				X:        float32(v.X) - 0.5,
+				Y:        float32(v.Y) - 0.5,
+				Z:        side.z,
+				Texture:  side.tex,
+				TextureX: float64(v.TOffsetX),
+				TextureY: float64(v.TOffsetY),
+				R:        255,
+				G:        255,
+				B:        255,
+				A:        255,
+			})
+		}
+	}
+	return verts
+}
+
+func exportMap(id int) {
+	m, ok := maps[id]
+	if !ok {
+		console.Text("Unknown map %d", id)
+		return
+	}
+	path := filepath.Join(mapExportDir, fmt.Sprintf("map_%d.png", id))
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		console.Text("Failed to create the map export directory: %s", err)
+		return
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		console.Text("Failed to export map: %s", err)
+		return
+	}
+	defer f.Close()
+	if err := png.Encode(f, m.image()); err != nil {
+		console.Text("Failed to export map: %s", err)
+		return
+	}
+	console.Text("Exported map %d to %s", id, path)
+}
+
+// itemMap is a filled map, the damage value of the item is the id
+// of the map.
+type itemMap struct {
+	itemBasic
+	id int16
+}
+
+func (i *itemMap) ParseDamage(d int16) { i.id = d }
+func (i *itemMap) MapID() int          { return int(i.id) }
+
+// ItemMap is implemented by items that display a map
+type ItemMap interface {
+	MapID() int
+}
@@ -26,6 +26,8 @@ type processedModel struct {
 	faces            []processedFace
 	ambientOcclusion bool
 	weight           int
+	// Texture used for particles created by the block
+	particle render.TextureInfo
 }
 
 type processedFace struct {
@@ -72,6 +74,9 @@ func precomputeModel(bm *model) *processedModel {
 	p := &processedModel{}
 	p.ambientOcclusion = bm.ambientOcclusion
 	p.weight = bm.weight
+	if _, ok := bm.textureVars["particle"]; ok {
+		p.particle = bm.lookupTexture("#particle")
+	}
 	for _, el := range bm.elements {
 		for i, face := range el.faces {
 			faceID := direction.Type(i)
@@ -0,0 +1,774 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/thinkofdeath/steven/protocol"
+	"github.com/thinkofdeath/steven/render"
+	"github.com/thinkofdeath/steven/type/direction"
+)
+
+// Limits the number of particles alive at once, new particles
+// are dropped once this is reached.
+const maxParticles = 4000
+
+// particle is a single simulated particle. Velocities are in
+// blocks per a tick and times are in ticks to match vanilla.
+type particle struct {
+	x, y, z    float64
+	vx, vy, vz float64
+	// Multiplier for the amount gravity effects the particle,
+	// negative values cause the particle to rise.
+	gravity float64
+	// Multiplier applied to the velocity each tick
+	drag float64
+	// Applied upwards each tick (smoke etc)
+	rise float64
+
+	age, life  float64
+	size       float64
+	r, g, b, a float64
+	// Ignores the light level of the world
+	emissive bool
+
+	tex render.TextureInfo
+	// When animFrames is set the particle steps backwards through
+	// the particle sheet from animStart over its lifetime.
+	animStart, animFrames int
+	// Shrinks the particle as it ages (flames)
+	shrink bool
+
+	collide, onGround bool
+	// Removes the particle once it lands (drips/rain)
+	dieOnGround bool
+}
+
+// particleSystem simulates all particles on the cpu and draws
+// them in a single model.
+type particleSystem struct {
+	particles []*particle
+	verts     []*render.StaticVertex
+	model     *render.StaticModel
+}
+
+func (ps *particleSystem) free() {
+	if ps.model != nil {
+		ps.model.Free()
+		ps.model = nil
+	}
+	ps.particles = nil
+}
+
+func (ps *particleSystem) add(p *particle) {
+	if len(ps.particles) >= maxParticles {
+		return
+	}
+	if p.drag == 0 {
+		p.drag = 0.98
+	}
+	if p.a == 0 {
+		p.a = 1
+	}
+	if p.animFrames > 0 {
+		p.tex = particleTexture(p.animStart)
+	}
+	ps.particles = append(ps.particles, p)
+}
+
+func (ps *particleSystem) tick(delta float64) {
+	dt := delta / 3
+	live := ps.particles[:0]
+	for _, p := range ps.particles {
+		if p.tick(dt) {
+			live = append(live, p)
+		}
+	}
+	for i := len(live); i < len(ps.particles); i++ {
+		ps.particles[i] = nil
+	}
+	ps.particles = live
+	ps.draw()
+}
+
+func (p *particle) tick(dt float64) bool {
+	p.age += dt
+	if p.age >= p.life {
+		return false
+	}
+	p.vy -= 0.04 * p.gravity * dt
+	p.vy += p.rise * dt
+	p.move(p.vx*dt, p.vy*dt, p.vz*dt)
+	if p.dieOnGround && p.onGround {
+		return false
+	}
+	f := math.Pow(p.drag, dt)
+	p.vx *= f
+	p.vy *= f
+	p.vz *= f
+	if p.onGround {
+		f = math.Pow(0.7, dt)
+		p.vx *= f
+		p.vz *= f
+	}
+	if p.animFrames > 0 {
+		frame := int(p.age * float64(p.animFrames) / p.life)
+		p.tex = particleTexture(p.animStart - frame)
+	}
+	return true
+}
+
+// move moves the particle handling each axis separately so
+// that it slides along blocks it hits.
+func (p *particle) move(dx, dy, dz float64) {
+	if !p.collide {
+		p.x += dx
+		p.y += dy
+		p.z += dz
+		return
+	}
+	p.x += dx
+	if particleCollides(p.x, p.y, p.z) {
+		p.x -= dx
+		p.vx = 0
+	}
+	p.y += dy
+	if particleCollides(p.x, p.y, p.z) {
+		p.y -= dy
+		p.onGround = dy < 0
+		p.vy = 0
+	} else if dy != 0 {
+		p.onGround = false
+	}
+	p.z += dz
+	if particleCollides(p.x, p.y, p.z) {
+		p.z -= dz
+		p.vz = 0
+	}
+}
+
+func particleCollides(x, y, z float64) bool {
+	bx, by, bz := int(math.Floor(x)), int(math.Floor(y)), int(math.Floor(z))
+	b := chunkMap.Block(bx, by, bz)
+	if !b.Collidable() {
+		return false
+	}
+	x, y, z = x-float64(bx), y-float64(by), z-float64(bz)
+	for _, bb := range b.CollisionBounds() {
+		if x >= float64(bb.Min.X()) && x <= float64(bb.Max.X()) &&
+			y >= float64(bb.Min.Y()) && y <= float64(bb.Max.Y()) &&
+			z >= float64(bb.Min.Z()) && z <= float64(bb.Max.Z()) {
+			return true
+		}
+	}
+	return false
+}
+
+// draw rebuilds the model for the particles. Each particle is
+// a quad that faces the camera.
+func (ps *particleSystem) draw() {
+	count := len(ps.particles) * 4
+	for len(ps.verts) < count {
+		ps.verts = append(ps.verts, &render.StaticVertex{})
+	}
+	cx, cy, cz := render.Camera.X, render.Camera.Y, render.Camera.Z
+	i := 0
+	for _, p := range ps.particles {
+		yaw := math.Atan2(p.x-cx, p.z-cz)
+		pitch := -math.Atan2(cy-p.y, math.Hypot(p.x-cx, p.z-cz))
+		sinY, cosY := math.Sincos(yaw)
+		sinP, cosP := math.Sincos(pitch)
+
+		size := p.size * 0.1
+		if p.shrink {
+			f := p.age / p.life
+			size *= 1 - f*f*0.5
+		}
+
+		r, g, b := p.r, p.g, p.b
+		if !p.emissive {
+			bx, by, bz := int(math.Floor(p.x)), int(math.Floor(p.y)), int(math.Floor(p.z))
+			lr, lg, lb := lightColor(
+				float64(chunkMap.BlockLight(bx, by, bz)),
+				float64(chunkMap.SkyLight(bx, by, bz)),
+			)
+			r, g, b = r*lr, g*lg, b*lb
+		}
+
+		for _, v := range faceVertices[direction.North].verts {
+			lx := (float64(v.X)*2 - 1) * size
+			ly := (float64(v.Y)*2 - 1) * size
+			vert := ps.verts[i]
+			i++
+			vert.X = float32(p.x + lx*cosY - ly*sinP*sinY)
+			vert.Y = float32(p.y + ly*cosP)
+			vert.Z = float32(p.z - lx*sinY - ly*sinP*cosY)
+			vert.Texture = p.tex
+			vert.TextureX = float64(v.TOffsetX)
+			vert.TextureY = float64(v.TOffsetY)
+			vert.R = byte(255 * clampFloat(r, 0, 1))
+			vert.G = byte(255 * clampFloat(g, 0, 1))
+			vert.B = byte(255 * clampFloat(b, 0, 1))
+			vert.A = byte(255 * clampFloat(p.a, 0, 1))
+		}
+	}
+	if ps.model == nil {
+		if count == 0 {
+			return
+		}
+		ps.model = render.NewStaticModel([][]*render.StaticVertex{ps.verts[:count]})
+		// Lighting is handled per a particle
+		ps.model.BlockLight, ps.model.SkyLight = 15, 0
+		return
+	}
+	ps.model.Verts = ps.verts[:count]
+	ps.model.Refresh()
+}
+
+// lightColor matches the lighting done by the shaders for the
+// passed light levels.
+func lightColor(blockLight, skyLight float64) (r, g, b float64) {
+	level := float64(render.LightLevel)
+	skyOffset := float64(render.SkyOffset)
+	bl := math.Pow(level, 15-blockLight)
+	sk := math.Pow(level, 15-skyLight) * (skyOffset*0.95 + 0.05)
+
+	r = sk*(skyOffset*0.65+0.35) + bl
+	g = sk*(skyOffset*0.65+0.35) + bl*((bl*0.6+0.4)*0.6+0.4)
+	b = sk + bl*(bl*bl*0.6+0.4)
+	r = clampFloat((r*0.96+0.03)*0.96+0.03, 0, 1)
+	g = clampFloat((g*0.96+0.03)*0.96+0.03, 0, 1)
+	b = clampFloat((b*0.96+0.03)*0.96+0.03, 0, 1)
+	return
+}
+
+// particleTexture returns the texture at the index in the
+// particle sheet. The sheet is a 16x16 grid.
+func particleTexture(index int) render.TextureInfo {
+	if index < 0 {
+		index = 0
+	}
+	return render.RelativeTexture(render.GetTexture("particle/particles"), 16, 16).
+		Sub(index%16, index/16, 1, 1)
+}
+
+// fragmentTexture returns a random quarter of the passed texture
+// used by particles that are pieces of blocks or items.
+func fragmentTexture(tex render.TextureInfo) render.TextureInfo {
+	return render.RelativeTexture(tex, 16, 16).
+		Sub(rand.Intn(12), rand.Intn(12), 4, 4)
+}
+
+// blockParticleTexture returns the texture used for particles of
+// the passed block.
+func blockParticleTexture(b Block) render.TextureInfo {
+	for _, m := range b.Models() {
+		if m.particle != nil {
+			return m.particle
+		}
+	}
+	return nil
+}
+
+// itemParticleTexture returns the texture used for particles of
+// the passed item.
+func itemParticleTexture(it ItemType) render.TextureInfo {
+	if bi, ok := it.(*blockItem); ok {
+		if tex := blockParticleTexture(bi.block); tex != nil {
+			return tex
+		}
+	}
+	mdl := getModel(it.Name())
+	if mdl == nil {
+		return nil
+	}
+	if _, ok := mdl.textureVars["layer0"]; ok {
+		return mdl.lookupTexture("#layer0")
+	}
+	if _, ok := mdl.textureVars["particle"]; ok {
+		return mdl.lookupTexture("#particle")
+	}
+	return nil
+}
+
+// blockFromStateID converts vanilla's state id (used by particles
+// and effects) into a block.
+func blockFromStateID(id int) Block {
+	return GetBlockByCombinedID(uint16((id&0xFFF)<<4 | (id>>12)&0xF))
+}
+
+func randRange(r float64) float64 {
+	return (rand.Float64()*2 - 1) * r
+}
+
+// newBlockParticle creates a particle that is a fragment of the
+// passed block.
+func newBlockParticle(b Block, x, y, z, vx, vy, vz float64) *particle {
+	tex := blockParticleTexture(b)
+	if tex == nil {
+		return nil
+	}
+	r, g, bl := b.TintColor()
+	return &particle{
+		x: x, y: y, z: z,
+		vx: vx, vy: vy, vz: vz,
+		tex:     fragmentTexture(tex),
+		gravity: 1,
+		size:    (rand.Float64()*0.5 + 0.5),
+		life:    4 / (rand.Float64()*0.9 + 0.1),
+		r:       0.6 * float64(r) / 255,
+		g:       0.6 * float64(g) / 255,
+		b:       0.6 * float64(bl) / 255,
+		collide: true,
+	}
+}
+
+// randomVelocity matches vanilla's default velocity for particles
+// which is the passed velocity with some randomness.
+func randomVelocity(vx, vy, vz float64) (float64, float64, float64) {
+	mx, my, mz := vx+randRange(0.4), vy+randRange(0.4), vz+randRange(0.4)
+	speed := (rand.Float64() + rand.Float64() + 1) * 0.15
+	l := math.Sqrt(mx*mx + my*my + mz*mz)
+	if l == 0 {
+		l = 1
+	}
+	return mx / l * speed * 0.4, my/l*speed*0.4 + 0.1, mz / l * speed * 0.4
+}
+
+// spawnBlockBreak spawns the particles for a block being broken
+func (ps *particleSystem) spawnBlockBreak(b Block, pos Position) {
+	if blockParticleTexture(b) == nil {
+		return
+	}
+	const count = 4
+	for x := 0; x < count; x++ {
+		for y := 0; y < count; y++ {
+			for z := 0; z < count; z++ {
+				px := float64(pos.X) + (float64(x)+0.5)/count
+				py := float64(pos.Y) + (float64(y)+0.5)/count
+				pz := float64(pos.Z) + (float64(z)+0.5)/count
+				vx, vy, vz := randomVelocity(
+					px-float64(pos.X)-0.5,
+					py-float64(pos.Y)-0.5,
+					pz-float64(pos.Z)-0.5,
+				)
+				if p := newBlockParticle(b, px, py, pz, vx, vy, vz); p != nil {
+					ps.add(p)
+				}
+			}
+		}
+	}
+}
+
+// spawnBlockHit spawns a particle on the face of a block being
+// hit by the player.
+func (ps *particleSystem) spawnBlockHit(b Block, pos Position, face direction.Type) {
+	bounds := b.CollisionBounds()
+	minX, minY, minZ, maxX, maxY, maxZ := 0.0, 0.0, 0.0, 1.0, 1.0, 1.0
+	if len(bounds) > 0 {
+		bb := bounds[0]
+		minX, minY, minZ = float64(bb.Min.X()), float64(bb.Min.Y()), float64(bb.Min.Z())
+		maxX, maxY, maxZ = float64(bb.Max.X()), float64(bb.Max.Y()), float64(bb.Max.Z())
+	}
+	x := float64(pos.X) + minX + rand.Float64()*(maxX-minX)
+	y := float64(pos.Y) + minY + rand.Float64()*(maxY-minY)
+	z := float64(pos.Z) + minZ + rand.Float64()*(maxZ-minZ)
+	switch face {
+	case direction.Down:
+		y = float64(pos.Y) + minY - 0.1
+	case direction.Up:
+		y = float64(pos.Y) + maxY + 0.1
+	case direction.North:
+		z = float64(pos.Z) + minZ - 0.1
+	case direction.South:
+		z = float64(pos.Z) + maxZ + 0.1
+	case direction.West:
+		x = float64(pos.X) + minX - 0.1
+	case direction.East:
+		x = float64(pos.X) + maxX + 0.1
+	}
+	vx, vy, vz := randomVelocity(0, 0, 0)
+	p := newBlockParticle(b, x, y, z, vx*0.2, (vy-0.1)*0.2+0.1, vz*0.2)
+	if p == nil {
+		return
+	}
+	p.size *= 0.6
+	ps.add(p)
+}
+
+// spawnAround spawns count particles at random positions within
+// the entity's bounds.
+func (ps *particleSystem) spawnAround(e Entity, id, count int) {
+	p, ok := e.(PositionComponent)
+	if !ok {
+		return
+	}
+	x, y, z := p.Position()
+	w, h := 0.5, 1.0
+	if s, ok := e.(SizeComponent); ok {
+		b := s.Bounds()
+		w, h = float64(b.Max.X()-b.Min.X()), float64(b.Max.Y()-b.Min.Y())
+	}
+	for i := 0; i < count; i++ {
+		ps.spawn(id,
+			x+(rand.Float64()*2-1)*w,
+			y+0.5+rand.Float64()*h,
+			z+(rand.Float64()*2-1)*w,
+			rand.NormFloat64()*0.02,
+			rand.NormFloat64()*0.02,
+			rand.NormFloat64()*0.02,
+			nil,
+		)
+	}
+}
+
+// spawnCrit spawns the burst of particles shown when an entity
+// receives a critical hit.
+func (ps *particleSystem) spawnCrit(e Entity, magic bool) {
+	p, ok := e.(PositionComponent)
+	if !ok {
+		return
+	}
+	id := 9
+	if magic {
+		id = 10
+	}
+	x, y, z := p.Position()
+	w, h := 0.5, 1.0
+	if s, ok := e.(SizeComponent); ok {
+		b := s.Bounds()
+		w, h = float64(b.Max.X()-b.Min.X()), float64(b.Max.Y()-b.Min.Y())
+	}
+	for i := 0; i < 48; i++ {
+		dx, dy, dz := rand.Float64()*2-1, rand.Float64()*2-1, rand.Float64()*2-1
+		if dx*dx+dy*dy+dz*dz > 1 {
+			continue
+		}
+		ps.spawn(id,
+			x+dx*w/4,
+			y+h/2+dy*h/4,
+			z+dz*w/4,
+			dx, dy+0.2, dz,
+			nil,
+		)
+	}
+}
+
+// spawn creates a particle with the vanilla particle id at the
+// location. The velocity and data are interpreted depending
+// on the type of particle.
+func (ps *particleSystem) spawn(id int, x, y, z, vx, vy, vz float64, data []int) {
+	p := &particle{
+		x: x, y: y, z: z,
+		r: 1, g: 1, b: 1,
+		size: rand.Float64()*0.5 + 0.5,
+		life: 8 / (rand.Float64()*0.8 + 0.2),
+	}
+	p.vx, p.vy, p.vz = randomVelocity(vx, vy, vz)
+	p.vx, p.vy, p.vz = p.vx+vx, p.vy+vy, p.vz+vz
+
+	switch id {
+	case 0: // explode
+		p.vx, p.vy, p.vz = vx+randRange(0.05), vy+randRange(0.05), vz+randRange(0.05)
+		grey := rand.Float64()*0.3 + 0.7
+		p.r, p.g, p.b = grey, grey, grey
+		p.size = rand.Float64()*rand.Float64()*6 + 1
+		p.life = 16/(rand.Float64()*0.8+0.2) + 2
+		p.rise, p.drag = 0.004, 0.9
+		p.animStart, p.animFrames = 7, 8
+	case 1: // largeexplode
+		Client.explosions = append(Client.explosions, newExplosionEffect(x, y, z, 2*(1-vx*0.5)))
+		return
+	case 2: // hugeexplosion
+		Client.spawnExplosion(x, y, z, 4)
+		return
+	case 3: // fireworksSpark
+		p.vx, p.vy, p.vz = vx, vy, vz
+		p.size *= 0.75
+		p.life = 48 + float64(rand.Intn(12))
+		p.gravity, p.drag = 0.1, 0.91
+		p.animStart, p.animFrames = 167, 8
+		p.emissive = true
+	case 4: // bubble
+		p.tex = particleTexture(32)
+		p.vx, p.vy, p.vz = vx*0.2+randRange(0.02), vy*0.2+randRange(0.02), vz*0.2+randRange(0.02)
+		p.size *= rand.Float64()*0.6 + 0.2
+		p.gravity, p.drag = -0.05, 0.85
+		p.collide = true
+	case 5, 39: // splash, droplet
+		p.tex = particleTexture(19 + rand.Intn(4))
+		p.vx, p.vy, p.vz = vx*0.3+randRange(0.05), rand.Float64()*0.2+0.1, vz*0.3+randRange(0.05)
+		if vy == 0 && (vx != 0 || vz != 0) {
+			p.vx, p.vy, p.vz = vx, vy+0.1, vz
+		}
+		p.gravity = 1.5
+		p.collide, p.dieOnGround = true, true
+	case 6: // wake
+		p.tex = particleTexture(19)
+		p.vx, p.vy, p.vz = vx, vy, vz
+		p.size *= 0.3
+		p.drag = 0.98
+		p.collide = true
+	case 7, 8: // suspended, depthsuspend
+		p.tex = particleTexture(0)
+		p.vx, p.vy, p.vz = vx*0.2, vy*0.2, vz*0.2
+		p.size *= rand.Float64()*0.6 + 0.2
+		p.r, p.g, p.b = 0.4, 0.4, 0.7
+		if id == 8 {
+			grey := rand.Float64() * 0.3
+			p.r, p.g, p.b = grey, grey, grey+0.2
+		}
+		p.life = 16 / (rand.Float64()*0.8 + 0.2)
+		p.drag = 1
+	case 9, 10: // crit, magicCrit
+		p.tex = particleTexture(65)
+		p.vx, p.vy, p.vz = p.vx*0.1+vx, p.vy*0.1+vy, p.vz*0.1+vz
+		grey := rand.Float64()*0.3 + 0.6
+		p.r, p.g, p.b = grey, grey, grey
+		if id == 10 {
+			p.r, p.g = grey*0.3, grey*0.8
+		}
+		p.size *= 0.75
+		p.life = 6 / (rand.Float64()*0.8 + 0.6)
+		p.gravity, p.drag = 0.5, 0.7
+		p.collide = true
+	case 11, 12: // smoke, largesmoke
+		p.vx, p.vy, p.vz = p.vx*0.1+vx, p.vy*0.1+vy, p.vz*0.1+vz
+		grey := rand.Float64() * 0.3
+		p.r, p.g, p.b = grey, grey, grey
+		p.size *= 0.75
+		if id == 12 {
+			p.size *= 2.5
+		}
+		p.rise, p.drag = 0.004, 0.96
+		p.animStart, p.animFrames = 7, 8
+		p.collide = true
+	case 13, 14, 15, 16, 17: // spell, instantSpell, mobSpell, mobSpellAmbient, witchMagic
+		p.vx, p.vy, p.vz = p.vx*0.1, p.vy*0.1+0.2*rand.Float64(), p.vz*0.1
+		p.animStart, p.animFrames = 135, 8
+		if id == 14 {
+			p.animStart = 151
+		}
+		switch id {
+		case 15, 16:
+			// The velocity is the colour of the effect
+			p.r, p.g, p.b = vx, vy, vz
+			p.vx, p.vy, p.vz = 0, 0.02, 0
+			if id == 16 {
+				p.a = 0.15
+			}
+		case 17:
+			f := rand.Float64()*0.5 + 0.35
+			p.r, p.g, p.b = f, 0, f
+		}
+		p.rise, p.drag = 0.004, 0.96
+		p.collide = true
+	case 18, 19: // dripWater, dripLava
+		p.tex = particleTexture(113)
+		p.vx, p.vy, p.vz = 0, 0, 0
+		p.r, p.g, p.b = 0, 0, 1
+		if id == 19 {
+			p.r, p.g, p.b = 1, 0.3, 0
+			p.emissive = true
+		}
+		p.life = 64 / (rand.Float64()*0.8 + 0.2)
+		p.gravity = 1.5
+		p.collide, p.dieOnGround = true, true
+	case 20, 34: // angryVillager, heart
+		p.tex = particleTexture(80)
+		if id == 20 {
+			p.tex = particleTexture(81)
+			p.y += 0.5
+		}
+		p.vx, p.vy, p.vz = p.vx*0.01, p.vy*0.01+0.1, p.vz*0.01
+		p.size *= 0.75 * 2
+		p.life = 16
+		p.drag = 0.86
+		p.collide = true
+	case 21, 22: // happyVillager, townaura
+		p.tex = particleTexture(0)
+		if id == 21 {
+			p.tex = particleTexture(82)
+		}
+		p.vx, p.vy, p.vz = vx, vy, vz
+		p.size *= rand.Float64()*0.6 + 0.5
+		p.life = 20 / (rand.Float64()*0.8 + 0.2)
+		p.drag = 0.99
+		p.collide = true
+	case 23: // note
+		p.tex = particleTexture(64)
+		p.r = math.Sin((vx+0)*math.Pi*2)*0.65 + 0.35
+		p.g = math.Sin((vx+1.0/3.0)*math.Pi*2)*0.65 + 0.35
+		p.b = math.Sin((vx+2.0/3.0)*math.Pi*2)*0.65 + 0.35
+		p.vx, p.vy, p.vz = p.vx*0.01, p.vy*0.01+0.2, p.vz*0.01
+		p.size *= 0.75 * 2
+		p.life = 6
+		p.drag = 0.66
+	case 24, 25: // portal, enchantmenttable
+		p.tex = particleTexture(rand.Intn(8))
+		f := rand.Float64()*0.6 + 0.4
+		p.r, p.g, p.b = f*0.9, f*0.3, f
+		p.life = rand.Float64()*10 + 40
+		if id == 25 {
+			p.tex = particleTexture(225 + rand.Intn(26))
+			p.r, p.g, p.b = f*0.9, f*0.9, f
+			p.life = rand.Float64()*10 + 30
+		}
+		// These move from the offset towards the origin
+		p.x, p.y, p.z = x+vx, y+vy, z+vz
+		p.vx, p.vy, p.vz = -vx/p.life, -vy/p.life, -vz/p.life
+		p.size *= rand.Float64()*0.2 + 0.5
+		p.drag = 1
+		p.emissive = true
+	case 26: // flame
+		p.tex = particleTexture(48)
+		p.vx, p.vy, p.vz = p.vx*0.01+vx, p.vy*0.01+vy, p.vz*0.01+vz
+		p.life = 8/(rand.Float64()*0.8+0.2) + 4
+		p.drag = 0.96
+		p.shrink, p.emissive = true, true
+		p.collide = true
+	case 27: // lava
+		p.tex = particleTexture(49)
+		p.vx, p.vy, p.vz = p.vx*0.8, rand.Float64()*0.4+0.05, p.vz*0.8
+		p.size *= rand.Float64()*2 + 0.2
+		p.life = 16 / (rand.Float64()*0.8 + 0.2)
+		p.gravity, p.drag = 0.75, 0.999
+		p.shrink, p.emissive = true, true
+		p.collide = true
+	case 28: // footstep
+		// Not sent by vanilla servers
+		return
+	case 29, 32: // cloud, snowshovel
+		p.vx, p.vy, p.vz = p.vx*0.1+vx, p.vy*0.1+vy, p.vz*0.1+vz
+		grey := 1 - rand.Float64()*0.3
+		p.r, p.g, p.b = grey, grey, grey
+		p.size *= 0.75
+		if id == 29 {
+			p.size *= 2.5
+			p.rise = 0.004
+		} else {
+			p.gravity = 0.075
+		}
+		p.drag = 0.96
+		p.animStart, p.animFrames = 7, 8
+		p.collide = true
+	case 30: // reddust
+		p.vx, p.vy, p.vz = p.vx*0.1, p.vy*0.1, p.vz*0.1
+		if vx == 0 {
+			vx = 1
+		}
+		f := rand.Float64()*0.4 + 0.6
+		p.r = (rand.Float64()*0.2 + 0.8) * vx * f
+		p.g = (rand.Float64()*0.2 + 0.8) * vy * f
+		p.b = (rand.Float64()*0.2 + 0.8) * vz * f
+		p.size *= 0.75
+		p.rise, p.drag = 0.004, 0.96
+		p.animStart, p.animFrames = 7, 8
+		p.collide = true
+	case 31, 33, 35: // snowballpoof, slime, barrier
+		name := "items/snowball"
+		switch id {
+		case 33:
+			name = "items/slimeball"
+		case 35:
+			name = "items/barrier"
+		}
+		tex := render.GetTexture(name)
+		if id == 35 {
+			p.tex = tex
+			p.vx, p.vy, p.vz = 0, 0, 0
+			p.size = 5
+			p.life = 80
+			p.drag = 1
+			break
+		}
+		p.tex = fragmentTexture(tex)
+		p.size /= 2
+		p.gravity = 1
+		p.collide = true
+	case 36: // iconcrack
+		if len(data) < 2 || !validItemID(data[0]) {
+			return
+		}
+		it := ItemById(data[0])
+		it.ParseDamage(int16(data[1]))
+		tex := itemParticleTexture(it)
+		if tex == nil {
+			return
+		}
+		p.tex = fragmentTexture(tex)
+		p.vx, p.vy, p.vz = p.vx*0.1+vx, p.vy*0.1+vy, p.vz*0.1+vz
+		p.size /= 2
+		p.gravity = 1
+		p.collide = true
+	case 37, 38: // blockcrack, blockdust
+		if len(data) < 1 {
+			return
+		}
+		b := blockFromStateID(data[0])
+		var bp *particle
+		if id == 38 {
+			bp = newBlockParticle(b, x, y, z, vx, vy, vz)
+		} else {
+			bp = newBlockParticle(b, x, y, z, p.vx, p.vy, p.vz)
+		}
+		if bp == nil {
+			return
+		}
+		p = bp
+	default: // take, mobappearance
+		return
+	}
+	ps.add(p)
+}
+
+// handleParticle spawns the particles for a particle packet
+// using the same spread as vanilla.
+func (ps *particleSystem) handleParticle(p *protocol.Particle) {
+	data := make([]int, len(p.Data))
+	for i, d := range p.Data {
+		data[i] = int(d)
+	}
+	x, y, z := float64(p.X), float64(p.Y), float64(p.Z)
+	speed := float64(p.Speed)
+	if p.Count == 0 {
+		ps.spawn(int(p.ParticleID), x, y, z,
+			float64(p.OffsetX)*speed,
+			float64(p.OffsetY)*speed,
+			float64(p.OffsetZ)*speed,
+			data,
+		)
+		return
+	}
+	// The count comes from the server, no more than the
+	// particle limit could be shown anyway
+	count := int(p.Count)
+	if count > maxParticles {
+		count = maxParticles
+	}
+	for i := 0; i < count; i++ {
+		ps.spawn(int(p.ParticleID),
+			x+rand.NormFloat64()*float64(p.OffsetX),
+			y+rand.NormFloat64()*float64(p.OffsetY),
+			z+rand.NormFloat64()*float64(p.OffsetZ),
+			rand.NormFloat64()*speed,
+			rand.NormFloat64()*speed,
+			rand.NormFloat64()*speed,
+			data,
+		)
+	}
+}
@@ -16,6 +16,7 @@ package steven
 
 import (
 	"sort"
+	"strconv"
 
 	"github.com/thinkofdeath/steven/format"
 	"github.com/thinkofdeath/steven/protocol"
@@ -24,7 +25,11 @@ import (
 	"github.com/thinkofdeath/steven/ui/scene"
 )
 
-const playerListWidth = 150
+const (
+	playerListWidth = 150
+	// Maximum number of players in a single column
+	playerListRows = 20
+)
 
 type playerInfo struct {
 	name        string
@@ -44,10 +49,14 @@ type playerListUI struct {
 	background [4]*ui.Image
 	entries    []*playerListUIEntry
 	scene      *scene.Type
+
+	header, footer                     *ui.Formatted
+	headerBackground, footerBackground *ui.Image
 }
 
 type playerListUIEntry struct {
-	text    *ui.Text
+	text    *ui.Formatted
+	score   *ui.Text
 	icon    *ui.Image
 	iconHat *ui.Image
 	ping    *ui.Image
@@ -55,6 +64,7 @@ type playerListUIEntry struct {
 
 func (p playerListUIEntry) set(enabled bool) {
 	p.text.SetDraw(enabled)
+	p.score.SetDraw(enabled)
 	p.icon.SetDraw(enabled)
 	p.iconHat.SetDraw(enabled)
 	p.ping.SetDraw(enabled)
@@ -69,6 +79,31 @@ func (p *playerListUI) init() {
 		p.background[i].SetDraw(false)
 		p.scene.AddDrawable(p.background[i].Attach(ui.Top, ui.Center))
 	}
+	p.headerBackground = ui.NewImage(render.GetTexture("solid"), 0, 16, 0, 0, 0, 0, 1, 1, 0, 0, 0)
+	p.headerBackground.SetA(120)
+	p.scene.AddDrawable(p.headerBackground.Attach(ui.Top, ui.Center))
+	p.footerBackground = ui.NewImage(render.GetTexture("solid"), 0, 16, 0, 0, 0, 0, 1, 1, 0, 0, 0)
+	p.footerBackground.SetA(120)
+	p.scene.AddDrawable(p.footerBackground.Attach(ui.Top, ui.Center))
+	p.header = ui.NewFormatted(emptyComponent(), 0, 16)
+	p.scene.AddDrawable(p.header.Attach(ui.Top, ui.Center))
+	p.footer = ui.NewFormatted(emptyComponent(), 0, 16)
+	p.scene.AddDrawable(p.footer.Attach(ui.Top, ui.Center))
+}
+
+// setHeaderFooter changes the text displayed above and below
+// the player list.
+func (p *playerListUI) setHeaderFooter(header, footer format.AnyComponent) {
+	if header.Value == nil {
+		header = emptyComponent()
+	}
+	if footer.Value == nil {
+		footer = emptyComponent()
+	}
+	format.ConvertLegacy(header)
+	format.ConvertLegacy(footer)
+	p.header.Update(header)
+	p.footer.Update(footer)
 }
 
 func (p *playerListUI) free() {
@@ -100,12 +135,39 @@ func (p *playerListUI) render(delta float64) {
 	for _, e := range p.entries {
 		e.set(false)
 	}
+
+	// The header pushes the columns down and the footer limits
+	// how tall they can grow before they are split
+	top := 16.0
+	hasHeader := p.header.Width > 0
+	p.header.SetDraw(hasHeader)
+	p.headerBackground.SetDraw(hasHeader)
+	if hasHeader {
+		top += p.header.Height + 4
+	}
+	hasFooter := p.footer.Width > 0
+	p.footer.SetDraw(hasFooter)
+	p.footerBackground.SetDraw(hasFooter)
+	footerHeight := 0.0
+	if hasFooter {
+		footerHeight = p.footer.Height + 4
+	}
+	rows := int((480 - top - footerHeight - 16) / 18)
+	if rows > playerListRows {
+		rows = playerListRows
+	} else if rows < 1 {
+		rows = 1
+	}
+	for _, b := range p.background {
+		b.SetY(top)
+	}
+
 	offset := 0
 	count := 0
 	bTab := 0
 	lastEntry := 0
 	for _, pl := range p.players() {
-		if count >= 20 {
+		if count >= rows {
 			entries := p.entries[lastEntry:offset]
 			lastEntry = offset
 			for _, e := range entries {
@@ -125,9 +187,12 @@ func (p *playerListUI) render(delta float64) {
 		background := p.background[bTab]
 		background.SetDraw(true)
 		if offset >= len(p.entries) {
-			text := ui.NewText("", 24, 0, 255, 255, 255).
+			text := ui.NewFormatted(format.Wrap(&format.TextComponent{}), 24, 0).
 				Attach(ui.Top, ui.Left)
 			p.scene.AddDrawable(text)
+			score := ui.NewText("", 24, 0, 255, 255, 85).
+				Attach(ui.Top, ui.Right)
+			p.scene.AddDrawable(score)
 			icon := ui.NewImage(pl.skin, 0, 0, 16, 16, 8/64.0, 8/64.0, 8/64.0, 8/64.0, 255, 255, 255).
 				Attach(ui.Top, ui.Center)
 			p.scene.AddDrawable(icon)
@@ -139,12 +204,14 @@ func (p *playerListUI) render(delta float64) {
 			p.scene.AddDrawable(ping)
 
 			text.AttachTo(background)
+			score.AttachTo(background)
 			icon.AttachTo(background)
 			iconHat.AttachTo(background)
 			ping.AttachTo(background)
 
 			p.entries = append(p.entries, &playerListUIEntry{
 				text:    text,
+				score:   score,
 				icon:    icon,
 				iconHat: iconHat,
 				ping:    ping,
@@ -153,8 +220,27 @@ func (p *playerListUI) render(delta float64) {
 		e := p.entries[offset]
 		e.set(true)
 		offset++
-		e.text.SetY(1 + 18*float64(count))
-		e.text.Update(pl.name)
+		e.text.SetY(18 * float64(count))
+		if pl.displayName.Value != nil {
+			e.text.Update(pl.displayName)
+		} else {
+			e.text.Update(Client.scoreboard.formatName(pl.name))
+		}
+		e.score.SetY(1 + 18*float64(count))
+		if o, v, ok := Client.scoreboard.score(scoreboardList, pl.name); ok {
+			e.score.Update(strconv.Itoa(v))
+			if o.hearts {
+				e.score.SetR(255)
+				e.score.SetG(85)
+				e.score.SetB(85)
+			} else {
+				e.score.SetR(255)
+				e.score.SetG(255)
+				e.score.SetB(85)
+			}
+		} else {
+			e.score.Update("")
+		}
 		e.icon.SetY(1 + 18*float64(count))
 		e.icon.SetTexture(pl.skin)
 		e.iconHat.SetY(1 + 18*float64(count))
@@ -208,6 +294,37 @@ func (p *playerListUI) render(delta float64) {
 		p.background[1].SetX(-p.background[1].Width() / 2)
 		p.background[2].SetX(p.background[2].Width() / 2)
 	}
+
+	columns := bTab + 1
+	if columns > len(p.background) {
+		columns = len(p.background)
+	}
+	width := float64(columns) * (playerListWidth + 48)
+	height := 0.0
+	for _, b := range p.background[:columns] {
+		if b.ShouldDraw() && b.Height() > height {
+			height = b.Height()
+		}
+	}
+	if hasHeader {
+		w := width
+		if p.header.Width+8 > w {
+			w = p.header.Width + 8
+		}
+		p.headerBackground.SetWidth(w)
+		p.headerBackground.SetHeight(p.header.Height + 4)
+		p.header.SetY(18)
+	}
+	if hasFooter {
+		w := width
+		if p.footer.Width+8 > w {
+			w = p.footer.Width + 8
+		}
+		p.footerBackground.SetY(top + height)
+		p.footerBackground.SetWidth(w)
+		p.footerBackground.SetHeight(p.footer.Height + 4)
+		p.footer.SetY(top + height + 2)
+	}
 }
 
 func (p *playerListUI) players() (out []*playerInfo) {
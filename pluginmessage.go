@@ -88,3 +88,15 @@ func init() {
 	registerPluginMessage((*pmMinecraftBrand)(nil), true)
 	registerPluginMessage((*pmMinecraftBrand)(nil), false)
 }
+
+// This is a packet
+type pmBookOpen struct {
+}
+
+func (*pmBookOpen) channel() string {
+	return "MC|BOpen"
+}
+
+func init() {
+	registerPluginMessage((*pmBookOpen)(nil), false)
+}
@@ -21,3 +21,10 @@ func (p *pmMinecraftBrand) read(rr io.Reader) (err error) {
 	}
 	return
 }
+
+func (p *pmBookOpen) write(ww io.Writer) (err error) {
+	return
+}
+func (p *pmBookOpen) read(rr io.Reader) (err error) {
+	return
+}
@@ -0,0 +1,76 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+// effectColors contains the particle/liquid colour of each
+// status effect indexed by its id.
+var effectColors = [...]uint32{
+	1:  0x7CAFC6, // Speed
+	2:  0x5A6C81, // Slowness
+	3:  0xD9C043, // Haste
+	4:  0x4A4217, // Mining fatigue
+	5:  0x932423, // Strength
+	6:  0xF82423, // Instant health
+	7:  0x430A09, // Instant damage
+	8:  0x22FF4C, // Jump boost
+	9:  0x551D4A, // Nausea
+	10: 0xCD5CAB, // Regeneration
+	11: 0x99453A, // Resistance
+	12: 0xE49A3A, // Fire resistance
+	13: 0x2E5299, // Water breathing
+	14: 0x7F8392, // Invisibility
+	15: 0x1F1F23, // Blindness
+	16: 0x1F1FA1, // Night vision
+	17: 0x587653, // Hunger
+	18: 0x484D48, // Weakness
+	19: 0x4E9331, // Poison
+	20: 0x352A27, // Wither
+	21: 0xF87D23, // Health boost
+	22: 0x2552A5, // Absorption
+	23: 0xF82423, // Saturation
+}
+
+// The lower 4 bits of a potion's damage value select
+// the effect it applies.
+var potionDamageEffects = [16]int{
+	1:  10, // Regeneration
+	2:  1,  // Speed
+	3:  12, // Fire resistance
+	4:  19, // Poison
+	5:  6,  // Instant health
+	6:  16, // Night vision
+	8:  18, // Weakness
+	9:  5,  // Strength
+	10: 2,  // Slowness
+	11: 8,  // Jump boost
+	12: 7,  // Instant damage
+	13: 13, // Water breathing
+	14: 14, // Invisibility
+}
+
+// effectColor returns the colour of the effect with the passed id.
+func effectColor(id int) (r, g, b byte) {
+	col := uint32(0x385DC6) // Water
+	if id > 0 && id < len(effectColors) {
+		col = effectColors[id]
+	}
+	return byte(col >> 16), byte(col >> 8), byte(col)
+}
+
+// potionColor returns the colour of the potion with the passed
+// damage value.
+func potionColor(damage int) (r, g, b byte) {
+	return effectColor(potionDamageEffects[damage&0xF])
+}
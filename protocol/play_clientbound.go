@@ -781,10 +781,10 @@ type Camera struct {
 // Currently the packet id is: 0x44
 type WorldBorder struct {
 	Action         VarInt
+	X, Z           float64 `if:".Action == 3 .Action == 2"`
 	OldRadius      float64 `if:".Action == 3 .Action == 1"`
 	NewRadius      float64 `if:".Action == 3 .Action == 1 .Action == 0"`
 	Speed          VarLong `if:".Action == 3 .Action == 1"`
-	X, Z           float64 `if:".Action == 3 .Action == 2"`
 	PortalBoundary VarInt  `if:".Action == 3"`
 	WarningTime    VarInt  `if:".Action == 3 .Action == 4"`
 	WarningBlocks  VarInt  `if:".Action == 3 .Action == 5"`
@@ -3496,8 +3496,8 @@ func (w *WorldBorder) write(ww io.Writer) (err error) {
 	if err = WriteVarInt(ww, w.Action); err != nil {
 		return
 	}
-	if w.Action == 3 || w.Action == 1 {
-		tmp0 := math.Float64bits(w.OldRadius)
+	if w.Action == 3 || w.Action == 2 {
+		tmp0 := math.Float64bits(w.X)
 		tmp[0] = byte(tmp0 >> 56)
 		tmp[1] = byte(tmp0 >> 48)
 		tmp[2] = byte(tmp0 >> 40)
@@ -3509,9 +3509,7 @@ func (w *WorldBorder) write(ww io.Writer) (err error) {
 		if _, err = ww.Write(tmp[:8]); err != nil {
 			return
 		}
-	}
-	if w.Action == 3 || w.Action == 1 || w.Action == 0 {
-		tmp1 := math.Float64bits(w.NewRadius)
+		tmp1 := math.Float64bits(w.Z)
 		tmp[0] = byte(tmp1 >> 56)
 		tmp[1] = byte(tmp1 >> 48)
 		tmp[2] = byte(tmp1 >> 40)
@@ -3525,12 +3523,7 @@ func (w *WorldBorder) write(ww io.Writer) (err error) {
 		}
 	}
 	if w.Action == 3 || w.Action == 1 {
-		if err = WriteVarLong(ww, w.Speed); err != nil {
-			return
-		}
-	}
-	if w.Action == 3 || w.Action == 2 {
-		tmp2 := math.Float64bits(w.X)
+		tmp2 := math.Float64bits(w.OldRadius)
 		tmp[0] = byte(tmp2 >> 56)
 		tmp[1] = byte(tmp2 >> 48)
 		tmp[2] = byte(tmp2 >> 40)
@@ -3542,7 +3535,9 @@ func (w *WorldBorder) write(ww io.Writer) (err error) {
 		if _, err = ww.Write(tmp[:8]); err != nil {
 			return
 		}
-		tmp3 := math.Float64bits(w.Z)
+	}
+	if w.Action == 3 || w.Action == 1 || w.Action == 0 {
+		tmp3 := math.Float64bits(w.NewRadius)
 		tmp[0] = byte(tmp3 >> 56)
 		tmp[1] = byte(tmp3 >> 48)
 		tmp[2] = byte(tmp3 >> 40)
@@ -3555,6 +3550,11 @@ func (w *WorldBorder) write(ww io.Writer) (err error) {
 			return
 		}
 	}
+	if w.Action == 3 || w.Action == 1 {
+		if err = WriteVarLong(ww, w.Speed); err != nil {
+			return
+		}
+	}
 	if w.Action == 3 {
 		if err = WriteVarInt(ww, w.PortalBoundary); err != nil {
 			return
@@ -3577,40 +3577,40 @@ func (w *WorldBorder) read(rr io.Reader) (err error) {
 	if w.Action, err = ReadVarInt(rr); err != nil {
 		return
 	}
-	if w.Action == 3 || w.Action == 1 {
+	if w.Action == 3 || w.Action == 2 {
 		var tmp0 uint64
 		if _, err = rr.Read(tmp[:8]); err != nil {
 			return
 		}
 		tmp0 = (uint64(tmp[7]) << 0) | (uint64(tmp[6]) << 8) | (uint64(tmp[5]) << 16) | (uint64(tmp[4]) << 24) | (uint64(tmp[3]) << 32) | (uint64(tmp[2]) << 40) | (uint64(tmp[1]) << 48) | (uint64(tmp[0]) << 56)
-		w.OldRadius = math.Float64frombits(tmp0)
-	}
-	if w.Action == 3 || w.Action == 1 || w.Action == 0 {
+		w.X = math.Float64frombits(tmp0)
 		var tmp1 uint64
 		if _, err = rr.Read(tmp[:8]); err != nil {
 			return
 		}
 		tmp1 = (uint64(tmp[7]) << 0) | (uint64(tmp[6]) << 8) | (uint64(tmp[5]) << 16) | (uint64(tmp[4]) << 24) | (uint64(tmp[3]) << 32) | (uint64(tmp[2]) << 40) | (uint64(tmp[1]) << 48) | (uint64(tmp[0]) << 56)
-		w.NewRadius = math.Float64frombits(tmp1)
+		w.Z = math.Float64frombits(tmp1)
 	}
 	if w.Action == 3 || w.Action == 1 {
-		if w.Speed, err = ReadVarLong(rr); err != nil {
-			return
-		}
-	}
-	if w.Action == 3 || w.Action == 2 {
 		var tmp2 uint64
 		if _, err = rr.Read(tmp[:8]); err != nil {
 			return
 		}
 		tmp2 = (uint64(tmp[7]) << 0) | (uint64(tmp[6]) << 8) | (uint64(tmp[5]) << 16) | (uint64(tmp[4]) << 24) | (uint64(tmp[3]) << 32) | (uint64(tmp[2]) << 40) | (uint64(tmp[1]) << 48) | (uint64(tmp[0]) << 56)
-		w.X = math.Float64frombits(tmp2)
+		w.OldRadius = math.Float64frombits(tmp2)
+	}
+	if w.Action == 3 || w.Action == 1 || w.Action == 0 {
 		var tmp3 uint64
 		if _, err = rr.Read(tmp[:8]); err != nil {
 			return
 		}
 		tmp3 = (uint64(tmp[7]) << 0) | (uint64(tmp[6]) << 8) | (uint64(tmp[5]) << 16) | (uint64(tmp[4]) << 24) | (uint64(tmp[3]) << 32) | (uint64(tmp[2]) << 40) | (uint64(tmp[1]) << 48) | (uint64(tmp[0]) << 56)
-		w.Z = math.Float64frombits(tmp3)
+		w.NewRadius = math.Float64frombits(tmp3)
+	}
+	if w.Action == 3 || w.Action == 1 {
+		if w.Speed, err = ReadVarLong(rr); err != nil {
+			return
+		}
 	}
 	if w.Action == 3 {
 		if w.PortalBoundary, err = ReadVarInt(rr); err != nil {
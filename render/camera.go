@@ -27,4 +27,6 @@ func init() {
 type ClientCamera struct {
 	X, Y, Z    float64
 	Yaw, Pitch float64
+	// Roll tilts the camera around the direction it is facing
+	Roll float64
 }
@@ -31,6 +31,8 @@ type chunkShader struct {
 	Texture           gl.Uniform   `gl:"textures"`
 	LightLevel        gl.Uniform   `gl:"lightLevel"`
 	SkyOffset         gl.Uniform   `gl:"skyOffset"`
+	FogDistance       gl.Uniform   `gl:"fogDistance"`
+	FogColor          gl.Uniform   `gl:"fogColor"`
 }
 
 func init() {
@@ -52,13 +54,16 @@ out vec4 vTextureInfo;
 out vec2 vTextureOffset;
 out float vAtlas;
 out vec3 vLighting;
+out float vFogDist;
 
 #include get_light
 
 void main() {
 	vec3 pos = vec3(aPosition.x, -aPosition.y, aPosition.z);
 	vec3 o = vec3(offset.x, -offset.y, offset.z);
-	gl_Position = perspectiveMatrix * cameraMatrix * vec4(pos + o * 16.0, 1.0);
+	vec4 viewPos = cameraMatrix * vec4(pos + o * 16.0, 1.0);
+	gl_Position = perspectiveMatrix * viewPos;
+	vFogDist = length(viewPos.xyz);
 
 	vColor = aColor;
 	vTextureInfo = aTextureInfo;
@@ -70,12 +75,15 @@ void main() {
 `)
 	glsl.Register("chunk_frag", `
 uniform sampler2DArray textures;
+uniform float fogDistance;
+uniform vec3 fogColor;
 
 in vec3 vColor;
 in vec4 vTextureInfo;
 in vec2 vTextureOffset;
 in float vAtlas;
 in vec3 vLighting;
+in float vFogDist;
 
 #ifndef alpha
 out vec4 fragColor;
@@ -85,6 +93,7 @@ out float revealage;
 #endif
 
 #include lookup_texture
+#include apply_fog
 
 void main() {
 	vec4 col = atlasTexture();
@@ -93,6 +102,7 @@ void main() {
 	#endif
 	col *= vec4(vColor, 1.0);
 	col.rgb *= vLighting;
+	col.rgb = applyFog(col.rgb);
 	
 	#ifndef alpha
 	fragColor = col;
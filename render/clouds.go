@@ -140,7 +140,7 @@ func (c *cloudState) init() {
 }
 
 func (c *cloudState) tick(delta float64) {
-	if !DrawClouds {
+	if !DrawClouds || FogDistance > 0 {
 		return
 	}
 	c.offset += delta
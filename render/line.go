@@ -14,7 +14,10 @@
 
 package render
 
-import "github.com/thinkofdeath/steven/render/gl"
+import (
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/thinkofdeath/steven/render/gl"
+)
 
 var lineState = struct {
 	program  gl.Program
@@ -81,6 +84,41 @@ func DrawBox(x1, y1, z1, x2, y2, z2 float64, r, g, b, a byte) {
 	}
 }
 
+// DrawLine draws a line between the two points with the passed
+// thickness. The line is made of two crossed quads so that it
+// can be seen from any angle.
+func DrawLine(x1, y1, z1, x2, y2, z2, size float64, r, g, b, a byte) {
+	dir := mgl32.Vec3{float32(x2 - x1), float32(y2 - y1), float32(z2 - z1)}
+	if dir.Len() == 0 {
+		return
+	}
+	side := dir.Cross(mgl32.Vec3{0, 1, 0})
+	if side.Len() < 0.0001 {
+		side = mgl32.Vec3{1, 0, 0}
+	}
+	side = side.Normalize().Mul(float32(size / 2))
+	up := dir.Cross(side).Normalize().Mul(float32(size / 2))
+
+	start := mgl32.Vec3{float32(x1), float32(y1), float32(z1)}
+	end := mgl32.Vec3{float32(x2), float32(y2), float32(z2)}
+	for _, off := range [2]mgl32.Vec3{side, up} {
+		quad := [4]mgl32.Vec3{
+			start.Sub(off), start.Add(off),
+			end.Add(off), end.Sub(off),
+		}
+		// Both windings are added so that the quad isn't
+		// culled from either side
+		for _, i := range [...]int{0, 1, 2, 2, 3, 0, 0, 3, 2, 2, 1, 0} {
+			v := quad[i]
+			lineState.data = appendFloat(lineState.data, v.X())
+			lineState.data = appendFloat(lineState.data, v.Y())
+			lineState.data = appendFloat(lineState.data, v.Z())
+			lineState.data = append(lineState.data, r, g, b, a)
+			lineState.count++
+		}
+	}
+}
+
 // Precomputed face vertices
 var faceVertices = [6][6][3]float64{
 	{ // Up
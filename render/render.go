@@ -38,7 +38,10 @@ var (
 r_fov controls the field of view of the camera. Measured
 in degrees.
 `)
-	lastFOV               int = 90
+	// FOVScale scales the field of view, used to widen or
+	// narrow the view when the player's speed changes.
+	FOVScale                  = 1.0
+	lastFOV                   = 90.0
 	lastWidth, lastHeight int = -1, -1
 	perspectiveMatrix         = mgl32.Mat4{}
 	cameraMatrix              = mgl32.Mat4{}
@@ -59,6 +62,13 @@ debugging.
 	ClearColour                   = struct{ R, G, B float32 }{
 		122.0 / 255.0, 165.0 / 255.0, 247.0 / 255.0,
 	}
+	// FogDistance is the distance at which the world fades fully
+	// into the fog colour. Zero disables the fog.
+	FogDistance float32
+	FogColour   = struct{ R, G, B float32 }{}
+	// Warp is the strength (0-1) of the screen warping effect.
+	Warp     float64
+	warpTime float64
 )
 
 // Start starts the renderer
@@ -118,10 +128,12 @@ sync:
 	}
 
 	// Only update the viewport if the window was resized
-	if lastHeight != height || lastWidth != width || lastFOV != FOV.Value() {
+	resized := lastHeight != height || lastWidth != width
+	fov := float64(FOV.Value()) * FOVScale
+	if resized || lastFOV != fov {
 		lastWidth = width
 		lastHeight = height
-		lastFOV = FOV.Value()
+		lastFOV = fov
 
 		perspectiveMatrix = mgl32.Perspective(
 			(math.Pi/180)*float32(lastFOV),
@@ -129,13 +141,15 @@ sync:
 			0.1,
 			500.0,
 		)
-		gl.Viewport(0, 0, width, height)
 		frustum.SetPerspective(
 			(math.Pi/180)*float32(lastFOV),
 			float32(width)/float32(height),
 			0.1,
 			500.0,
 		)
+	}
+	if resized {
+		gl.Viewport(0, 0, width, height)
 		initTrans()
 	}
 
@@ -145,7 +159,11 @@ sync:
 	gl.ActiveTexture(0)
 	glTexture.Bind(gl.Texture2DArray)
 
-	gl.ClearColor(ClearColour.R, ClearColour.G, ClearColour.B, 1.0)
+	if FogDistance > 0 {
+		gl.ClearColor(FogColour.R, FogColour.G, FogColour.B, 1.0)
+	} else {
+		gl.ClearColor(ClearColour.R, ClearColour.G, ClearColour.B, 1.0)
+	}
 	gl.Clear(gl.ColorBufferBit | gl.DepthBufferBit)
 
 	chunkProgram.Use()
@@ -156,12 +174,26 @@ sync:
 		float32(-math.Sin(Camera.Yaw-math.Pi/2) * -math.Cos(Camera.Pitch)),
 	}
 	cam := mgl32.Vec3{-float32(Camera.X), -float32(Camera.Y), float32(Camera.Z)}
-	cameraMatrix = mgl32.LookAtV(
+	cameraMatrix = mgl32.HomogRotate3DZ(float32(Camera.Roll)).Mul4(mgl32.LookAtV(
 		cam,
 		cam.Add(mgl32.Vec3{-viewVector.X(), -viewVector.Y(), viewVector.Z()}),
 		mgl32.Vec3{0, -1, 0},
-	)
+	))
 	cameraMatrix = cameraMatrix.Mul4(mgl32.Scale3D(-1.0, 1.0, 1.0))
+	if Warp > 0 {
+		// Squashes the view along an axis that slowly rotates
+		// around the camera
+		warpTime += delta / 3
+		w := float32(Warp)
+		f := 5/(w*w+5) - w*0.04
+		f *= f
+		axis := mgl32.Vec3{0, 1, 1}.Normalize()
+		angle := float32(math.Mod(warpTime*7, 360)) * (math.Pi / 180)
+		cameraMatrix = mgl32.HomogRotate3D(-angle, axis).
+			Mul4(mgl32.Scale3D(1/f, 1, 1)).
+			Mul4(mgl32.HomogRotate3D(angle, axis)).
+			Mul4(cameraMatrix)
+	}
 
 	frustum.SetCamera(
 		cam,
@@ -174,6 +206,8 @@ sync:
 	shaderChunk.Texture.Int(0)
 	shaderChunk.LightLevel.Float(LightLevel)
 	shaderChunk.SkyOffset.Float(SkyOffset)
+	shaderChunk.FogDistance.Float(FogDistance)
+	shaderChunk.FogColor.Float3(FogColour.R, FogColour.G, FogColour.B)
 
 	chunkPos := position{
 		X: int(Camera.X) >> 4,
@@ -190,7 +224,7 @@ sync:
 	renderBuffer(nearestBuffer, chunkPos, direction.Invalid)
 
 	drawLines()
-	drawStatic()
+	drawStatic(delta)
 	clouds.tick(delta)
 
 	chunkProgramT.Use()
@@ -199,6 +233,8 @@ sync:
 	shaderChunkT.Texture.Int(0)
 	shaderChunkT.LightLevel.Float(LightLevel)
 	shaderChunkT.SkyOffset.Float(SkyOffset)
+	shaderChunkT.FogDistance.Float(FogDistance)
+	shaderChunkT.FogColor.Float3(FogColour.R, FogColour.G, FogColour.B)
 
 	// Copy the depth buffer
 	mainFramebuffer.BindRead()
@@ -55,5 +55,12 @@ vec3 getLight(vec2 light) {
 
 	return clamp(col, 0.0, 1.0);
 }	
+`)
+	glsl.Register("apply_fog", `
+vec3 applyFog(vec3 col) {
+	if (fogDistance <= 0.0) return col;
+	float f = clamp((vFogDist - fogDistance * 0.25) / (fogDistance * 0.75), 0.0, 1.0);
+	return mix(col, fogColor, f);
+}
 `)
 }
@@ -191,3 +191,15 @@ func AddIcon(id string, pix image.Image) {
 	}
 	skins[id] = s
 }
+
+// UpdateIcon replaces the contents of an existing icon. The
+// image must be the same size as the one the icon was created
+// with.
+func UpdateIcon(id string, pix image.Image) {
+	s := skins[id]
+	if s == nil {
+		return
+	}
+	s.data = imgToBytes(pix)
+	uploadTexture(s.info.info, s.data)
+}
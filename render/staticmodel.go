@@ -15,6 +15,8 @@
 package render
 
 import (
+	"math"
+
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/thinkofdeath/steven/render/builder"
 	"github.com/thinkofdeath/steven/render/gl"
@@ -30,6 +32,7 @@ var staticState = struct {
 	indexBuffer    gl.Buffer
 	indexType      gl.Type
 	maxIndex       int
+	glintTime      float64
 }{}
 
 type staticCollection struct {
@@ -64,6 +67,7 @@ type StaticModel struct {
 	// Per a part matrix
 	Matrix               []mgl32.Mat4
 	Colors               [][4]float32
+	Glint                []bool // Draws the enchantment glint over the part
 	BlockLight, SkyLight float32
 	array                gl.VertexArray
 	buffer               gl.Buffer
@@ -107,6 +111,7 @@ func newStaticModel(parts [][]*StaticVertex, c *staticCollection) *StaticModel {
 
 	model.Matrix = make([]mgl32.Mat4, len(parts))
 	model.Colors = make([][4]float32, len(parts))
+	model.Glint = make([]bool, len(parts))
 	model.counts = make([]int32, len(parts))
 	model.offsets = make([]uintptr, len(parts))
 	var all []*StaticVertex
@@ -135,6 +140,11 @@ func (sm *StaticModel) data() {
 	verts := sm.Verts
 	sm.array.Bind()
 	sm.count = (len(verts) / 4) * 6
+	// Single part models may change their vertex
+	// count between refreshes
+	if len(sm.counts) == 1 {
+		sm.counts[0] = int32(sm.count)
+	}
 	if staticState.maxIndex < sm.count {
 		var data []byte
 		data, staticState.indexType = genElementBuffer(sm.count)
@@ -207,7 +217,7 @@ func initStatic() {
 	staticState.indexBuffer = gl.CreateBuffer()
 }
 
-func drawStatic() {
+func drawStatic(delta float64) {
 	if len(staticState.models) == 0 {
 		return
 	}
@@ -219,6 +229,27 @@ func drawStatic() {
 	gl.Enable(gl.Blend)
 
 	offsetBuf := make([]uintptr, 10)
+	draw := func(c *staticCollection, mdl *StaticModel, colors [][4]float32) {
+		mdl.array.Bind()
+		c.shader.Lighting.Float2(mdl.BlockLight, mdl.SkyLight)
+		c.shader.ModelMatrix.Matrix4Multi(mdl.Matrix)
+		c.shader.ColorMul.FloatMutliRaw(colors, len(colors))
+		if len(mdl.counts) > 1 {
+			copy(offsetBuf, mdl.offsets)
+			for i := range mdl.offsets {
+				offsetBuf[i] *= uintptr(m)
+			}
+			gl.MultiDrawElements(gl.Triangles, mdl.counts, staticState.indexType, offsetBuf[:len(mdl.offsets)])
+		} else {
+			gl.DrawElements(gl.Triangles, int(mdl.counts[0]), staticState.indexType, int(mdl.offsets[0])*m)
+		}
+	}
+
+	glintTex := GetTexture("misc/enchanted_item_glint")
+	glintRect := glintTex.Rect()
+	staticState.glintTime += delta / 60
+	glintTime := float32(math.Mod(staticState.glintTime, 1024))
+	var glintColors [10][4]float32
 
 	for _, c := range staticState.models {
 		c.program.Use()
@@ -227,26 +258,48 @@ func drawStatic() {
 		c.shader.CameraMatrix.Matrix4(&cameraMatrix)
 		c.shader.SkyOffset.Float(SkyOffset)
 		c.shader.LightLevel.Float(LightLevel)
+		c.shader.FogDistance.Float(FogDistance)
+		c.shader.FogColor.Float3(FogColour.R, FogColour.G, FogColour.B)
+		c.shader.GlintEnabled.Int(0)
+		var glinted []*StaticModel
 		for _, mdl := range c.models {
 			if mdl.Radius != 0 && !frustum.IsSphereInside(mdl.X, mdl.Y, mdl.Z, mdl.Radius) {
 				continue
 			}
-			mdl.array.Bind()
-			c.shader.Lighting.Float2(mdl.BlockLight, mdl.SkyLight)
-			if len(mdl.counts) > 1 {
-				copy(offsetBuf, mdl.offsets)
-				for i := range mdl.offsets {
-					offsetBuf[i] *= uintptr(m)
+			draw(c, mdl, mdl.Colors)
+			for _, g := range mdl.Glint {
+				if g {
+					glinted = append(glinted, mdl)
+					break
 				}
-				c.shader.ModelMatrix.Matrix4Multi(mdl.Matrix)
-				c.shader.ColorMul.FloatMutliRaw(mdl.Colors, len(mdl.Colors))
-				gl.MultiDrawElements(gl.Triangles, mdl.counts, staticState.indexType, offsetBuf[:len(mdl.offsets)])
-			} else {
-				c.shader.ModelMatrix.Matrix4Multi(mdl.Matrix)
-				c.shader.ColorMul.FloatMutliRaw(mdl.Colors, len(mdl.Colors))
-				gl.DrawElements(gl.Triangles, int(mdl.counts[0]), staticState.indexType, int(mdl.offsets[0])*m)
 			}
 		}
+		if len(glinted) == 0 {
+			continue
+		}
+
+		// The glint is added on top of the parts that have
+		// it, other parts are drawn black so they are left
+		// unchanged.
+		gl.BlendFunc(gl.OneFactor, gl.OneFactor)
+		c.shader.GlintEnabled.Int(1)
+		c.shader.GlintTime.Float(glintTime)
+		c.shader.GlintInfo.Float4(
+			float32(glintRect.X), float32(glintRect.Y),
+			float32(glintRect.Width), float32(glintRect.Height),
+		)
+		c.shader.GlintAtlas.Float(float32(glintTex.Atlas()))
+		for _, mdl := range glinted {
+			for i, g := range mdl.Glint {
+				glintColors[i] = [4]float32{}
+				if g {
+					glintColors[i] = [4]float32{0.5, 0.25, 0.8, 1.0}
+				}
+			}
+			draw(c, mdl, glintColors[:len(mdl.Glint)])
+		}
+		c.shader.GlintEnabled.Int(0)
+		gl.BlendFunc(gl.SrcAlpha, gl.OneMinusSrcAlpha)
 	}
 
 	gl.Disable(gl.Blend)
@@ -266,6 +319,12 @@ type staticShader struct {
 	Lighting          gl.Uniform   `gl:"lighting"`
 	LightLevel        gl.Uniform   `gl:"lightLevel"`
 	SkyOffset         gl.Uniform   `gl:"skyOffset"`
+	GlintEnabled      gl.Uniform   `gl:"glintEnabled"`
+	GlintTime         gl.Uniform   `gl:"glintTime"`
+	GlintInfo         gl.Uniform   `gl:"glintInfo"`
+	GlintAtlas        gl.Uniform   `gl:"glintAtlas"`
+	FogDistance       gl.Uniform   `gl:"fogDistance"`
+	FogColor          gl.Uniform   `gl:"fogColor"`
 }
 
 func init() {
@@ -289,12 +348,15 @@ out vec2 vTextureOffset;
 out float vAtlas;
 out float vID;
 out vec3 vLighting;
+out float vFogDist;
 
 #include get_light
 
 void main() {
 	vec3 pos = vec3(aPosition.x, -aPosition.y, aPosition.z);
-	gl_Position = perspectiveMatrix * cameraMatrix * modelMatrix[id] * vec4(pos, 1.0);
+	vec4 viewPos = cameraMatrix * modelMatrix[id] * vec4(pos, 1.0);
+	gl_Position = perspectiveMatrix * viewPos;
+	vFogDist = length(viewPos.xyz);
 
 	vColor = aColor;
 	vTextureInfo = aTextureInfo;
@@ -309,6 +371,12 @@ void main() {
 
 uniform sampler2DArray textures;
 uniform vec4 colorMul[10];
+uniform int glintEnabled;
+uniform float glintTime;
+uniform vec4 glintInfo;
+uniform float glintAtlas;
+uniform float fogDistance;
+uniform vec3 fogColor;
 
 in vec4 vColor;
 in vec4 vTextureInfo;
@@ -316,16 +384,30 @@ in vec2 vTextureOffset;
 in float vAtlas;
 in vec3 vLighting;
 in float vID;
+in float vFogDist;
 
 out vec4 fragColor;
 
 #include lookup_texture
+#include apply_fog
 
 void main() {
 	vec4 col = atlasTexture();
 	if (col.a <= 0.05) discard;
+	if (glintEnabled == 1) {
+		// Scrolls the glint texture across the model
+		vec2 gPos = vTextureOffset * 0.5 + vec2(glintTime * 8.0, glintTime * 4.0);
+		gPos = mod(gPos, glintInfo.zw) + glintInfo.xy;
+		vec4 glint = texture(textures, vec3(gPos * invAtlasSize, glintAtlas));
+		if (fogDistance > 0.0) {
+			glint.rgb *= 1.0 - clamp((vFogDist - fogDistance * 0.25) / (fogDistance * 0.75), 0.0, 1.0);
+		}
+		fragColor = vec4(glint.rgb * vLighting, 1.0) * colorMul[int(vID)];
+		return;
+	}
 	col *= vColor;
 	col.rgb *= vLighting;
+	col.rgb = applyFog(col.rgb);
 	fragColor = col * colorMul[int(vID)];
 }
 `)
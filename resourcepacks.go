@@ -106,6 +106,7 @@ func reloadResources() {
 	locale.Clear()
 	render.LoadSkinBuffer()
 	modelCache = map[string]*model{}
+	entityModelCache = map[string]*entityModel{}
 	console.Text("Reloading textures")
 	render.LoadTextures()
 	console.Text("Reloading biomes")
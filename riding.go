@@ -0,0 +1,126 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"github.com/thinkofdeath/steven/protocol"
+	"github.com/thinkofdeath/steven/render"
+	"github.com/thinkofdeath/steven/ui"
+	"github.com/thinkofdeath/steven/ui/scene"
+)
+
+// riding handles the player's input whilst riding a vehicle.
+type riding struct {
+	jumpBar     *ui.Image
+	jumpBarFill *ui.Image
+
+	// chargedJump is set whilst riding a vehicle that uses
+	// the jump bar
+	chargedJump bool
+	// jumpTime is the number of game ticks jump has been held
+	// for
+	jumpTime  float64
+	jumpPower float64
+}
+
+func (r *riding) init(scene *scene.Type, icons render.TextureInfo) {
+	// The jump bar replaces the exp bar whilst shown
+	r.jumpBar = ui.NewImage(icons, 0, 22*2+4, 182*2, 10, 0, 84.0/256.0, 182.0/256.0, 5.0/256.0, 255, 255, 255).
+		Attach(ui.Bottom, ui.Center)
+	r.jumpBar.SetDraw(false)
+	scene.AddDrawable(r.jumpBar)
+	r.jumpBarFill = ui.NewImage(icons, 0, 0, 0, 10, 0, 89.0/256.0, 0, 5.0/256.0, 255, 255, 255).
+		Attach(ui.Top, ui.Left)
+	r.jumpBarFill.AttachTo(r.jumpBar)
+	r.jumpBarFill.SetDraw(false)
+	scene.AddDrawable(r.jumpBarFill)
+}
+
+// tick charges the jump bar whilst jump is held and sends the
+// jump once it is released.
+func (r *riding) tick(vehicle Entity, isRiding bool, delta float64) {
+	v, ok := vehicle.(VehicleComponent)
+	r.chargedJump = isRiding && ok && v.ChargedJump()
+	Client.showExpBar(!r.chargedJump)
+	r.jumpBar.SetDraw(r.chargedJump)
+	r.jumpBarFill.SetDraw(r.chargedJump)
+	if !r.chargedJump {
+		r.jumpTime, r.jumpPower = 0, 0
+		return
+	}
+
+	if Client.KeyState[KeyJump] {
+		r.jumpTime += delta / 3
+		// Like vanilla the power drops slightly if jump is
+		// held after the bar fills
+		if r.jumpTime < 10 {
+			r.jumpPower = r.jumpTime * 0.1
+		} else {
+			r.jumpPower = 0.8 + 2/(r.jumpTime-9)*0.1
+		}
+	} else if r.jumpTime > 0 {
+		Client.network.Write(&protocol.PlayerAction{
+			EntityID:  protocol.VarInt(Client.entityID),
+			ActionID:  5, // Jump with horse
+			JumpBoost: protocol.VarInt(r.jumpPower * 100),
+		})
+		r.jumpTime, r.jumpPower = 0, 0
+	}
+	r.jumpBarFill.SetWidth(182 * 2 * r.jumpPower)
+	r.jumpBarFill.SetTextureWidth((182.0 / 256.0) * r.jumpPower)
+}
+
+// steer returns the packet containing the player's movement
+// input for the vehicle. Sneaking dismounts the player.
+func (r *riding) steer() *protocol.SteerVehicle {
+	const speed = 0.98
+	s := &protocol.SteerVehicle{}
+	keys := &Client.KeyState
+	if keys[KeyForward] {
+		s.Forward += speed
+	}
+	if keys[KeyBackwards] {
+		s.Forward -= speed
+	}
+	if keys[KeyLeft] {
+		s.Sideways += speed
+	}
+	if keys[KeyRight] {
+		s.Sideways -= speed
+	}
+	if keys[KeyJump] && !r.chargedJump {
+		s.Flags |= 0x1
+	}
+	if keys[KeySneak] {
+		s.Flags |= 0x2
+	}
+	return s
+}
+
+// dismount moves the player off of their vehicle and on to the
+// top of it.
+func (c *ClientState) dismount() {
+	vehicle, ok := c.entities.vehicle(c.entityID)
+	if !ok {
+		return
+	}
+	if p, ok := vehicle.(PositionComponent); ok {
+		c.X, c.Y, c.Z = p.Position()
+		if s, ok := vehicle.(SizeComponent); ok {
+			c.Y += float64(s.Bounds().Max.Y())
+		}
+	}
+	c.VSpeed = 0
+}
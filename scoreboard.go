@@ -0,0 +1,421 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/thinkofdeath/steven/format"
+	"github.com/thinkofdeath/steven/protocol"
+	"github.com/thinkofdeath/steven/render"
+	"github.com/thinkofdeath/steven/ui"
+	"github.com/thinkofdeath/steven/ui/scene"
+)
+
+const (
+	scoreboardList = iota
+	scoreboardSidebar
+	scoreboardBelowName
+)
+
+const maxSidebarScores = 15
+
+// teamColors maps the team color index sent by the server
+// to the matching chat color.
+var teamColors = [16]format.Color{
+	format.Black,
+	format.DarkBlue,
+	format.DarkGreen,
+	format.DarkAqua,
+	format.DarkRed,
+	format.DarkPurple,
+	format.Gold,
+	format.Gray,
+	format.DarkGray,
+	format.Blue,
+	format.Green,
+	format.Aqua,
+	format.Red,
+	format.LightPurple,
+	format.Yellow,
+	format.White,
+}
+
+type scoreboardObjective struct {
+	name        string
+	displayName string
+	hearts      bool
+	scores      map[string]int
+}
+
+type scoreboardTeam struct {
+	name         string
+	displayName  string
+	prefix       string
+	suffix       string
+	friendlyFire bool
+	seeInvisible bool
+	nameTag      string
+	color        byte
+	players      map[string]struct{}
+}
+
+type scoreboardState struct {
+	objectives map[string]*scoreboardObjective
+	display    [3]string
+	teams      map[string]*scoreboardTeam
+	// Reverse lookup of player name to team
+	playerTeams map[string]*scoreboardTeam
+
+	scene   *scene.Type
+	sidebar []ui.Drawable
+	dirty   bool
+}
+
+func (s *scoreboardState) init(sc *scene.Type) {
+	s.objectives = map[string]*scoreboardObjective{}
+	s.teams = map[string]*scoreboardTeam{}
+	s.playerTeams = map[string]*scoreboardTeam{}
+	s.scene = sc
+}
+
+func (s *scoreboardState) free() {
+	for _, d := range s.sidebar {
+		ui.Remove(d)
+	}
+	s.sidebar = nil
+}
+
+func (s *scoreboardState) handleObjective(p *protocol.ScoreboardObjective) {
+	switch p.Mode {
+	case 0: // Create
+		s.objectives[p.Name] = &scoreboardObjective{
+			name:        p.Name,
+			displayName: p.Value,
+			hearts:      p.Type == "hearts",
+			scores:      map[string]int{},
+		}
+	case 1: // Remove
+		delete(s.objectives, p.Name)
+		for i := range s.display {
+			if s.display[i] == p.Name {
+				s.display[i] = ""
+			}
+		}
+	case 2: // Update
+		o, ok := s.objectives[p.Name]
+		if !ok {
+			return
+		}
+		o.displayName = p.Value
+		o.hearts = p.Type == "hearts"
+	}
+	s.dirty = true
+}
+
+func (s *scoreboardState) handleScore(p *protocol.UpdateScore) {
+	switch p.Action {
+	case 0: // Update
+		o, ok := s.objectives[p.ObjectName]
+		if !ok {
+			return
+		}
+		o.scores[p.Name] = int(p.Value)
+	case 1: // Remove
+		if p.ObjectName == "" {
+			for _, o := range s.objectives {
+				delete(o.scores, p.Name)
+			}
+		} else if o, ok := s.objectives[p.ObjectName]; ok {
+			delete(o.scores, p.Name)
+		}
+	}
+	s.dirty = true
+}
+
+func (s *scoreboardState) handleDisplay(p *protocol.ScoreboardDisplay) {
+	if int(p.Position) >= len(s.display) {
+		return
+	}
+	s.display[p.Position] = p.Name
+	s.dirty = true
+}
+
+func (s *scoreboardState) handleTeam(p *protocol.Teams) {
+	switch p.Mode {
+	case 0: // Create
+		// Replaces the team if it already exists
+		s.removeTeam(p.Name)
+		t := &scoreboardTeam{
+			name:    p.Name,
+			players: map[string]struct{}{},
+		}
+		s.teams[p.Name] = t
+		t.update(p)
+		s.addPlayers(t, p.Players)
+	case 1: // Remove
+		s.removeTeam(p.Name)
+	case 2: // Update
+		t, ok := s.teams[p.Name]
+		if !ok {
+			return
+		}
+		t.update(p)
+	case 3: // Add players
+		t, ok := s.teams[p.Name]
+		if !ok {
+			return
+		}
+		s.addPlayers(t, p.Players)
+	case 4: // Remove players
+		t, ok := s.teams[p.Name]
+		if !ok {
+			return
+		}
+		for _, pl := range p.Players {
+			delete(t.players, pl)
+			if s.playerTeams[pl] == t {
+				delete(s.playerTeams, pl)
+			}
+		}
+	}
+	s.dirty = true
+}
+
+func (s *scoreboardState) removeTeam(name string) {
+	t, ok := s.teams[name]
+	if !ok {
+		return
+	}
+	for pl := range t.players {
+		delete(s.playerTeams, pl)
+	}
+	delete(s.teams, name)
+}
+
+func (s *scoreboardState) addPlayers(t *scoreboardTeam, players []string) {
+	for _, pl := range players {
+		// A player can only be on a single team at once
+		if old, ok := s.playerTeams[pl]; ok {
+			delete(old.players, pl)
+		}
+		t.players[pl] = struct{}{}
+		s.playerTeams[pl] = t
+	}
+}
+
+func (t *scoreboardTeam) update(p *protocol.Teams) {
+	t.displayName = p.DisplayName
+	t.prefix = p.Prefix
+	t.suffix = p.Suffix
+	t.friendlyFire = p.Flags&0x1 != 0
+	t.seeInvisible = p.Flags&0x2 != 0
+	t.nameTag = p.NameTagVisibility
+	t.color = p.Color
+}
+
+// legacyName returns the player's name decorated with their
+// team's prefix and suffix as a legacy formatted string.
+func (s *scoreboardState) legacyName(name string) string {
+	t, ok := s.playerTeams[name]
+	if !ok {
+		return name
+	}
+	return t.prefix + name + t.suffix
+}
+
+// formatName returns the player's name decorated with their
+// team's prefix, suffix and color.
+func (s *scoreboardState) formatName(name string) format.AnyComponent {
+	txt := &format.TextComponent{Text: s.legacyName(name)}
+	if t, ok := s.playerTeams[name]; ok && int(t.color) < len(teamColors) {
+		txt.Color = teamColors[t.color]
+	}
+	c := format.Wrap(txt)
+	format.ConvertLegacy(c)
+	return c
+}
+
+// nameTagVisible returns whether the named player's name tag
+// should be shown to the local player.
+func (s *scoreboardState) nameTagVisible(name string) bool {
+	t, ok := s.playerTeams[name]
+	if !ok {
+		return true
+	}
+	own := s.playerTeams[clientUsername.Value()] == t
+	switch t.nameTag {
+	case "never":
+		return false
+	case "hideForOtherTeams":
+		return own
+	case "hideForOwnTeam":
+		return !own
+	}
+	return true
+}
+
+// displayed returns the objective in the passed display slot
+// if any.
+func (s *scoreboardState) displayed(slot int) *scoreboardObjective {
+	return s.objectives[s.display[slot]]
+}
+
+// score returns the player's score for the objective in the
+// passed display slot.
+func (s *scoreboardState) score(slot int, name string) (*scoreboardObjective, int, bool) {
+	o := s.displayed(slot)
+	if o == nil {
+		return nil, 0, false
+	}
+	v, ok := o.scores[name]
+	return o, v, ok
+}
+
+// belowName returns the text shown below the player's name tag
+// or an empty string if there isn't any.
+func (s *scoreboardState) belowName(name string) string {
+	o, v, ok := s.score(scoreboardBelowName, name)
+	if !ok {
+		return ""
+	}
+	return strconv.Itoa(v) + " " + o.displayName
+}
+
+func (s *scoreboardState) render() {
+	if !s.dirty {
+		return
+	}
+	s.dirty = false
+	for _, d := range s.sidebar {
+		ui.Remove(d)
+	}
+	s.sidebar = s.sidebar[:0]
+
+	o := s.displayed(scoreboardSidebar)
+	if o == nil {
+		return
+	}
+	var scores []sidebarScore
+	for name, v := range o.scores {
+		// Names starting with a # are hidden from the sidebar
+		if len(name) > 0 && name[0] == '#' {
+			continue
+		}
+		scores = append(scores, sidebarScore{name, v})
+	}
+	sort.Sort(sortedSidebarScores(scores))
+	if len(scores) > maxSidebarScores {
+		scores = scores[:maxSidebarScores]
+	}
+
+	tc := format.Wrap(&format.TextComponent{Text: o.displayName})
+	format.ConvertLegacy(tc)
+	title := ui.NewFormatted(tc, 0, 1).
+		Attach(ui.Top, ui.Center)
+	width := title.Width
+
+	names := make([]*ui.Formatted, len(scores))
+	values := make([]*ui.Text, len(scores))
+	for i, sc := range scores {
+		names[i] = ui.NewFormatted(s.formatName(sc.name), 2, 18*float64(i+1)+1).
+			Attach(ui.Top, ui.Left)
+		values[i] = ui.NewText(strconv.Itoa(sc.value), 2, 18*float64(i+1)+1, 255, 85, 85).
+			Attach(ui.Top, ui.Right)
+		if w := names[i].Width + values[i].Width + 20; w > width {
+			width = w
+		}
+	}
+
+	height := 18 * float64(len(scores)+1)
+	background := ui.NewImage(render.GetTexture("solid"), 2, 0, width+4, height, 0, 0, 1, 1, 0, 0, 0).
+		Attach(ui.Middle, ui.Right)
+	background.SetA(80)
+	header := ui.NewImage(render.GetTexture("solid"), 0, 0, width+4, 18, 0, 0, 1, 1, 0, 0, 0).
+		Attach(ui.Top, ui.Left)
+	header.SetA(40)
+	header.AttachTo(background)
+	title.AttachTo(background)
+	s.add(background)
+	s.add(header)
+	s.add(title)
+	for i := range scores {
+		names[i].AttachTo(background)
+		values[i].AttachTo(background)
+		s.add(names[i])
+		s.add(values[i])
+	}
+}
+
+func (s *scoreboardState) add(d ui.Drawable) {
+	s.sidebar = append(s.sidebar, d)
+	s.scene.AddDrawable(d)
+}
+
+type sidebarScore struct {
+	name  string
+	value int
+}
+
+type sortedSidebarScores []sidebarScore
+
+func (s sortedSidebarScores) Len() int { return len(s) }
+func (s sortedSidebarScores) Less(a, b int) bool {
+	if s[a].value != s[b].value {
+		return s[a].value > s[b].value
+	}
+	return s[a].name < s[b].name
+}
+func (s sortedSidebarScores) Swap(a, b int) { s[a], s[b] = s[b], s[a] }
+
+// legacyColor returns the color selected by the legacy
+// formatting code. Codes that don't change the color return
+// the passed color.
+func legacyColor(code rune, r, g, b byte) (byte, byte, byte) {
+	code = unicode.ToLower(code)
+	var i int
+	switch {
+	case code >= '0' && code <= '9':
+		i = int(code - '0')
+	case code >= 'a' && code <= 'f':
+		i = int(code-'a') + 10
+	case code == 'r':
+		i = 15
+	default:
+		return r, g, b
+	}
+	rr, gg, bb := chatColorRGB(teamColors[i])
+	return byte(rr), byte(gg), byte(bb)
+}
+
+// stripLegacy removes legacy formatting codes from the string.
+func stripLegacy(str string) string {
+	if !strings.ContainsRune(str, '§') {
+		return str
+	}
+	text := []rune(str)
+	out := make([]rune, 0, len(text))
+	for i := 0; i < len(text); i++ {
+		if text[i] == '§' && i+1 < len(text) {
+			i++
+			continue
+		}
+		out = append(out, text[i])
+	}
+	return string(out)
+}
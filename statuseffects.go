@@ -0,0 +1,326 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/thinkofdeath/steven/format"
+	"github.com/thinkofdeath/steven/render"
+	"github.com/thinkofdeath/steven/ui"
+	"github.com/thinkofdeath/steven/ui/scene"
+)
+
+// Status effects that change how the world is shown or how the
+// player moves.
+const (
+	effectSpeed        = 1
+	effectSlowness     = 2
+	effectJumpBoost    = 8
+	effectNausea       = 9
+	effectInvisibility = 14
+	effectBlindness    = 15
+	effectNightVision  = 16
+)
+
+// effectMaxDuration is the duration used by the server for effects
+// that don't run out, e.g. ones given by a beacon.
+const effectMaxDuration = 32767
+
+type effectType struct {
+	name string
+	// icon is the index of the effect's icon in the inventory
+	// texture, -1 if the effect doesn't have one.
+	icon int
+}
+
+var effectTypes = map[int]effectType{
+	1:  {"moveSpeed", 0},
+	2:  {"moveSlowdown", 1},
+	3:  {"digSpeed", 2},
+	4:  {"digSlowDown", 3},
+	5:  {"damageBoost", 4},
+	6:  {"heal", -1},
+	7:  {"harm", -1},
+	8:  {"jump", 10},
+	9:  {"confusion", 11},
+	10: {"regeneration", 7},
+	11: {"resistance", 14},
+	12: {"fireResistance", 15},
+	13: {"waterBreathing", 16},
+	14: {"invisibility", 8},
+	15: {"blindness", 13},
+	16: {"nightVision", 12},
+	17: {"hunger", 9},
+	18: {"weakness", 5},
+	19: {"poison", 6},
+	20: {"wither", 17},
+	21: {"healthBoost", 23},
+	22: {"absorption", 18},
+	23: {"saturation", -1},
+}
+
+// effectIcon returns the texture coordinates of the effect's
+// icon.
+func effectIcon(icon int) (x, y float64) {
+	return float64(icon%8) * 18, 198 + float64(icon/8)*18
+}
+
+type statusEffect struct {
+	id        int
+	amplifier int
+	// duration is the number of game ticks left
+	duration float64
+}
+
+func (s *statusEffect) infinite() bool {
+	return s.duration >= effectMaxDuration
+}
+
+// name returns the name of the effect along with its level.
+func (s *statusEffect) name() format.AnyComponent {
+	name := &format.TextComponent{}
+	name.Extra = append(name.Extra, format.Wrap(&format.TranslateComponent{
+		Translate: "potion." + effectTypes[s.id].name,
+	}))
+	if s.amplifier > 0 && s.amplifier < 10 {
+		name.Extra = append(name.Extra,
+			format.Wrap(&format.TextComponent{Text: " "}),
+			format.Wrap(&format.TranslateComponent{
+				Translate: fmt.Sprintf("enchantment.level.%d", s.amplifier+1),
+			}),
+		)
+	}
+	return format.Wrap(name)
+}
+
+// durationString formats the time left as minutes and seconds.
+func (s *statusEffect) durationString() string {
+	if s.infinite() {
+		return "**:**"
+	}
+	secs := int(s.duration / 20)
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
+
+// effectState tracks the status effects applied to the player and
+// displays them on the hud.
+type effectState struct {
+	scene   *scene.Type
+	effects []*statusEffect
+	hud     []ui.Drawable
+	timers  []*ui.Text
+	dirty   bool
+
+	warp float64
+}
+
+func (e *effectState) init(sc *scene.Type) {
+	e.scene = sc
+}
+
+func (e *effectState) free() {
+	for _, d := range e.hud {
+		ui.Remove(d)
+	}
+	e.hud, e.timers = nil, nil
+	render.Warp = 0
+	render.FogDistance = 0
+}
+
+func (e *effectState) add(id, amplifier, duration int) {
+	if _, ok := effectTypes[id]; !ok {
+		return
+	}
+	ef := e.get(id)
+	if ef == nil {
+		ef = &statusEffect{id: id}
+		e.effects = append(e.effects, ef)
+	}
+	ef.amplifier = amplifier
+	ef.duration = float64(duration)
+	e.changed()
+}
+
+func (e *effectState) remove(id int) {
+	for i, ef := range e.effects {
+		if ef.id == id {
+			e.effects = append(e.effects[:i], e.effects[i+1:]...)
+			e.changed()
+			return
+		}
+	}
+}
+
+// clear removes all effects, used when the player respawns.
+func (e *effectState) clear() {
+	e.effects = e.effects[:0]
+	e.changed()
+}
+
+func (e *effectState) changed() {
+	e.dirty = true
+	if Client.activeInventory == Client.playerInventory {
+		Client.playerInventory.UpdateProperties()
+	}
+}
+
+func (e *effectState) get(id int) *statusEffect {
+	for _, ef := range e.effects {
+		if ef.id == id {
+			return ef
+		}
+	}
+	return nil
+}
+
+// speedMultiplier returns the amount the player's walking speed
+// is changed by speed and slowness. Only used until the server
+// sends the player's movement speed.
+func (e *effectState) speedMultiplier() float64 {
+	m := 1.0
+	if ef := e.get(effectSpeed); ef != nil {
+		m += 0.2 * float64(ef.amplifier+1)
+	}
+	if ef := e.get(effectSlowness); ef != nil {
+		m -= 0.15 * float64(ef.amplifier+1)
+	}
+	return math.Max(0, m)
+}
+
+// jumpMultiplier returns the amount the player's jump velocity
+// is changed by jump boost.
+func (e *effectState) jumpMultiplier() float64 {
+	if ef := e.get(effectJumpBoost); ef != nil {
+		return (0.42 + 0.1*float64(ef.amplifier+1)) / 0.42
+	}
+	return 1
+}
+
+// tick counts down the effects and applies their effects on the
+// renderer.
+func (e *effectState) tick(delta float64) {
+	dt := delta / 3
+	timersChanged := false
+	for i, ef := range e.effects {
+		if ef.infinite() {
+			continue
+		}
+		last := int(ef.duration / 20)
+		ef.duration = math.Max(0, ef.duration-dt)
+		if int(ef.duration/20) != last {
+			timersChanged = true
+			if i < len(e.timers) && e.timers[i] != nil {
+				e.timers[i].Update(ef.durationString())
+			}
+		}
+	}
+	if timersChanged && Client.activeInventory == Client.playerInventory {
+		Client.playerInventory.UpdateProperties()
+	}
+	e.render()
+
+	base := Client.WorldType.lightLevel()
+	render.LightLevel = base
+	if ef := e.get(effectNightVision); ef != nil {
+		nv := 1.0
+		// Flickers as it is about to run out
+		if !ef.infinite() && ef.duration < 200 {
+			nv = 0.7 + math.Sin(ef.duration*math.Pi*0.2)*0.3
+		}
+		render.LightLevel = base + (1-base)*float32(nv)
+	}
+
+	render.FogDistance = 0
+	if ef := e.get(effectBlindness); ef != nil {
+		const blindDistance, fullDistance = 5.0, 256.0
+		dist := blindDistance
+		// Fades back in over the last second
+		if !ef.infinite() && ef.duration < 20 {
+			dist += (fullDistance - blindDistance) * (1 - ef.duration/20)
+		}
+		render.FogDistance = float32(dist)
+	}
+
+	if e.get(effectNausea) != nil {
+		e.warp = math.Min(1, e.warp+dt/150)
+	} else {
+		e.warp = math.Max(0, e.warp-dt*0.05)
+	}
+	render.Warp = e.warp
+}
+
+// render rebuilds the hud icons if the effects have changed.
+func (e *effectState) render() {
+	if !e.dirty {
+		return
+	}
+	e.dirty = false
+	for _, d := range e.hud {
+		ui.Remove(d)
+	}
+	e.hud = e.hud[:0]
+	e.timers = e.timers[:0]
+
+	tex := render.GetTexture("gui/container/inventory")
+	x := 4.0
+	for _, ef := range e.effects {
+		icon := effectTypes[ef.id].icon
+		if icon < 0 {
+			e.timers = append(e.timers, nil)
+			continue
+		}
+		tx, ty := effectIcon(icon)
+		img := ui.NewImage(tex, x, 4, 36, 36, tx/256.0, ty/256.0, 18/256.0, 18/256.0, 255, 255, 255).
+			Attach(ui.Top, ui.Right)
+		e.scene.AddDrawable(img)
+		timer := ui.NewText(ef.durationString(), 0, 38, 255, 255, 255).
+			Attach(ui.Top, ui.Center)
+		timer.AttachTo(img)
+		e.scene.AddDrawable(timer)
+		e.hud = append(e.hud, img, timer)
+		e.timers = append(e.timers, timer)
+		x += 56
+	}
+}
+
+// drawInventory draws the effect panels to the left of the
+// player's inventory.
+func (e *effectState) drawInventory(s *scene.Type, inv *Inventory) {
+	const texture = "gui/container/inventory"
+	spacing := 33.0
+	if len(e.effects) > 5 {
+		spacing = 132 / float64(len(e.effects)-1)
+	}
+	for i, ef := range e.effects {
+		y := spacing * float64(i)
+		drawWindowPart(s, inv, texture, -124, y, 0, 166, 140, 32)
+		if icon := effectTypes[ef.id].icon; icon >= 0 {
+			tx, ty := effectIcon(icon)
+			img := drawWindowPart(s, inv, texture, -124+6, y+7, tx, ty, 18, 18)
+			img.SetLayer(2)
+		}
+
+		name := ui.NewFormatted(ef.name(), (-124+28)*2, (y+6)*2)
+		name.AttachTo(inv.background)
+		name.SetLayer(2)
+		s.AddDrawable(name.Attach(ui.Top, ui.Left))
+		timer := ui.NewText(ef.durationString(), (-124+28)*2, (y+16)*2, 127, 127, 127)
+		timer.AttachTo(inv.background)
+		timer.SetLayer(2)
+		s.AddDrawable(timer.Attach(ui.Top, ui.Left))
+	}
+}
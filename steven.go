@@ -106,6 +106,7 @@ func connect(server string) {
 	setScreen(nil)
 	connected = true
 	initClient()
+	Client.chat.log.setServer(server)
 	disconnectReason.Value = nil
 	Client.network.Connect(getProfile(), server)
 }
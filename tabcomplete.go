@@ -0,0 +1,205 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/thinkofdeath/steven/console"
+	"github.com/thinkofdeath/steven/protocol"
+	"github.com/thinkofdeath/steven/render"
+	"github.com/thinkofdeath/steven/ui"
+)
+
+// Max number of suggestions shown at once in the chat popup
+const maxCompletionPopup = 10
+
+// tabCompletion tracks a set of matches for the last word of an
+// input line and cycles through them.
+type tabCompletion struct {
+	base    string
+	matches []string
+	index   int
+	result  string
+}
+
+// set replaces the matches and returns the input with the
+// first match applied.
+func (t *tabCompletion) set(input string, matches []string) string {
+	t.base = input[:strings.LastIndex(input, " ")+1]
+	t.matches = matches
+	t.index = 0
+	t.result = t.base + matches[0]
+	return t.result
+}
+
+// active returns whether the input is the result of the last
+// completion and can be cycled further.
+func (t *tabCompletion) active(input string) bool {
+	return len(t.matches) > 0 && input == t.result
+}
+
+// next returns the input with the next match applied.
+func (t *tabCompletion) next() string {
+	t.index = (t.index + 1) % len(t.matches)
+	t.result = t.base + t.matches[t.index]
+	return t.result
+}
+
+func (t *tabCompletion) reset() {
+	t.base = ""
+	t.matches = nil
+	t.index = 0
+	t.result = ""
+}
+
+// lastWord returns the part of the input after the last space.
+func lastWord(input string) string {
+	return input[strings.LastIndex(input, " ")+1:]
+}
+
+// completeLocal completes the input using the console's command
+// tree. Arguments that the tree can't complete are completed with
+// the names of the players on the server.
+func completeLocal(input string) []string {
+	matches := console.Complete(input)
+	if len(matches) == 0 && strings.Contains(input, " ") {
+		matches = playerNames(lastWord(input))
+	}
+	return matches
+}
+
+// playerNames returns the sorted names of the players on the
+// server that start with the prefix, ignoring case.
+func playerNames(prefix string) []string {
+	if Client == nil {
+		return nil
+	}
+	prefix = strings.ToLower(prefix)
+	var names []string
+	for _, pl := range Client.playerList.info {
+		if strings.HasPrefix(strings.ToLower(pl.name), prefix) {
+			names = append(names, pl.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completeChat either cycles the current completion or requests
+// a new set of matches from the server.
+func (c *ChatUI) completeChat() {
+	input := string(c.inputLine)
+	if c.completion.active(input) {
+		c.inputLine = []rune(c.completion.next())
+		c.updateCompletionPopup()
+		return
+	}
+	c.resetCompletion()
+	c.completionRequest = input
+	c.completionPending = true
+	Client.network.Write(&protocol.TabComplete{Text: input})
+}
+
+// handleCompletion merges the server's matches with the names of
+// the players on the server and applies the first one. Local
+// console commands aren't offered as chat is always sent to the
+// server.
+func (c *ChatUI) handleCompletion(matches []string) {
+	input := string(c.inputLine)
+	if !c.enteringText || !c.completionPending || input != c.completionRequest {
+		return
+	}
+	c.completionPending = false
+
+	seen := map[string]bool{}
+	var all []string
+	add := func(matches []string) {
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				all = append(all, m)
+			}
+		}
+	}
+	add(matches)
+	if word := lastWord(input); !strings.HasPrefix(word, "/") {
+		add(playerNames(word))
+	}
+	if len(all) == 0 {
+		return
+	}
+	c.inputLine = []rune(c.completion.set(input, all))
+	c.updateCompletionPopup()
+}
+
+func (c *ChatUI) resetCompletion() {
+	c.completion.reset()
+	c.completionPending = false
+	for _, d := range c.completionPopup {
+		ui.Remove(d)
+	}
+	c.completionPopup = c.completionPopup[:0]
+}
+
+// updateCompletionPopup shows the matches for the current
+// completion above the input line with the selected one
+// highlighted.
+func (c *ChatUI) updateCompletionPopup() {
+	for _, d := range c.completionPopup {
+		ui.Remove(d)
+	}
+	c.completionPopup = c.completionPopup[:0]
+	if len(c.completion.matches) <= 1 {
+		return
+	}
+
+	// Keep the selected match within the visible window
+	start := 0
+	if c.completion.index >= maxCompletionPopup {
+		start = c.completion.index - maxCompletionPopup + 1
+	}
+	end := start + maxCompletionPopup
+	if end > len(c.completion.matches) {
+		end = len(c.completion.matches)
+	}
+	visible := c.completion.matches[start:end]
+
+	width := 0.0
+	for _, m := range visible {
+		if w := render.SizeOfString(m); w > width {
+			width = w
+		}
+	}
+	x := 5 + render.SizeOfString(c.completion.base)
+	for i, m := range visible {
+		y := 20 + 18*float64(len(visible)-1-i)
+		background := ui.NewImage(render.GetTexture("solid"), x-2, y, width+4, 18, 0, 0, 1, 1, 0, 0, 0).
+			Attach(ui.Bottom, ui.Left)
+		background.SetA(200)
+		background.AttachTo(c.container)
+		txt := ui.NewText(m, x, y+1, 170, 170, 170).Attach(ui.Bottom, ui.Left)
+		if start+i == c.completion.index {
+			txt.SetR(255)
+			txt.SetG(255)
+			txt.SetB(85)
+		}
+		txt.AttachTo(c.container)
+		Client.scene.AddDrawable(background)
+		Client.scene.AddDrawable(txt)
+		c.completionPopup = append(c.completionPopup, background, txt)
+	}
+}
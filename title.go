@@ -0,0 +1,143 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"github.com/thinkofdeath/steven/format"
+	"github.com/thinkofdeath/steven/protocol"
+	"github.com/thinkofdeath/steven/ui"
+	"github.com/thinkofdeath/steven/ui/scene"
+)
+
+// Default title timings in ticks
+const (
+	defaultTitleFadeIn  = 10
+	defaultTitleStay    = 70
+	defaultTitleFadeOut = 20
+
+	actionBarTime = 60
+)
+
+type titleState struct {
+	title    *ui.Formatted
+	subTitle *ui.Formatted
+
+	subTitleValue         format.AnyComponent
+	fadeIn, stay, fadeOut float64
+	time                  float64
+
+	actionBar      *ui.Formatted
+	actionBarTimer float64
+}
+
+func (t *titleState) init(sc *scene.Type, hotbar *ui.Image) {
+	t.fadeIn, t.stay, t.fadeOut = defaultTitleFadeIn, defaultTitleStay, defaultTitleFadeOut
+	t.subTitleValue = emptyComponent()
+
+	t.title = ui.NewFormatted(emptyComponent(), 0, -40).
+		Attach(ui.Middle, ui.Center)
+	t.title.SetScaleX(4)
+	t.title.SetScaleY(4)
+	t.title.SetDraw(false)
+	sc.AddDrawable(t.title)
+
+	t.subTitle = ui.NewFormatted(emptyComponent(), 0, 40).
+		Attach(ui.Middle, ui.Center)
+	t.subTitle.SetScaleX(2)
+	t.subTitle.SetScaleY(2)
+	t.subTitle.SetDraw(false)
+	sc.AddDrawable(t.subTitle)
+
+	t.actionBar = ui.NewFormatted(emptyComponent(), 0, -16-8-10-16-20-36)
+	t.actionBar.AttachTo(hotbar)
+	t.actionBar.SetDraw(false)
+	sc.AddDrawable(t.actionBar.Attach(ui.Top, ui.Middle))
+}
+
+func emptyComponent() format.AnyComponent {
+	return format.Wrap(&format.TextComponent{})
+}
+
+func (t *titleState) handle(p *protocol.Title) {
+	switch p.Action {
+	case 0: // Title
+		if p.Title.Value == nil {
+			return
+		}
+		format.ConvertLegacy(p.Title)
+		t.title.Update(p.Title)
+		t.subTitle.Update(t.subTitleValue)
+		t.time = t.fadeIn + t.stay + t.fadeOut
+	case 1: // Subtitle
+		if p.SubTitle.Value == nil {
+			return
+		}
+		format.ConvertLegacy(p.SubTitle)
+		t.subTitleValue = p.SubTitle
+		t.subTitle.Update(p.SubTitle)
+	case 2: // Times
+		t.fadeIn = float64(p.FadeIn)
+		t.stay = float64(p.FadeStay)
+		t.fadeOut = float64(p.FadeOut)
+	case 3: // Clear
+		t.time = 0
+	case 4: // Reset
+		t.time = 0
+		t.subTitleValue = emptyComponent()
+		t.subTitle.Update(t.subTitleValue)
+		t.fadeIn, t.stay, t.fadeOut = defaultTitleFadeIn, defaultTitleStay, defaultTitleFadeOut
+	}
+}
+
+// setActionBar displays the message above the hotbar for a
+// short time.
+func (t *titleState) setActionBar(msg format.AnyComponent) {
+	format.ConvertLegacy(msg)
+	t.actionBar.Update(msg)
+	t.actionBarTimer = actionBarTime
+}
+
+func (t *titleState) tick(delta float64) {
+	// Timings are in ticks which are 3 frames
+	ticks := delta / 3
+
+	if t.time > 0 {
+		t.time -= ticks
+		elapsed := t.fadeIn + t.stay + t.fadeOut - t.time
+		alpha := 1.0
+		if elapsed < t.fadeIn {
+			alpha = elapsed / t.fadeIn
+		} else if t.time < t.fadeOut {
+			alpha = t.time / t.fadeOut
+		}
+		alpha = clampFloat(alpha, 0, 1)
+		setFormattedAlpha(t.title, alpha)
+		setFormattedAlpha(t.subTitle, alpha)
+	}
+	t.title.SetDraw(t.time > 0)
+	t.subTitle.SetDraw(t.time > 0)
+
+	if t.actionBarTimer > 0 {
+		t.actionBarTimer -= ticks
+		setFormattedAlpha(t.actionBar, clampFloat(t.actionBarTimer/20, 0, 1))
+	}
+	t.actionBar.SetDraw(t.actionBarTimer > 0)
+}
+
+func setFormattedAlpha(f *ui.Formatted, alpha float64) {
+	for _, txt := range f.Text {
+		txt.SetA(int(255 * alpha))
+	}
+}
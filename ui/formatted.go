@@ -34,6 +34,18 @@ type Formatted struct {
 	Lines         int
 
 	Text []*Text
+	// Events contains the events for each of the text runs
+	// in Text.
+	Events []Event
+}
+
+// Event contains the click and hover events that apply to a
+// run of formatted text. These are inherited from the parent
+// components.
+type Event struct {
+	Click     *format.ClickEvent
+	Hover     *format.HoverEvent
+	Insertion string
 }
 
 // NewFormatted creates a new Formatted drawable.
@@ -139,10 +151,11 @@ func (f *Formatted) Remove() {
 func (f *Formatted) Update(val format.AnyComponent) {
 	f.value = val
 	f.Text = f.Text[:0]
+	f.Events = f.Events[:0]
 	state := formatState{
 		f: f,
 	}
-	state.build(val, func() format.Color { return format.White })
+	state.build(val, func() format.Color { return format.White }, Event{})
 	f.Height = float64(state.lines+1) * 18
 	f.Width = state.width
 	f.Lines = state.lines + 1
@@ -174,25 +187,27 @@ type formatState struct {
 	width  float64
 }
 
-func (f *formatState) build(c format.AnyComponent, color getColorFunc) {
+func (f *formatState) build(c format.AnyComponent, color getColorFunc, ev Event) {
 	switch c := c.Value.(type) {
 	case *format.TextComponent:
 		gc := getColor(&c.Component, color)
-		f.appendText(c.Text, gc)
+		ev := getEvent(&c.Component, ev)
+		f.appendText(c.Text, gc, ev)
 		for _, e := range c.Extra {
-			f.build(e, gc)
+			f.build(e, gc, ev)
 		}
 	case *format.TranslateComponent:
 		gc := getColor(&c.Component, color)
+		ev := getEvent(&c.Component, ev)
 		for _, part := range locale.Get(c.Translate) {
 			switch part := part.(type) {
 			case string:
-				f.appendText(part, gc)
+				f.appendText(part, gc, ev)
 			case int:
 				if part < 0 || part >= len(c.With) {
 					continue
 				}
-				f.build(c.With[part], gc)
+				f.build(c.With[part], gc, ev)
 			}
 		}
 
@@ -201,7 +216,7 @@ func (f *formatState) build(c format.AnyComponent, color getColorFunc) {
 	}
 }
 
-func (f *formatState) appendText(text string, color getColorFunc) {
+func (f *formatState) appendText(text string, color getColorFunc, ev Event) {
 	width := 0.0
 	last := 0
 	for i, r := range text {
@@ -215,6 +230,7 @@ func (f *formatState) appendText(text string, color getColorFunc) {
 				last++
 			}
 			f.f.Text = append(f.f.Text, txt)
+			f.f.Events = append(f.f.Events, ev)
 			f.offset = 0
 			f.lines++
 			width = 0
@@ -229,6 +245,7 @@ func (f *formatState) appendText(text string, color getColorFunc) {
 		txt := NewText(text[last:], f.offset, float64(f.lines*18+1), r, g, b)
 		txt.AttachTo(f.f)
 		f.f.Text = append(f.f.Text, txt)
+		f.f.Events = append(f.f.Events, ev)
 		f.offset += txt.Width + 2
 		if f.offset > f.width {
 			f.width = f.offset
@@ -236,6 +253,33 @@ func (f *formatState) appendText(text string, color getColorFunc) {
 	}
 }
 
+func getEvent(c *format.Component, parent Event) Event {
+	if c.ClickEvent != nil {
+		parent.Click = c.ClickEvent
+	}
+	if c.HoverEvent != nil {
+		parent.Hover = c.HoverEvent
+	}
+	if c.Insertion != "" {
+		parent.Insertion = c.Insertion
+	}
+	return parent
+}
+
+// EventAt returns the events for the text run at the passed
+// screen location.
+func (f *Formatted) EventAt(x, y float64, width, height int) (Event, bool) {
+	if !f.ShouldDraw() {
+		return Event{}, false
+	}
+	for i, t := range f.Text {
+		if _, _, ok := Intersects(t, x, y, width, height); ok {
+			return f.Events[i], true
+		}
+	}
+	return Event{}, false
+}
+
 type getColorFunc func() format.Color
 
 func getColor(c *format.Component, parent getColorFunc) getColorFunc {
@@ -193,6 +193,19 @@ func Intersects(d Drawable, x, y float64, width, height int) (float64, float64,
 	return 0, 0, false
 }
 
+// ScreenOffset converts the screen location into an offset for
+// a drawable attached to the top left of the screen.
+func ScreenOffset(x, y float64, width, height int) (float64, float64) {
+	sw := scaledWidth / float64(width)
+	sh := scaledHeight / float64(height)
+	if DrawMode == Unscaled {
+		sw, sh = Scale, Scale
+	}
+	x = (x / float64(width)) * scaledWidth
+	y = (y / float64(height)) * scaledHeight
+	return x / sw, y / sh
+}
+
 func getDrawRegion(d Drawable, sw, sh float64) Region {
 	parent := d.AttachedTo()
 	var superR Region
@@ -0,0 +1,183 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/thinkofdeath/steven/encoding/nbt"
+	"github.com/thinkofdeath/steven/format"
+	"github.com/thinkofdeath/steven/render"
+	"github.com/thinkofdeath/steven/ui"
+	"github.com/thinkofdeath/steven/ui/scene"
+)
+
+// bookScreen displays the pages of a written book
+type bookScreen struct {
+	baseUI
+	scene *scene.Type
+
+	background *ui.Image
+	book       *ui.Image
+	pageNumber *ui.Text
+	text       *ui.Formatted
+	tooltip    hoverTooltip
+
+	pages []format.AnyComponent
+	page  int
+}
+
+func newBookScreen(item *ItemStack) *bookScreen {
+	bs := &bookScreen{
+		scene: scene.New(true),
+		pages: bookPages(item),
+	}
+	if len(bs.pages) == 0 {
+		bs.pages = append(bs.pages, emptyComponent())
+	}
+
+	bs.background = ui.NewImage(render.GetTexture("solid"), 0, 0, 854, 480, 0, 0, 1, 1, 0, 0, 0)
+	bs.background.SetA(160)
+	bs.scene.AddDrawable(bs.background.Attach(ui.Top, ui.Left))
+
+	bs.book = ui.NewImage(render.GetTexture("gui/book"), 0, -20, 192*2, 192*2, 0, 0, 192/256.0, 192/256.0, 255, 255, 255)
+	bs.scene.AddDrawable(bs.book.Attach(ui.Center, ui.Middle))
+
+	bs.pageNumber = ui.NewText("", 44*2, 16*2, 0, 0, 0)
+	bs.pageNumber.AttachTo(bs.book)
+	bs.scene.AddDrawable(bs.pageNumber.Attach(ui.Top, ui.Right))
+
+	bs.text = ui.NewFormattedWidth(emptyComponent(), 36*2, 30*2, 116*2)
+	bs.text.AttachTo(bs.book)
+	bs.scene.AddDrawable(bs.text.Attach(ui.Top, ui.Left))
+
+	prev, txt := newButtonText("<", -60, 200, 80, 40)
+	bs.scene.AddDrawable(prev.Attach(ui.Center, ui.Middle))
+	bs.scene.AddDrawable(txt)
+	prev.AddClick(func() { bs.setPage(bs.page - 1) })
+
+	next, txt := newButtonText(">", 60, 200, 80, 40)
+	bs.scene.AddDrawable(next.Attach(ui.Center, ui.Middle))
+	bs.scene.AddDrawable(txt)
+	next.AddClick(func() { bs.setPage(bs.page + 1) })
+
+	bs.setPage(0)
+	return bs
+}
+
+// bookPages returns the pages of the book item. Written books
+// store each page as a json component whilst writable books use
+// plain text.
+func bookPages(item *ItemStack) (pages []format.AnyComponent) {
+	if item == nil || item.rawTag == nil {
+		return nil
+	}
+	list, ok := item.rawTag.Items["pages"].(*nbt.List)
+	if !ok {
+		return nil
+	}
+	written := item.Type.Name() == "written_book"
+	for _, p := range list.Elements {
+		str, _ := p.(string)
+		var c format.AnyComponent
+		if !written || json.Unmarshal([]byte(str), &c) != nil {
+			c = format.Wrap(&format.TextComponent{Text: str})
+		}
+		format.ConvertLegacy(c)
+		pages = append(pages, c)
+	}
+	return pages
+}
+
+func (bs *bookScreen) setPage(page int) {
+	if page < 0 || page >= len(bs.pages) {
+		return
+	}
+	bs.page = page
+	bs.tooltip.hide()
+	bs.text.Update(bs.pages[page])
+	// The book text is black unless the page says otherwise
+	for _, t := range bs.text.Text {
+		if t.R() == 255 && t.G() == 255 && t.B() == 255 {
+			t.SetR(0)
+			t.SetG(0)
+			t.SetB(0)
+		}
+	}
+	bs.pageNumber.Update(fmt.Sprintf("Page %d of %d", page+1, len(bs.pages)))
+}
+
+func (bs *bookScreen) init() {
+	window.SetKeyCallback(bs.handleKey)
+}
+
+func (bs *bookScreen) tick(delta float64) {
+	width, height := window.GetFramebufferSize()
+	bs.background.SetWidth(float64(width) / ui.Scale)
+	bs.background.SetHeight(float64(height) / ui.Scale)
+}
+
+func (bs *bookScreen) hover(x, y float64, w, h int) {
+	ui.Hover(x, y, w, h)
+	if ev, ok := bs.text.EventAt(x, y, w, h); ok && ev.Hover != nil {
+		bs.tooltip.show(bs.scene, ev.Hover, x, y, w, h)
+		return
+	}
+	bs.tooltip.hide()
+}
+
+func (bs *bookScreen) click(down bool, x, y float64, w, h int) {
+	if down {
+		return
+	}
+	if ev, ok := bs.text.EventAt(x, y, w, h); ok && ev.Click != nil {
+		if ev.Click.Action == format.ChangePage {
+			if page, err := strconv.Atoi(ev.Click.Value); err == nil {
+				bs.setPage(page - 1)
+			}
+			return
+		}
+		if handleClickEvent(ev.Click) && currentScreen == bs {
+			setScreen(nil)
+		}
+		return
+	}
+	ui.Click(x, y, w, h)
+}
+
+func (bs *bookScreen) handleKey(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+	if key == glfw.KeyEscape && action == glfw.Release {
+		setScreen(nil)
+		return
+	}
+	if action == glfw.Release {
+		return
+	}
+	switch key {
+	case glfw.KeyLeft:
+		bs.setPage(bs.page - 1)
+	case glfw.KeyRight:
+		bs.setPage(bs.page + 1)
+	}
+}
+
+func (bs *bookScreen) remove() {
+	bs.tooltip.hide()
+	bs.scene.Hide()
+	window.SetKeyCallback(onKey)
+}
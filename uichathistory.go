@@ -0,0 +1,189 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/thinkofdeath/steven/format"
+	"github.com/thinkofdeath/steven/render"
+	"github.com/thinkofdeath/steven/ui"
+	"github.com/thinkofdeath/steven/ui/scene"
+)
+
+// chatHistoryScreen allows for scrolling back through and
+// searching all the chat messages received this session.
+type chatHistoryScreen struct {
+	baseUI
+	scene *scene.Type
+
+	background *ui.Image
+	search     *ui.TextBox
+	count      *ui.Text
+	lines      []*ui.Formatted
+	tooltip    hoverTooltip
+
+	query      string
+	matches    []*chatEntry
+	scroll     int
+	lastChange int
+	dirty      bool
+}
+
+func newChatHistoryScreen() *chatHistoryScreen {
+	hs := &chatHistoryScreen{
+		scene: scene.New(true),
+		dirty: true,
+	}
+
+	hs.background = ui.NewImage(render.GetTexture("solid"), 0, 0, 854, 480, 0, 0, 1, 1, 0, 0, 0)
+	hs.background.SetA(200)
+	hs.scene.AddDrawable(hs.background.Attach(ui.Top, ui.Left))
+
+	hs.search = ui.NewTextBox(0, 20, 400, 40)
+	hs.scene.AddDrawable(hs.search.Attach(ui.Top, ui.Center))
+	label := ui.NewText("Search:", 0, -18, 255, 255, 255).Attach(ui.Top, ui.Left)
+	label.AttachTo(hs.search)
+	hs.scene.AddDrawable(label)
+
+	hs.count = ui.NewText("", 10, 30, 170, 170, 170)
+	hs.scene.AddDrawable(hs.count.Attach(ui.Top, ui.Right))
+	return hs
+}
+
+func (hs *chatHistoryScreen) init() {
+	window.SetKeyCallback(hs.handleKey)
+	window.SetScrollCallback(hs.onScroll)
+}
+
+func (hs *chatHistoryScreen) tick(delta float64) {
+	width, height := window.GetFramebufferSize()
+	hs.background.SetWidth(float64(width) / ui.Scale)
+	hs.background.SetHeight(float64(height) / ui.Scale)
+
+	log := &Client.chat.log
+	if q := strings.ToLower(hs.search.Value()); q != hs.query || log.changes != hs.lastChange {
+		if q != hs.query {
+			hs.scroll = 0
+		}
+		hs.query = q
+		hs.lastChange = log.changes
+		hs.matches = hs.matches[:0]
+		for _, e := range log.history {
+			if q == "" || strings.Contains(strings.ToLower(e.Plain), q) {
+				hs.matches = append(hs.matches, e)
+			}
+		}
+		hs.count.Update(fmt.Sprintf("%d messages", len(hs.matches)))
+		hs.dirty = true
+	}
+	if hs.dirty {
+		hs.dirty = false
+		hs.redraw()
+	}
+}
+
+// redraw lays out the matching messages from the bottom of the
+// screen upwards starting at the current scroll position.
+func (hs *chatHistoryScreen) redraw() {
+	hs.tooltip.hide()
+	for _, l := range hs.lines {
+		ui.Remove(l)
+	}
+	hs.lines = hs.lines[:0]
+
+	offset := 10.0
+	for i := len(hs.matches) - 1 - hs.scroll; i >= 0 && offset < 480-80; i-- {
+		e := hs.matches[i]
+		prefix := &format.TextComponent{Text: "[" + e.Time.Format("15:04:05") + "] "}
+		prefix.Color = format.DarkGray
+		if e.Hidden {
+			prefix.Text += "(hidden) "
+		}
+		msg := &format.TextComponent{}
+		msg.Extra = []format.AnyComponent{format.Wrap(prefix), e.Message}
+		f := ui.NewFormattedWidth(format.Wrap(msg), 10, offset, 854-20)
+		offset += f.Height
+		hs.lines = append(hs.lines, f)
+		hs.scene.AddDrawable(f.Attach(ui.Bottom, ui.Left))
+	}
+}
+
+func (hs *chatHistoryScreen) scrollBy(amount int) {
+	hs.scroll += amount
+	if max := len(hs.matches) - 1; hs.scroll > max {
+		hs.scroll = max
+	}
+	if hs.scroll < 0 {
+		hs.scroll = 0
+	}
+	hs.dirty = true
+}
+
+func (hs *chatHistoryScreen) onScroll(w *glfw.Window, xoff float64, yoff float64) {
+	if yoff > 0 {
+		hs.scrollBy(3)
+	} else if yoff < 0 {
+		hs.scrollBy(-3)
+	}
+}
+
+func (hs *chatHistoryScreen) hover(x, y float64, w, h int) {
+	ui.Hover(x, y, w, h)
+	for _, l := range hs.lines {
+		if ev, ok := l.EventAt(x, y, w, h); ok && ev.Hover != nil {
+			hs.tooltip.show(hs.scene, ev.Hover, x, y, w, h)
+			return
+		}
+	}
+	hs.tooltip.hide()
+}
+
+func (hs *chatHistoryScreen) click(down bool, x, y float64, w, h int) {
+	if down {
+		return
+	}
+	for _, l := range hs.lines {
+		if ev, ok := l.EventAt(x, y, w, h); ok && ev.Click != nil {
+			if handleClickEvent(ev.Click) && currentScreen == hs {
+				setScreen(nil)
+			}
+			return
+		}
+	}
+	ui.Click(x, y, w, h)
+}
+
+func (hs *chatHistoryScreen) handleKey(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+	switch {
+	case key == glfw.KeyEscape && action == glfw.Release:
+		setScreen(nil)
+	case key == glfw.KeyPageUp && action != glfw.Release:
+		hs.scrollBy(10)
+	case key == glfw.KeyPageDown && action != glfw.Release:
+		hs.scrollBy(-10)
+	default:
+		ui.HandleKey(w, key, scancode, action, mods)
+	}
+}
+
+func (hs *chatHistoryScreen) remove() {
+	hs.tooltip.hide()
+	hs.scene.Hide()
+	window.SetKeyCallback(onKey)
+	window.SetScrollCallback(onScroll)
+}
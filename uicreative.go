@@ -0,0 +1,458 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"strings"
+
+	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/thinkofdeath/steven/format"
+	"github.com/thinkofdeath/steven/protocol"
+	"github.com/thinkofdeath/steven/render"
+	"github.com/thinkofdeath/steven/ui"
+	"github.com/thinkofdeath/steven/ui/scene"
+)
+
+// Slots in the creative inventory. Player slots use the same ids
+// as the player's inventory window.
+const (
+	creativeNoSlot     = -1
+	creativeDeleteSlot = 99
+	creativeGridSlot   = 100
+)
+
+const (
+	creativeColumns = 9
+	creativeRows    = 5
+)
+
+// creativeScreen is the inventory used whilst in creative mode. Items
+// can be taken from the tabs freely and are placed in the player's
+// inventory using protocol.CreativeInventoryAction.
+type creativeScreen struct {
+	scene *scene.Type
+
+	items []creativeItem
+	shown []*ItemStack
+	tab   creativeTab
+
+	search *ui.TextBox
+	query  string
+	scroll int
+
+	activeSlot int
+	inWindow   bool
+	drawn      [45]*ItemStack
+
+	cursorItem     *ItemStack
+	cursorIcon     *ui.Container
+	cursorScene    *scene.Type
+	lastMX, lastMY float64
+}
+
+func newCreativeScreen() *creativeScreen {
+	cs := &creativeScreen{
+		scene:       scene.New(true),
+		cursorScene: scene.New(true),
+		items:       creativeItems(),
+		activeSlot:  creativeNoSlot,
+	}
+	cs.setTab(creativeBuilding)
+	return cs
+}
+
+func (cs *creativeScreen) init() {
+	window.SetKeyCallback(cs.handleKey)
+	window.SetScrollCallback(cs.onScroll)
+}
+
+func (cs *creativeScreen) setTab(tab creativeTab) {
+	cs.tab = tab
+	cs.scroll = 0
+	cs.query = ""
+	cs.filter()
+	cs.redraw()
+	if tab == creativeSearch {
+		ui.CycleFocus()
+	}
+}
+
+// filter updates the items shown for the current tab.
+func (cs *creativeScreen) filter() {
+	cs.shown = cs.shown[:0]
+	for _, ci := range cs.items {
+		if cs.tab == creativeSearch {
+			if strings.Contains(ci.name, cs.query) {
+				cs.shown = append(cs.shown, ci.item)
+			}
+		} else if ci.tab == cs.tab {
+			cs.shown = append(cs.shown, ci.item)
+		}
+	}
+}
+
+func (cs *creativeScreen) maxScroll() int {
+	rows := (len(cs.shown) + creativeColumns - 1) / creativeColumns
+	if rows <= creativeRows {
+		return 0
+	}
+	return rows - creativeRows
+}
+
+func (cs *creativeScreen) redraw() {
+	cs.scene.Hide()
+	cs.scene = scene.New(true)
+	s := cs.scene
+
+	texture := "gui/container/creative_inventory/tab_items"
+	switch cs.tab {
+	case creativeSearch:
+		texture = "gui/container/creative_inventory/tab_item_search"
+	case creativeInventory:
+		texture = "gui/container/creative_inventory/tab_inventory"
+	}
+	background := ui.NewImage(render.GetTexture(texture),
+		0, 0, 195*2, 136*2,
+		0, 0, 195/256.0, 136/256.0,
+		255, 255, 255,
+	)
+	s.AddDrawable(background.Attach(ui.Middle, ui.Center))
+
+	check := ui.NewContainer(0, 0, 195*2, 136*2)
+	s.AddDrawable(check.Attach(ui.Middle, ui.Center))
+	check.HoverFunc = func(over bool) {
+		cs.inWindow = over
+	}
+
+	for t := creativeTab(0); t < creativeTabCount; t++ {
+		cs.drawTab(s, background, t)
+	}
+
+	switch cs.tab {
+	case creativeInventory:
+		for i := 0; i < 4; i++ {
+			cs.drawSlot(s, background, 5+i, 54+54*float64(i/2), 6+27*float64(i%2))
+		}
+		for i := 0; i < 27; i++ {
+			cs.drawSlot(s, background, 9+i, 9+18*float64(i%9), 54+18*float64(i/9))
+		}
+		cs.drawSlot(s, background, creativeDeleteSlot, 173, 112)
+	default:
+		title := format.Wrap(&format.TranslateComponent{Translate: creativeTabInfo[cs.tab].locale})
+		drawInventoryTitle(s, background, title, 8, 6)
+		for i := 0; i < creativeColumns*creativeRows; i++ {
+			cs.drawSlot(s, background, creativeGridSlot+i, 9+18*float64(i%creativeColumns), 18+18*float64(i/creativeColumns))
+		}
+		// Scroll bar
+		tx := 232.0
+		max := cs.maxScroll()
+		y := 18.0
+		if max == 0 {
+			tx = 244
+		} else {
+			y += (112 - 15) * float64(cs.scroll) / float64(max)
+		}
+		bar := ui.NewImage(render.GetTexture("gui/container/creative_inventory/tabs"),
+			175*2, y*2, 12*2, 15*2,
+			tx/256.0, 0, 12/256.0, 15/256.0,
+			255, 255, 255,
+		)
+		bar.AttachTo(background)
+		s.AddDrawable(bar.Attach(ui.Top, ui.Left))
+	}
+	for i := 0; i < 9; i++ {
+		cs.drawSlot(s, background, invPlayerHotbarOffset+i, 9+18*float64(i), 112)
+	}
+	copy(cs.drawn[:], Client.playerInventory.Items)
+
+	if cs.tab == creativeSearch {
+		if cs.search == nil {
+			cs.search = ui.NewTextBox(82*2, 4*2, 89*2, 12*2)
+		}
+		cs.search.AttachTo(background)
+		s.AddDrawable(cs.search.Attach(ui.Top, ui.Left))
+	}
+}
+
+// drawTab draws the tab's button above or below the window.
+func (cs *creativeScreen) drawTab(s *scene.Type, background *ui.Image, t creativeTab) {
+	col := float64(t % 6)
+	x, y := 28*col, -28.0
+	ty := 0.0
+	if t >= 6 {
+		y = 136 - 4
+		ty = 64
+	}
+	if t == cs.tab {
+		ty += 32
+	}
+	tab := ui.NewImage(render.GetTexture("gui/container/creative_inventory/tabs"),
+		x*2, y*2, 28*2, 32*2,
+		col*28/256.0, ty/256.0, 28/256.0, 32/256.0,
+		255, 255, 255,
+	)
+	tab.AttachTo(background)
+	// The selected tab is drawn over the window
+	if t == cs.tab {
+		tab.SetLayer(1)
+	}
+	s.AddDrawable(tab.Attach(ui.Top, ui.Left))
+
+	if ty := itemByName(creativeTabInfo[t].icon); ty != nil {
+		icon := createItemIcon(&ItemStack{Type: ty, Count: 1}, s, x*2+12, y*2+16)
+		icon.AttachTo(background)
+		icon.SetLayer(2)
+	}
+
+	btn := ui.NewContainer(x*2, y*2, 28*2, 32*2)
+	btn.AttachTo(background)
+	s.AddDrawable(btn.Attach(ui.Top, ui.Left))
+	btn.ClickFunc = func() {
+		if t != cs.tab {
+			cs.setTab(t)
+		}
+	}
+}
+
+// item returns the item displayed in the slot.
+func (cs *creativeScreen) item(slot int) *ItemStack {
+	switch {
+	case slot >= creativeGridSlot:
+		i := slot - creativeGridSlot + cs.scroll*creativeColumns
+		if i < len(cs.shown) {
+			return cs.shown[i]
+		}
+	case slot >= 0 && slot < len(Client.playerInventory.Items):
+		return Client.playerInventory.Items[slot]
+	}
+	return nil
+}
+
+func (cs *creativeScreen) drawSlot(s *scene.Type, background *ui.Image, slot int, x, y float64) {
+	ctn := ui.NewContainer(x*2, y*2, 32, 32)
+	ctn.AttachTo(background)
+	s.AddDrawable(ctn)
+
+	if item := cs.item(slot); item != nil {
+		container := createItemIcon(item, s, x*2, y*2)
+		container.AttachTo(background)
+	}
+
+	highlight := ui.NewImage(render.GetTexture("solid"), x*2, y*2, 32, 32, 0, 0, 1, 1, 255, 255, 255)
+	highlight.SetA(0)
+	highlight.AttachTo(background)
+	highlight.SetLayer(25)
+	s.AddDrawable(highlight)
+
+	ctn.HoverFunc = func(over bool) {
+		if over {
+			highlight.SetA(100)
+			cs.activeSlot = slot
+		} else {
+			highlight.SetA(0)
+			if slot == cs.activeSlot {
+				cs.activeSlot = creativeNoSlot
+			}
+		}
+	}
+}
+
+func (cs *creativeScreen) tick(delta float64) {
+	if cs.tab == creativeSearch && cs.search != nil {
+		if q := strings.ToLower(cs.search.Value()); q != cs.query {
+			cs.query = q
+			cs.scroll = 0
+			cs.filter()
+			cs.redraw()
+			return
+		}
+	}
+	// Redraw if the server changed the player's inventory
+	for i, item := range Client.playerInventory.Items {
+		if cs.drawn[i] != item {
+			cs.redraw()
+			return
+		}
+	}
+}
+
+func (cs *creativeScreen) hover(x, y float64, w, h int) {
+	cs.lastMX, cs.lastMY = x, y
+	if cs.cursorIcon != nil {
+		cs.cursorIcon.SetX(x - 16)
+		cs.cursorIcon.SetY(y - 16)
+	}
+	ui.Hover(x, y, w, h)
+}
+
+func (cs *creativeScreen) click(down bool, x, y float64, w, h int) {
+	cs.mouseButton(glfw.MouseButtonLeft, down, 0, x, y, w, h)
+}
+
+func (cs *creativeScreen) mouseButton(button glfw.MouseButton, down bool, mods glfw.ModifierKey, x, y float64, w, h int) {
+	b := int(button)
+	if !down {
+		if button == glfw.MouseButtonLeft {
+			ui.Click(x, y, w, h)
+		}
+		return
+	}
+	slot := cs.activeSlot
+	shift := mods&glfw.ModShift != 0
+	switch {
+	case slot == creativeNoSlot:
+		if !cs.inWindow && cs.cursorItem != nil && b != 2 {
+			drop := cs.cursorItem.clone()
+			cursor := cs.cursorItem.clone()
+			// Right click only drops a single item
+			if b == 1 {
+				drop.Count = 1
+				cursor.Count--
+			} else {
+				cursor = nil
+			}
+			cs.setItem(-1, drop)
+			cs.setCursor(cursor)
+		}
+	case slot == creativeDeleteSlot:
+		if shift {
+			for i := 1; i < len(Client.playerInventory.Items); i++ {
+				if Client.playerInventory.Items[i] != nil {
+					cs.setItem(i, nil)
+				}
+			}
+		}
+		cs.setCursor(nil)
+	case slot >= creativeGridSlot:
+		item := cs.item(slot)
+		cursor := cs.cursorItem.clone()
+		switch {
+		case item == nil:
+			cursor = nil
+		case cursor == nil:
+			cursor = item.clone()
+			if shift || b == 2 {
+				cursor.Count = cursor.maxStack()
+			}
+		case !cursor.stacksWith(item):
+			// Clicking a different item deletes the held one
+			cursor = nil
+		case b == 0 && cursor.Count < cursor.maxStack():
+			cursor.Count++
+		case b == 1:
+			cursor.Count--
+			if cursor.Count <= 0 {
+				cursor = nil
+			}
+		}
+		cs.setCursor(cursor)
+	case b == 2:
+		if item := cs.item(slot); item != nil && cs.cursorItem == nil {
+			cursor := item.clone()
+			cursor.Count = cursor.maxStack()
+			cs.setCursor(cursor)
+		}
+	default:
+		item, cursor := clickResult(cs.item(slot), cs.cursorItem, b, slot == 0)
+		cs.setItem(slot, item)
+		cs.setCursor(cursor)
+	}
+}
+
+// setItem changes the item in a slot of the player's inventory and
+// tells the server about it. Slot -1 drops the item.
+func (cs *creativeScreen) setItem(slot int, item *ItemStack) {
+	Client.network.Write(&protocol.CreativeInventoryAction{
+		Slot:        int16(slot),
+		ClickedItem: ItemStackToProtocol(item),
+	})
+	if slot < 0 {
+		return
+	}
+	Client.playerInventory.setItem(slot, item)
+	cs.redraw()
+}
+
+func (cs *creativeScreen) setCursor(item *ItemStack) {
+	cs.cursorScene.Hide()
+	cs.cursorScene = scene.New(true)
+	cs.cursorItem = item
+	cs.cursorIcon = nil
+	if item != nil {
+		cs.cursorIcon = createItemIcon(item, cs.cursorScene, cs.lastMX-16, cs.lastMY-16)
+		cs.cursorIcon.SetLayer(100)
+	}
+}
+
+func (cs *creativeScreen) onScroll(w *glfw.Window, xoff float64, yoff float64) {
+	scroll := cs.scroll
+	if yoff > 0 {
+		scroll--
+	} else if yoff < 0 {
+		scroll++
+	}
+	if max := cs.maxScroll(); scroll > max {
+		scroll = max
+	}
+	if scroll < 0 {
+		scroll = 0
+	}
+	if scroll != cs.scroll {
+		cs.scroll = scroll
+		cs.redraw()
+	}
+}
+
+func (cs *creativeScreen) handleKey(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+	if key == glfw.KeyEscape && action == glfw.Release {
+		setScreen(nil)
+		return
+	}
+	// Keys are used for searching whilst on the search tab
+	if cs.tab == creativeSearch {
+		ui.HandleKey(w, key, scancode, action, mods)
+		return
+	}
+	if key == glfw.KeyE && action == glfw.Release {
+		setScreen(nil)
+		return
+	}
+	if action != glfw.Press || key < glfw.Key1 || key > glfw.Key9 {
+		return
+	}
+	// Number keys swap the hovered item with the hotbar
+	slot := cs.activeSlot
+	hotbar := invPlayerHotbarOffset + int(key-glfw.Key1)
+	switch {
+	case slot >= creativeGridSlot:
+		if item := cs.item(slot); item != nil {
+			item = item.clone()
+			item.Count = item.maxStack()
+			cs.setItem(hotbar, item)
+		}
+	case slot >= 0 && slot != creativeDeleteSlot && slot != hotbar:
+		item, held := cs.item(slot), cs.item(hotbar)
+		cs.setItem(slot, held)
+		cs.setItem(hotbar, item)
+	}
+}
+
+func (cs *creativeScreen) remove() {
+	cs.scene.Hide()
+	cs.cursorScene.Hide()
+	window.SetKeyCallback(onKey)
+	window.SetScrollCallback(onScroll)
+	Client.playerInventory.Update()
+}
@@ -26,6 +26,13 @@ type screen interface {
 	remove()
 }
 
+// buttonScreen is implemented by screens that want to handle mouse
+// buttons other than the left one.
+type buttonScreen interface {
+	screen
+	mouseButton(button glfw.MouseButton, down bool, mods glfw.ModifierKey, x, y float64, w, h int)
+}
+
 func setScreen(s screen) {
 	if currentScreen != nil {
 		currentScreen.remove()
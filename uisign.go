@@ -0,0 +1,262 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"math"
+
+	"github.com/go-gl/glfw/v3.1/glfw"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/thinkofdeath/steven/format"
+	"github.com/thinkofdeath/steven/protocol"
+	"github.com/thinkofdeath/steven/render"
+	"github.com/thinkofdeath/steven/ui"
+	"github.com/thinkofdeath/steven/ui/scene"
+)
+
+// The maximum width of a single line on a sign. Matches
+// vanilla's limit of 90 pixels at our doubled font scale.
+const signLineWidth = 180
+
+// The scale the sign model is drawn at in the editor, the
+// backboard ends up 192 pixels wide.
+const signModelScale = 6
+
+// signEditScreen allows the player to write the text on a sign
+// they just placed.
+type signEditScreen struct {
+	baseUI
+	scene *scene.Type
+
+	background *ui.Image
+	model      *ui.Model
+	shown      [4]string
+
+	location protocol.Position
+	hasStand bool
+	lines    [4][]rune
+	line     int
+	cursor   int
+
+	cursorTick float64
+	done       bool
+}
+
+func newSignEditScreen(location protocol.Position) *signEditScreen {
+	ss := &signEditScreen{
+		scene:    scene.New(true),
+		location: location,
+	}
+
+	ss.background = ui.NewImage(render.GetTexture("solid"), 0, 0, 854, 480, 0, 0, 1, 1, 0, 0, 0)
+	ss.background.SetA(100)
+	ss.scene.AddDrawable(ss.background.Attach(ui.Top, ui.Left))
+
+	title := ui.NewText("Edit sign message", 0, 40, 255, 255, 255)
+	ss.scene.AddDrawable(title.Attach(ui.Top, ui.Middle))
+
+	_, ss.hasStand = chunkMap.Block(location.X(), location.Y(), location.Z()).(*blockFloorSign)
+	ss.updateModel()
+
+	done, txt := newButtonText("Done", 0, 100, 400, 40)
+	ss.scene.AddDrawable(done.Attach(ui.Bottom, ui.Middle))
+	ss.scene.AddDrawable(txt)
+	done.AddClick(func() { setScreen(nil) })
+
+	ss.updateSign()
+	return ss
+}
+
+func (ss *signEditScreen) init() {
+	window.SetKeyCallback(ss.handleKey)
+	window.SetCharCallback(ss.handleChar)
+}
+
+func (ss *signEditScreen) tick(delta float64) {
+	width, height := window.GetFramebufferSize()
+	ss.background.SetWidth(float64(width) / ui.Scale)
+	ss.background.SetHeight(float64(height) / ui.Scale)
+
+	ss.cursorTick += delta
+	if ss.cursorTick > 0xFFFFFF {
+		ss.cursorTick = 0
+	}
+	ss.updateModel()
+}
+
+// displayLines returns the lines as they should be shown in the
+// editor with the selected line and the cursor marked.
+func (ss *signEditScreen) displayLines() (lines [4]string) {
+	for i, l := range ss.lines {
+		str := string(l)
+		if i == ss.line {
+			if int(ss.cursorTick/30)%2 == 0 {
+				str = string(l[:ss.cursor]) + "|" + string(l[ss.cursor:])
+			}
+			str = "> " + str + " <"
+		}
+		lines[i] = str
+	}
+	return lines
+}
+
+// updateModel rebuilds the sign model shown in the editor if the
+// displayed text has changed since it was last built.
+func (ss *signEditScreen) updateModel() {
+	lines := ss.displayLines()
+	if ss.model != nil && lines == ss.shown {
+		return
+	}
+	ss.shown = lines
+	var components [4]format.AnyComponent
+	for i, l := range lines {
+		components[i] = format.Wrap(&format.TextComponent{Text: l})
+	}
+
+	var verts []*ui.ModelVertex
+	for _, v := range signVertices(components, ss.hasStand) {
+		rect := v.Texture.Rect()
+		// ui.Model expects the model to be within 0-1 where
+		// as the sign is centered on the origin.
+		verts = append(verts, &ui.ModelVertex{
+			X:        v.X + 0.5,
+			Y:        v.Y + 0.5,
+			Z:        v.Z + 0.5,
+			TX:       uint16(rect.X),
+			TY:       uint16(rect.Y),
+			TW:       uint16(rect.Width),
+			TH:       uint16(rect.Height),
+			TOffsetX: int16(16 * float64(rect.Width) * v.TextureX),
+			TOffsetY: int16(16 * float64(rect.Height) * v.TextureY),
+			TAtlas:   int16(v.Texture.Atlas()),
+			R:        v.R,
+			G:        v.G,
+			B:        v.B,
+			A:        v.A,
+		})
+	}
+	// The text is written on the north face so turn the sign
+	// around to face the screen.
+	mat := mgl32.Rotate3DY(math.Pi).Mat4().
+		Mul4(mgl32.Scale3D(signModelScale, signModelScale, signModelScale))
+
+	if ss.model != nil {
+		ss.model.Remove()
+	}
+	ss.model = ui.NewModel(0, -50, verts, mat)
+	ss.scene.AddDrawable(ss.model.Attach(ui.Center, ui.Middle))
+}
+
+func (ss *signEditScreen) handleKey(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+	if key == glfw.KeyEscape && action == glfw.Release {
+		setScreen(nil)
+		return
+	}
+	if action == glfw.Release {
+		return
+	}
+	line := ss.lines[ss.line]
+	switch key {
+	case glfw.KeyUp:
+		ss.setLine(ss.line - 1)
+	case glfw.KeyDown, glfw.KeyEnter, glfw.KeyTab:
+		ss.setLine(ss.line + 1)
+	case glfw.KeyLeft:
+		if ss.cursor > 0 {
+			ss.cursor--
+		}
+	case glfw.KeyRight:
+		if ss.cursor < len(line) {
+			ss.cursor++
+		}
+	case glfw.KeyHome:
+		ss.cursor = 0
+	case glfw.KeyEnd:
+		ss.cursor = len(line)
+	case glfw.KeyBackspace:
+		if ss.cursor > 0 {
+			ss.lines[ss.line] = append(line[:ss.cursor-1], line[ss.cursor:]...)
+			ss.cursor--
+			ss.updateSign()
+		}
+	case glfw.KeyDelete:
+		if ss.cursor < len(line) {
+			ss.lines[ss.line] = append(line[:ss.cursor], line[ss.cursor+1:]...)
+			ss.updateSign()
+		}
+	}
+	ss.cursorTick = 0
+}
+
+// setLine moves the cursor to the end of the passed line,
+// wrapping around at the top and bottom of the sign.
+func (ss *signEditScreen) setLine(line int) {
+	ss.line = (line + len(ss.lines)) % len(ss.lines)
+	ss.cursor = len(ss.lines[ss.line])
+}
+
+func (ss *signEditScreen) handleChar(w *glfw.Window, char rune) {
+	// Formatting codes can't be typed on signs
+	if char == '§' || char < ' ' {
+		return
+	}
+	line := ss.lines[ss.line]
+	next := make([]rune, 0, len(line)+1)
+	next = append(next, line[:ss.cursor]...)
+	next = append(next, char)
+	next = append(next, line[ss.cursor:]...)
+	if render.SizeOfString(string(next)) > signLineWidth {
+		return
+	}
+	ss.lines[ss.line] = next
+	ss.cursor++
+	ss.cursorTick = 0
+	ss.updateSign()
+}
+
+// components returns the lines of the sign as chat components.
+func (ss *signEditScreen) components() (lines [4]format.AnyComponent) {
+	for i, l := range ss.lines {
+		lines[i] = format.Wrap(&format.TextComponent{Text: string(l)})
+	}
+	return lines
+}
+
+// updateSign updates the sign in the world to match what is
+// currently being typed.
+func (ss *signEditScreen) updateSign() {
+	be := chunkMap.BlockEntity(ss.location.X(), ss.location.Y(), ss.location.Z())
+	if s, ok := be.(SignComponent); ok {
+		s.Update(ss.components())
+	}
+}
+
+func (ss *signEditScreen) remove() {
+	ss.scene.Hide()
+	window.SetKeyCallback(onKey)
+	window.SetCharCallback(onChar)
+	if ss.done {
+		return
+	}
+	ss.done = true
+	lines := ss.components()
+	Client.network.Write(&protocol.SetSign{
+		Location: ss.location,
+		Line1:    lines[0],
+		Line2:    lines[1],
+		Line3:    lines[2],
+		Line4:    lines[3],
+	})
+}
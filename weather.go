@@ -0,0 +1,271 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/thinkofdeath/steven/render"
+)
+
+const (
+	// The radius around the player that rain/snow is
+	// drawn in.
+	weatherRadius   = 6
+	weatherDiameter = weatherRadius*2 + 1
+)
+
+// weatherState tracks the current weather sent by the server
+// along with the models used to render it.
+type weatherState struct {
+	raining bool
+	// Levels between 0 and 1 sent by the server
+	rain, thunder float64
+
+	time  float64
+	model *render.StaticModel
+
+	rainSoundTimer float64
+
+	flash float64
+	bolts []*lightningBolt
+}
+
+func (w *weatherState) free() {
+	if w.model != nil {
+		w.model.Free()
+		w.model = nil
+	}
+	for _, b := range w.bolts {
+		b.model.Free()
+	}
+	w.bolts = nil
+}
+
+// skyMultiplier returns the amount the sky should be darkened
+// by due to the weather.
+func (w *weatherState) skyMultiplier() float64 {
+	return (1 - w.rain*5/16) * (1 - w.thunder*5/16)
+}
+
+// precipitationAt returns the type of precipitation that falls at
+// the given location. The returned texture is nil if nothing falls
+// there.
+func precipitationAt(x, y, z int) (tex render.TextureInfo, snow bool) {
+	ch := chunkMap[chunkPosition{x >> 4, z >> 4}]
+	if ch == nil {
+		return nil, false
+	}
+	bi := ch.biome(x&0xF, z&0xF)
+	// Dry biomes (deserts, savannas etc) never rain
+	if bi.Moisture == 0 {
+		return nil, false
+	}
+	// It gets colder the higher you go
+	temp := bi.Temperature
+	if y > 64 {
+		temp -= float64(y-64) * 0.05 / 30
+	}
+	if temp < 0.15 {
+		return render.GetTexture("environment/snow"), true
+	}
+	return render.GetTexture("environment/rain"), false
+}
+
+func (w *weatherState) tick(delta float64) {
+	w.time += delta
+	w.tickLightning(delta)
+
+	// Only the overworld has weather, the server still sends the
+	// state of it in the other dimensions
+	if w.rain <= 0 || Client.WorldType != wtOverworld {
+		if w.model != nil {
+			w.model.Colors[0][3] = 0
+		}
+		return
+	}
+	if w.model == nil {
+		var verts []*render.StaticVertex
+		for i := 0; i < weatherDiameter*weatherDiameter*8; i++ {
+			verts = append(verts, &render.StaticVertex{
+				Texture: render.GetTexture("environment/rain"),
+				R:       255, G: 255, B: 255, A: 255,
+			})
+		}
+		w.model = render.NewStaticModel([][]*render.StaticVertex{verts})
+	}
+
+	px, py, pz := Client.X, Client.Y, Client.Z
+	bx, by, bz := int(math.Floor(px)), int(math.Floor(py)), int(math.Floor(pz))
+	w.model.BlockLight = float32(chunkMap.BlockLight(bx, by, bz))
+	w.model.SkyLight = float32(chunkMap.SkyLight(bx, by, bz))
+	w.model.Colors[0][3] = float32(w.rain)
+
+	var rainTop [3]float64
+	hasRainTop := false
+
+	i := 0
+	for z := bz - weatherRadius; z <= bz+weatherRadius; z++ {
+		for x := bx - weatherRadius; x <= bx+weatherRadius; x++ {
+			verts := w.model.Verts[i*8 : i*8+8]
+			i++
+
+			ground := chunkMap.HighestBlockAt(x, z) + 1
+			minY := by - weatherRadius
+			if minY < ground {
+				minY = ground
+			}
+			maxY := by + weatherRadius
+			tex, snow := precipitationAt(x, minY, z)
+			if tex == nil || minY >= maxY {
+				hideWeatherColumn(verts)
+				continue
+			}
+			if !snow && (!hasRainTop || soundRandom.Intn(3) == 0) {
+				rainTop = [3]float64{float64(x) + 0.5, float64(ground), float64(z) + 0.5}
+				hasRainTop = true
+			}
+
+			// Offset each column so they don't line up
+			hash := uint32(x*3121+x*x*45238971) ^ uint32(z*z*418711+z*13761)
+			offset := float64(hash&0xFF) / 255
+
+			var scroll, drift float64
+			if snow {
+				scroll = w.time/600 + offset
+				drift = math.Sin(w.time/60+offset*math.Pi*2) * 0.05
+			} else {
+				scroll = w.time/15 + offset
+			}
+
+			// The column faces the player
+			cx, cz := float64(x)+0.5, float64(z)+0.5
+			dx, dz := cx-px, cz-pz
+			l := math.Sqrt(dx*dx + dz*dz)
+			if l == 0 {
+				l = 1
+			}
+			ox, oz := dz/l*0.5, -dx/l*0.5
+
+			// The texture covers 4 blocks vertically and moves
+			// down over time.
+			v1 := -float64(maxY)/4 - scroll
+			v2 := -float64(minY)/4 - scroll
+			base := math.Floor(v1)
+			v1, v2 = v1-base, v2-base
+			corners := [4]struct{ x, y, z, u, v float64 }{
+				{cx - ox, float64(minY), cz - oz, drift, v2},
+				{cx + ox, float64(minY), cz + oz, 1 + drift, v2},
+				{cx - ox, float64(maxY), cz - oz, drift, v1},
+				{cx + ox, float64(maxY), cz + oz, 1 + drift, v1},
+			}
+			for j, c := range [8]int{0, 1, 2, 3, 1, 0, 3, 2} {
+				co := corners[c]
+				v := verts[j]
+				v.X, v.Y, v.Z = float32(co.x), float32(co.y), float32(co.z)
+				v.TextureX, v.TextureY = co.u, co.v
+				v.Texture = tex
+			}
+		}
+	}
+	w.model.Refresh()
+
+	w.rainSoundTimer -= delta
+	if hasRainTop && w.rainSoundTimer <= 0 {
+		w.rainSoundTimer = 15 + soundRandom.Float64()*15
+		vol := 0.2
+		if rainTop[1] > py+1 {
+			// Quieter when the rain is above the player
+			vol = 0.1
+		}
+		PlaySoundAt("ambient.weather.rain", vol*w.rain, 1, mgl32.Vec3{
+			float32(rainTop[0]), float32(rainTop[1]), float32(rainTop[2]),
+		})
+	}
+}
+
+func hideWeatherColumn(verts []*render.StaticVertex) {
+	for _, v := range verts {
+		v.X, v.Y, v.Z = 0, 0, 0
+	}
+}
+
+// lightningBolt is a short lived bolt of lightning spawned by
+// the server.
+type lightningBolt struct {
+	model *render.StaticModel
+	time  float64
+}
+
+func (w *weatherState) spawnLightning(x, y, z float64) {
+	tex := render.GetTexture("solid")
+	var verts []*render.StaticVertex
+	// Build a jagged bolt out of thin boxes that
+	// wander as they go up.
+	ox, oz := 0.0, 0.0
+	for by := 0.0; by < 128; by += 8 {
+		nx := ox + (rand.Float64()-0.5)*2
+		nz := oz + (rand.Float64()-0.5)*2
+		for _, s := range []float64{0.1, 0.25} {
+			verts = appendBox(verts,
+				float32(x+(ox+nx)/2-s/2), float32(y+by), float32(z+(oz+nz)/2-s/2),
+				float32(s), 8, float32(s),
+				[6]render.TextureInfo{tex, tex, tex, tex, tex, tex},
+			)
+		}
+		ox, oz = nx, nz
+	}
+	b := &lightningBolt{}
+	b.model = render.NewStaticModel([][]*render.StaticVertex{verts})
+	b.model.BlockLight, b.model.SkyLight = 15, 15
+	b.model.Colors[0] = [4]float32{0.45, 0.45, 0.5, 0.6}
+	w.bolts = append(w.bolts, b)
+
+	w.flash = 1
+
+	// Thunder is heard across the whole render distance so
+	// it isn't positioned at the bolt
+	PlaySoundVolume("ambient.weather.thunder", 1, 0.8+soundRandom.Float64()*0.2)
+	PlaySoundAt("random.explode", 2, 0.5+soundRandom.Float64()*0.2, mgl32.Vec3{
+		float32(x), float32(y), float32(z),
+	})
+}
+
+func (w *weatherState) tickLightning(delta float64) {
+	if w.flash > 0 {
+		w.flash -= delta / 10
+		if w.flash < 0 {
+			w.flash = 0
+		}
+	}
+	for i := 0; i < len(w.bolts); i++ {
+		b := w.bolts[i]
+		b.time += delta
+		// Bolts flicker for a few ticks before vanishing
+		if b.time > 15 {
+			b.model.Free()
+			w.bolts = append(w.bolts[:i], w.bolts[i+1:]...)
+			i--
+			continue
+		}
+		if int(b.time/3)%2 == 0 {
+			b.model.Colors[0][3] = 0.6
+		} else {
+			b.model.Colors[0][3] = 0.3
+		}
+	}
+}
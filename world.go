@@ -21,3 +21,11 @@ const (
 	wtOverworld
 	wtEnd
 )
+
+// lightLevel returns the base light level of the world type.
+func (w worldType) lightLevel() float32 {
+	if w == wtNether {
+		return 0.9
+	}
+	return 0.8
+}
@@ -0,0 +1,277 @@
+// Copyright 2015 Matthew Collins
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package steven
+
+import (
+	"math"
+
+	"github.com/thinkofdeath/steven/protocol"
+	"github.com/thinkofdeath/steven/render"
+	"github.com/thinkofdeath/steven/type/vmath"
+	"github.com/thinkofdeath/steven/ui"
+	"github.com/thinkofdeath/steven/ui/scene"
+)
+
+const (
+	// How close the player has to be to the border
+	// for it to be drawn
+	borderViewDistance = 24.0
+	// How many blocks a single repeat of the border
+	// texture covers
+	borderTextureSize = 2.0
+)
+
+// worldBorder tracks the state of the world border sent by
+// the server.
+type worldBorder struct {
+	enabled bool
+
+	centerX, centerZ float64
+	// Sizes are the diameter of the border
+	oldSize, newSize float64
+	// Lerp progress and length in milliseconds
+	lerpTime, lerpLength float64
+
+	portalBoundary int
+	// Warning time is in seconds
+	warningTime   int
+	warningBlocks int
+
+	time    float64
+	model   *render.StaticModel
+	warning *ui.Image
+}
+
+func (wb *worldBorder) init(s *scene.Type) {
+	wb.newSize, wb.oldSize = 60000000, 60000000
+	wb.warningTime = 15
+	wb.warningBlocks = 5
+	wb.warning = ui.NewImage(render.GetTexture("solid"), 0, 0, 854, 480, 0, 0, 1, 1, 255, 0, 0)
+	wb.warning.SetA(0)
+	wb.warning.SetLayer(-1)
+	s.AddDrawable(wb.warning.Attach(ui.Top, ui.Left))
+}
+
+func (wb *worldBorder) free() {
+	if wb.model != nil {
+		wb.model.Free()
+		wb.model = nil
+	}
+}
+
+func (wb *worldBorder) handle(p *protocol.WorldBorder) {
+	wb.enabled = true
+	switch p.Action {
+	case 0: // Set size
+		wb.oldSize, wb.newSize = p.NewRadius, p.NewRadius
+		wb.lerpTime, wb.lerpLength = 0, 0
+	case 1: // Lerp size
+		wb.oldSize, wb.newSize = p.OldRadius, p.NewRadius
+		wb.lerpTime, wb.lerpLength = 0, float64(p.Speed)
+	case 2: // Set center
+		wb.centerX, wb.centerZ = p.X, p.Z
+	case 3: // Initialize
+		wb.centerX, wb.centerZ = p.X, p.Z
+		wb.oldSize, wb.newSize = p.OldRadius, p.NewRadius
+		wb.lerpTime, wb.lerpLength = 0, float64(p.Speed)
+		wb.portalBoundary = int(p.PortalBoundary)
+		wb.warningTime = int(p.WarningTime)
+		wb.warningBlocks = int(p.WarningBlocks)
+	case 4: // Set warning time
+		wb.warningTime = int(p.WarningTime)
+	case 5: // Set warning blocks
+		wb.warningBlocks = int(p.WarningBlocks)
+	}
+}
+
+// size returns the current diameter of the border taking
+// into account any lerp in progress.
+func (wb *worldBorder) size() float64 {
+	if wb.lerpLength <= 0 || wb.lerpTime >= wb.lerpLength {
+		return wb.newSize
+	}
+	return wb.oldSize + (wb.newSize-wb.oldSize)*(wb.lerpTime/wb.lerpLength)
+}
+
+// bounds returns the min and max x/z coordinates of the
+// border.
+func (wb *worldBorder) bounds() (minX, minZ, maxX, maxZ float64) {
+	half := wb.size() / 2
+	return wb.centerX - half, wb.centerZ - half,
+		wb.centerX + half, wb.centerZ + half
+}
+
+// inside returns whether the x and z coordinates are within
+// the border.
+func (wb *worldBorder) inside(x, z float64) bool {
+	minX, minZ, maxX, maxZ := wb.bounds()
+	return x >= minX && x <= maxX && z >= minZ && z <= maxZ
+}
+
+// distance returns the distance to the closest edge of the
+// border.
+func (wb *worldBorder) distance(x, z float64) float64 {
+	minX, minZ, maxX, maxZ := wb.bounds()
+	return math.Min(
+		math.Min(x-minX, maxX-x),
+		math.Min(z-minZ, maxZ-z),
+	)
+}
+
+// collisionBoxes returns the boxes that make up the border's
+// edges around the passed bounds.
+func (wb *worldBorder) collisionBoxes(bounds vmath.AABB) []vmath.AABB {
+	if !wb.enabled {
+		return nil
+	}
+	minX, minZ, maxX, maxZ := wb.bounds()
+	y1, y2 := bounds.Min.Y()-1, bounds.Max.Y()+1
+	x1, x2 := bounds.Min.X()-1, bounds.Max.X()+1
+	z1, z2 := bounds.Min.Z()-1, bounds.Max.Z()+1
+	return []vmath.AABB{
+		vmath.NewAABB(float32(minX)-1, y1, z1, float32(minX), y2, z2),
+		vmath.NewAABB(float32(maxX), y1, z1, float32(maxX)+1, y2, z2),
+		vmath.NewAABB(x1, y1, float32(minZ)-1, x2, y2, float32(minZ)),
+		vmath.NewAABB(x1, y1, float32(maxZ), x2, y2, float32(maxZ)+1),
+	}
+}
+
+func (wb *worldBorder) tick(delta float64) {
+	if !wb.enabled {
+		return
+	}
+	// delta is in 60ths of a second
+	if wb.lerpTime < wb.lerpLength {
+		wb.lerpTime += delta * (1000.0 / 60.0)
+	}
+	wb.time += delta
+	wb.updateWarning()
+	wb.render()
+}
+
+// updateWarning tints the screen red as the player nears
+// the border.
+func (wb *worldBorder) updateWarning() {
+	dist := wb.distance(Client.X, Client.Z)
+
+	// A shrinking border warns based on how long it'll take
+	// to reach the player as well as the distance.
+	warnDist := float64(wb.warningBlocks)
+	if wb.lerpTime < wb.lerpLength {
+		speed := math.Abs(wb.newSize-wb.oldSize) / wb.lerpLength
+		remaining := math.Abs(wb.newSize - wb.size())
+		warnDist = math.Max(warnDist, math.Min(speed*float64(wb.warningTime)*1000, remaining))
+	}
+	amount := 0.0
+	if dist < warnDist {
+		amount = 1 - dist/warnDist
+	}
+	if amount > 1 {
+		amount = 1
+	}
+	wb.warning.SetA(int(amount * 100))
+}
+
+// render updates the model for the border's walls. Only the
+// part of the wall close to the player is drawn.
+func (wb *worldBorder) render() {
+	if wb.model == nil {
+		var parts [][]*render.StaticVertex
+		for i := 0; i < 4; i++ {
+			var verts []*render.StaticVertex
+			for j := 0; j < 8; j++ {
+				verts = append(verts, &render.StaticVertex{
+					Texture: render.GetTexture("misc/forcefield"),
+					R:       255, G: 255, B: 255, A: 255,
+				})
+			}
+			parts = append(parts, verts)
+		}
+		wb.model = render.NewStaticModel(parts)
+		wb.model.BlockLight, wb.model.SkyLight = 15, 15
+	}
+
+	var r, g, b float32
+	switch {
+	case wb.lerpTime >= wb.lerpLength || wb.newSize == wb.oldSize:
+		r, g, b = 0x20/255.0, 0xA0/255.0, 0xFF/255.0
+	case wb.newSize < wb.oldSize:
+		r, g, b = 0xFF/255.0, 0x30/255.0, 0x30/255.0
+	default:
+		r, g, b = 0x40/255.0, 0xFF/255.0, 0x80/255.0
+	}
+
+	minX, minZ, maxX, maxZ := wb.bounds()
+	px, py, pz := Client.X, Client.Y, Client.Z
+	y1, y2 := py-borderViewDistance, py+borderViewDistance
+	// Scroll the texture over 3 seconds
+	scroll := math.Mod(wb.time, 180) / 180
+
+	walls := [4]struct {
+		x1, z1, x2, z2 float64
+		dist           float64
+	}{
+		{minX, pz - borderViewDistance, minX, pz + borderViewDistance, px - minX},
+		{maxX, pz + borderViewDistance, maxX, pz - borderViewDistance, maxX - px},
+		{px + borderViewDistance, minZ, px - borderViewDistance, minZ, pz - minZ},
+		{px - borderViewDistance, maxZ, px + borderViewDistance, maxZ, maxZ - pz},
+	}
+	for i, w := range walls {
+		verts := wb.model.Verts[i*8 : i*8+8]
+		alpha := 0.0
+		if w.dist < borderViewDistance {
+			alpha = math.Pow(1-math.Max(w.dist, 0)/borderViewDistance, 4)
+		}
+		wb.model.Colors[i] = [4]float32{r, g, b, float32(alpha)}
+
+		// Clamp the wall to the border's corners
+		x1, x2 := clampFloat(w.x1, minX, maxX), clampFloat(w.x2, minX, maxX)
+		z1, z2 := clampFloat(w.z1, minZ, maxZ), clampFloat(w.z2, minZ, maxZ)
+
+		// Keep the texture in place as the player moves
+		u1 := (x1 + z1) / borderTextureSize
+		u2 := (x2 + z2) / borderTextureSize
+		base := math.Floor(math.Min(u1, u2))
+		u1, u2 = u1-base, u2-base
+		v1 := y1/borderTextureSize - math.Floor(y1/borderTextureSize) + scroll
+		v2 := v1 + (y2-y1)/borderTextureSize
+
+		corners := [4]struct{ x, y, z, u, v float64 }{
+			{x1, y1, z1, u1, v2},
+			{x2, y1, z2, u2, v2},
+			{x1, y2, z1, u1, v1},
+			{x2, y2, z2, u2, v1},
+		}
+		// Both sides of the wall are drawn as
+		// backface culling is enabled.
+		for j, c := range [8]int{0, 1, 2, 3, 1, 0, 3, 2} {
+			co := corners[c]
+			v := verts[j]
+			v.X, v.Y, v.Z = float32(co.x), float32(co.y), float32(co.z)
+			v.TextureX, v.TextureY = co.u, co.v
+		}
+	}
+	wb.model.Refresh()
+}
+
+func clampFloat(v, min, max float64) float64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}